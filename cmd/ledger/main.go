@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -15,7 +20,9 @@ import (
 	"github.com/kelseyhightower/envconfig"
 
 	"finplatform/internal/common/database"
+	"finplatform/internal/common/events"
 	"finplatform/internal/common/middleware"
+	"finplatform/internal/common/nats"
 	"finplatform/internal/ledger"
 	"finplatform/internal/ledger/api"
 )
@@ -27,7 +34,27 @@ type Config struct {
 	LogLevel    string `envconfig:"LOG_LEVEL" default:"info"`
 	LogFormat   string `envconfig:"LOG_FORMAT" default:"json"`
 
+	// Background position job
+	PositionsInterval     time.Duration `envconfig:"LEDGER_POSITIONS_INTERVAL" default:"1h"`
+	PositionsLookbackDays int           `envconfig:"LEDGER_POSITIONS_LOOKBACK_DAYS" default:"2"`
+
+	// Hash chain checkpoints, signed with a base64 ed25519 seed. Without a key none are made.
+	CheckpointKey      string        `envconfig:"LEDGER_CHECKPOINT_KEY"`
+	CheckpointInterval time.Duration `envconfig:"LEDGER_CHECKPOINT_INTERVAL" default:"24h"`
+
+	// Hex ed25519 public key checkpoints are verified with, by default that of the
+	// signing key. Without either, checkpoints are reported unverified.
+	CheckpointPublicKey string `envconfig:"LEDGER_CHECKPOINT_PUBLIC_KEY"`
+
+	// Background integrity audit; 0 disables it
+	AuditInterval time.Duration `envconfig:"LEDGER_AUDIT_INTERVAL" default:"24h"`
+
+	// Provision a ledger account for each wallet account created, from the events stream
+	WalletEvents bool   `envconfig:"LEDGER_WALLET_EVENTS" default:"false"`
+	EventsStream string `envconfig:"LEDGER_EVENTS_STREAM" default:"EVENTS"`
+
 	Database database.Config
+	NATS     nats.Config
 }
 
 func main() {
@@ -65,6 +92,76 @@ func main() {
 
 	// Create services
 	ledgerService := ledger.NewService(db, logger)
+	var checkpointKey ed25519.PrivateKey
+	if cfg.CheckpointKey != "" {
+		seed, err := base64.StdEncoding.DecodeString(cfg.CheckpointKey)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			logger.Error("LEDGER_CHECKPOINT_KEY must be a base64 ed25519 seed")
+			os.Exit(1)
+		}
+		checkpointKey = ed25519.NewKeyFromSeed(seed)
+		ledgerService.SetCheckpointKey(checkpointKey)
+	}
+	if cfg.CheckpointPublicKey != "" {
+		key, err := hex.DecodeString(cfg.CheckpointPublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			logger.Error("LEDGER_CHECKPOINT_PUBLIC_KEY must be a hex ed25519 public key")
+			os.Exit(1)
+		}
+		if checkpointKey != nil && !checkpointKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(key)) {
+			logger.Error("LEDGER_CHECKPOINT_PUBLIC_KEY does not match LEDGER_CHECKPOINT_KEY")
+			os.Exit(1)
+		}
+		ledgerService.SetCheckpointPublicKey(key)
+	}
+
+	// One-off commands, which an operator runs across tenants
+	if len(os.Args) > 1 {
+		if err := runCommand(database.WithSystemScope(ctx), ledgerService, os.Args[1], os.Args[2:]); err != nil {
+			logger.Error("command failed", "command", os.Args[1], "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Start background jobs
+	positionJob := ledger.NewPositionJob(ledgerService, logger, cfg.PositionsInterval, cfg.PositionsLookbackDays)
+	go positionJob.Run(ctx)
+
+	if cfg.CheckpointKey != "" {
+		checkpointJob := ledger.NewCheckpointJob(ledgerService, logger, cfg.CheckpointInterval)
+		go checkpointJob.Run(ctx)
+	}
+
+	if cfg.AuditInterval > 0 {
+		auditJob := ledger.NewAuditJob(ledgerService, logger, cfg.AuditInterval)
+		go auditJob.Run(ctx)
+	}
+
+	// Start event consumers
+	if cfg.WalletEvents {
+		natsClient, err := nats.New(ctx, cfg.NATS, logger)
+		if err != nil {
+			logger.Error("failed to connect to NATS", "error", err)
+			os.Exit(1)
+		}
+		defer natsClient.Close()
+
+		consumer, err := natsClient.EnsureConsumer(ctx, nats.DefaultConsumerConfig(
+			"ledger-wallet-accounts", cfg.EventsStream, "events."+events.EventWalletAccountCreated,
+		))
+		if err != nil {
+			logger.Error("failed to create wallet events consumer", "error", err)
+			os.Exit(1)
+		}
+
+		walletHandler := ledger.NewWalletEventHandler(ledgerService, logger)
+		go func() {
+			if err := nats.NewSubscriber(natsClient, consumer, logger).Start(ctx, walletHandler.Handle); err != nil && ctx.Err() == nil {
+				logger.Error("wallet events consumer stopped", "error", err)
+			}
+		}()
+	}
 
 	// Create handlers
 	ledgerHandler := api.NewHandler(ledgerService)
@@ -78,6 +175,7 @@ func main() {
 	r.Use(middleware.Recoverer(logger))
 	r.Use(middleware.Logger(logger))
 	r.Use(middleware.TenantExtractor)
+	r.Use(middleware.UserExtractor)
 	r.Use(chimw.Compress(5))
 
 	// Health check
@@ -138,6 +236,103 @@ func main() {
 	logger.Info("server stopped")
 }
 
+// runCommand runs a one-off command instead of the server:
+//
+//	ledger positions -from 2026-01-01 -to 2026-01-31 [-tenant ID]
+//	ledger verify-chain -tenant ID
+//	ledger checkpoint
+//	ledger audit [-tenant ID]
+//	ledger provision-wallets [-tenant ID]
+func runCommand(ctx context.Context, service *ledger.Service, name string, args []string) error {
+	switch name {
+	case "positions":
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		today := time.Now().UTC().Format("2006-01-02")
+		fromStr := fs.String("from", today, "first day to recompute (YYYY-MM-DD)")
+		toStr := fs.String("to", today, "last day to recompute (YYYY-MM-DD)")
+		tenantID := fs.String("tenant", "", "only recompute this tenant (default all)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		from, err := time.Parse("2006-01-02", *fromStr)
+		if err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		to, err := time.Parse("2006-01-02", *toStr)
+		if err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+
+		return service.RecomputePositions(ctx, *tenantID, from, to)
+	case "verify-chain":
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		tenantID := fs.String("tenant", "", "tenant whose chain to verify")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *tenantID == "" {
+			return fmt.Errorf("-tenant is required")
+		}
+
+		result, err := service.VerifyChain(ctx, *tenantID)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		if !result.Valid {
+			return fmt.Errorf("hash chain broken at sequence %d: %s", result.Break.Sequence, result.Break.Reason)
+		}
+		return nil
+	case "checkpoint":
+		return service.CreateChainCheckpoints(ctx)
+	case "audit":
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		tenantID := fs.String("tenant", "", "only audit this tenant (default all)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		// The runs of the tenants audited are printed even if others failed
+		runs, auditErr := service.RunAudits(ctx, *tenantID)
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(runs); err != nil {
+			return err
+		}
+		if auditErr != nil {
+			return auditErr
+		}
+
+		var findings int
+		for _, run := range runs {
+			findings += run.FindingCount
+		}
+		if findings > 0 {
+			return fmt.Errorf("audit found %d discrepancies", findings)
+		}
+		return nil
+	case "provision-wallets":
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		tenantID := fs.String("tenant", "", "only provision this tenant's wallets (default all)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		provisioned, err := service.ProvisionWalletAccounts(ctx, *tenantID)
+		fmt.Printf("provisioned %d wallet ledger accounts\n", provisioned)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
 func setupLogger(level, format string) *slog.Logger {
 	var logLevel slog.Level
 	switch level {
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -28,11 +30,12 @@ type PaginatedResponse[T any] struct {
 	Error      *Error      `json:"error,omitempty"`
 }
 
-// Pagination holds pagination info
+// Pagination holds pagination info. Keyset paginated listings leave out the
+// offset, and the total unless it was asked for.
 type Pagination struct {
 	Limit      int    `json:"limit"`
-	Offset     int    `json:"offset"`
-	Total      int64  `json:"total"`
+	Offset     int    `json:"offset,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
 	HasMore    bool   `json:"has_more"`
 	NextCursor string `json:"next_cursor,omitempty"`
 }
@@ -49,6 +52,10 @@ const (
 	ErrCodeServiceUnavail   = "SERVICE_UNAVAILABLE"
 	ErrCodeRateLimited      = "RATE_LIMITED"
 	ErrCodeInsufficientFunds = "INSUFFICIENT_FUNDS"
+	ErrCodePeriodClosed      = "PERIOD_CLOSED"
+
+	// An idempotency key was reused for a different request
+	ErrCodeIdempotencyMismatch = "IDEMPOTENCY_FINGERPRINT_MISMATCH"
 )
 
 // WriteJSON writes a JSON response
@@ -124,18 +131,27 @@ func InternalError(w http.ResponseWriter, message string) {
 
 // ValidationError writes a 422 response with validation details
 func ValidationError(w http.ResponseWriter, err error) {
-	var validationErrors validator.ValidationErrors
-	if errors.As(err, &validationErrors) {
-		details := make(map[string]string)
-		for _, e := range validationErrors {
-			details[e.Field()] = formatValidationError(e)
-		}
+	if details, ok := ValidationDetails(err); ok {
 		WriteErrorWithDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "Validation failed", details)
 		return
 	}
 	WriteError(w, http.StatusUnprocessableEntity, ErrCodeValidation, err.Error())
 }
 
+// ValidationDetails returns a message for each invalid field of a validation
+// error, or false if err is not one
+func ValidationDetails(err error) (map[string]string, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+	details := make(map[string]string)
+	for _, e := range validationErrors {
+		details[e.Field()] = formatValidationError(e)
+	}
+	return details, true
+}
+
 func formatValidationError(e validator.FieldError) string {
 	switch e.Tag() {
 	case "required":
@@ -195,8 +211,8 @@ func GetPaginationParams(r *http.Request, defaultLimit, maxLimit int) Pagination
 
 	if limit := r.URL.Query().Get("limit"); limit != "" {
 		var l int
-		if _, err := json.Number(limit).Int64(); err == nil {
-			l, _ = r.URL.Query().Get("limit"), 0
+		if n, err := json.Number(limit).Int64(); err == nil {
+			l = int(n)
 		}
 		if l > 0 && l <= maxLimit {
 			params.Limit = l
@@ -215,3 +231,29 @@ func GetPaginationParams(r *http.Request, defaultLimit, maxLimit int) Pagination
 
 	return params
 }
+
+// ErrInvalidCursor is returned for a cursor that EncodeCursor did not make
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor encodes a keyset position as an opaque cursor for next_cursor
+func EncodeCursor(position interface{}) string {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a cursor made by EncodeCursor into position
+func DecodeCursor(cursor string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(position); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
@@ -40,6 +40,11 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*DB, error) {
 	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
 	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
 
+	// Scope each connection to the tenant of the context it is used under
+	scopes := newSessionScopes()
+	poolConfig.BeforeAcquire = scopes.beforeAcquire
+	poolConfig.BeforeClose = scopes.beforeClose
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("creating connection pool: %w", err)
@@ -242,6 +247,20 @@ func (db *DB) BulkInsert(ctx context.Context, tableName string, columns []string
 	return copyCount, nil
 }
 
+// BulkInsertTx performs a bulk insert using COPY protocol within a transaction
+func BulkInsertTx(ctx context.Context, tx pgx.Tx, tableName string, columns []string, rows [][]interface{}) (int64, error) {
+	copyCount, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{tableName},
+		columns,
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("bulk insert into %s: %w", tableName, err)
+	}
+	return copyCount, nil
+}
+
 // HealthCheck performs a health check on the database
 func (db *DB) HealthCheck(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+
+	"finplatform/internal/common/tenancy"
+)
+
+// Row-level security on tenant tables shows a session only the rows of the
+// tenant in app.tenant_id, or every tenant's with app.system_scope on. The pool
+// sets both on each connection it hands out, from the context it is acquired
+// with, so every query and transaction runs as the context's tenant.
+
+type systemScopeKey struct{}
+
+// WithSystemScope returns a context whose queries see every tenant's rows, for
+// work not done on behalf of one tenant: background jobs, one-off commands and
+// provider callbacks. A tenant in the context still takes precedence.
+func WithSystemScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemScopeKey{}, true)
+}
+
+// hasSystemScope reports whether the context was made by WithSystemScope
+func hasSystemScope(ctx context.Context) bool {
+	v, _ := ctx.Value(systemScopeKey{}).(bool)
+	return v
+}
+
+// sessionScopes tracks the scope last set on each pooled connection, so that a
+// connection handed out again for the same tenant is not set again
+type sessionScopes struct {
+	mu     sync.Mutex
+	scopes map[*pgx.Conn]string
+}
+
+func newSessionScopes() *sessionScopes {
+	return &sessionScopes{scopes: make(map[*pgx.Conn]string)}
+}
+
+// beforeAcquire sets the session variables of a connection to the scope of the
+// context it is acquired with. A connection they cannot be set on is discarded.
+func (s *sessionScopes) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	tenantID := tenancy.TenantID(ctx)
+	systemScope := "off"
+	scope := "tenant:" + tenantID
+	if tenantID == "" && hasSystemScope(ctx) {
+		systemScope = "on"
+		scope = "system"
+	}
+
+	s.mu.Lock()
+	current, ok := s.scopes[conn]
+	s.mu.Unlock()
+	if ok && current == scope {
+		return true
+	}
+
+	_, err := conn.Exec(ctx, `SELECT set_config('app.tenant_id', $1, false), set_config('app.system_scope', $2, false)`,
+		tenantID, systemScope)
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	s.scopes[conn] = scope
+	s.mu.Unlock()
+	return true
+}
+
+// beforeClose forgets a connection leaving the pool
+func (s *sessionScopes) beforeClose(conn *pgx.Conn) {
+	s.mu.Lock()
+	delete(s.scopes, conn)
+	s.mu.Unlock()
+}
@@ -102,14 +102,17 @@ const (
 	// Ledger events
 	EventLedgerAccountCreated = "ledger.account.created"
 	EventLedgerBatchPosted    = "ledger.batch.posted"
+	EventLedgerBatchReversed  = "ledger.batch.reversed"
+	EventLedgerAuditFailed    = "ledger.audit.discrepancies"
 
 	// Wallet events
-	EventWalletCreated      = "wallet.created"
-	EventWalletCredited     = "wallet.credited"
-	EventWalletDebited      = "wallet.debited"
-	EventWalletHoldCreated  = "wallet.hold.created"
-	EventWalletHoldReleased = "wallet.hold.released"
-	EventWalletHoldCaptured = "wallet.hold.captured"
+	EventWalletCreated        = "wallet.created"
+	EventWalletAccountCreated = "wallet.account.created"
+	EventWalletCredited       = "wallet.credited"
+	EventWalletDebited        = "wallet.debited"
+	EventWalletHoldCreated    = "wallet.hold.created"
+	EventWalletHoldReleased   = "wallet.hold.released"
+	EventWalletHoldCaptured   = "wallet.hold.captured"
 
 	// Deposit events
 	EventDepositReceived = "deposit.received"
@@ -165,6 +168,29 @@ type LedgerBatchPostedData struct {
 	Currency      string `json:"currency"`
 }
 
+// LedgerBatchReversedData is the data for ledger.batch.reversed events
+type LedgerBatchReversedData struct {
+	BatchID         string `json:"batch_id"`
+	ReversalBatchID string `json:"reversal_batch_id"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+}
+
+// LedgerAuditFailedData is the data for ledger.audit.discrepancies events
+type LedgerAuditFailedData struct {
+	RunID        string   `json:"run_id"`
+	FindingCount int      `json:"finding_count"`
+	Checks       []string `json:"checks"`
+}
+
+// WalletAccountCreatedData is the data for wallet.account.created events
+type WalletAccountCreatedData struct {
+	WalletID        string `json:"wallet_id"`
+	WalletAccountID string `json:"wallet_account_id"`
+	CustomerID      string `json:"customer_id"`
+	Currency        string `json:"currency"`
+}
+
 // WalletCreditedData is the data for wallet.credited events
 type WalletCreditedData struct {
 	WalletID    string `json:"wallet_id"`
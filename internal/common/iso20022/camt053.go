@@ -0,0 +1,138 @@
+// Package iso20022 holds the ISO 20022 message structures shared by the payment
+// providers, which read them, and the ledger, which writes them.
+package iso20022
+
+import "encoding/xml"
+
+// Camt053Namespace is the namespace of the camt.053 version written
+const Camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// Entry and balance codes
+const (
+	Credit = "CRDT"
+	Debit  = "DBIT"
+
+	EntryBooked  = "BOOK"
+	EntryPending = "PDNG"
+
+	BalanceOpeningBooked = "OPBD"
+	BalanceClosingBooked = "CLBD"
+)
+
+// Camt053 XML structures (ISO 20022 Bank to Customer Statement). Fields are in
+// schema order, so the structures can be marshalled as well as parsed.
+type Camt053Document struct {
+	XMLName       xml.Name             `xml:"Document"`
+	Xmlns         string               `xml:"xmlns,attr,omitempty"`
+	BkToCstmrStmt Camt053BkToCstmrStmt `xml:"BkToCstmrStmt"`
+}
+
+type Camt053BkToCstmrStmt struct {
+	GrpHdr Camt053GrpHdr `xml:"GrpHdr"`
+	Stmt   []Camt053Stmt `xml:"Stmt"`
+}
+
+type Camt053GrpHdr struct {
+	MsgId   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+type Camt053Stmt struct {
+	Id        string            `xml:"Id"`
+	CreDtTm   string            `xml:"CreDtTm,omitempty"`
+	FrToDt    *Camt053FrToDt    `xml:"FrToDt,omitempty"`
+	Acct      *Camt053Acct      `xml:"Acct,omitempty"`
+	Bal       []Camt053Bal      `xml:"Bal"`
+	TxsSummry *Camt053TxsSummry `xml:"TxsSummry,omitempty"`
+	Ntry      []Camt053Ntry     `xml:"Ntry"`
+}
+
+type Camt053FrToDt struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type Camt053Acct struct {
+	Id  Camt053AcctId `xml:"Id"`
+	Ccy string        `xml:"Ccy,omitempty"`
+	Nm  string        `xml:"Nm,omitempty"`
+}
+
+type Camt053AcctId struct {
+	IBAN string       `xml:"IBAN,omitempty"`
+	Othr *Camt053Othr `xml:"Othr,omitempty"`
+}
+
+type Camt053Othr struct {
+	Id string `xml:"Id"`
+}
+
+type Camt053Bal struct {
+	Tp        Camt053BalTp `xml:"Tp"`
+	Amt       Camt053Amt   `xml:"Amt"`
+	CdtDbtInd string       `xml:"CdtDbtInd"`
+	Dt        Camt053Dt    `xml:"Dt"`
+}
+
+type Camt053BalTp struct {
+	CdOrPrtry Camt053CdOrPrtry `xml:"CdOrPrtry"`
+}
+
+type Camt053CdOrPrtry struct {
+	Cd string `xml:"Cd"`
+}
+
+type Camt053TxsSummry struct {
+	TtlCdtNtries Camt053NumberAndSum `xml:"TtlCdtNtries"`
+	TtlDbtNtries Camt053NumberAndSum `xml:"TtlDbtNtries"`
+}
+
+type Camt053NumberAndSum struct {
+	NbOfNtries string `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type Camt053Ntry struct {
+	NtryRef      string            `xml:"NtryRef,omitempty"`
+	Amt          Camt053Amt        `xml:"Amt"`
+	CdtDbtInd    string            `xml:"CdtDbtInd"` // CRDT or DBIT
+	Sts          string            `xml:"Sts"`       // BOOK, PDNG
+	BookgDt      Camt053Dt         `xml:"BookgDt"`
+	ValDt        *Camt053Dt        `xml:"ValDt,omitempty"`
+	AcctSvcrRef  string            `xml:"AcctSvcrRef,omitempty"`
+	BkTxCd       *Camt053BkTxCd    `xml:"BkTxCd,omitempty"`
+	NtryDtls     []Camt053NtryDtls `xml:"NtryDtls"`
+	AddtlNtryInf string            `xml:"AddtlNtryInf,omitempty"`
+}
+
+type Camt053Amt struct {
+	Value string `xml:",chardata"`
+	Ccy   string `xml:"Ccy,attr"`
+}
+
+type Camt053Dt struct {
+	Dt string `xml:"Dt"`
+}
+
+type Camt053BkTxCd struct {
+	Prtry Camt053Prtry `xml:"Prtry"`
+}
+
+type Camt053Prtry struct {
+	Cd string `xml:"Cd"`
+}
+
+type Camt053NtryDtls struct {
+	TxDtls []Camt053TxDtls `xml:"TxDtls"`
+}
+
+type Camt053TxDtls struct {
+	Refs       Camt053Refs `xml:"Refs"`
+	AddtlTxInf string      `xml:"AddtlTxInf,omitempty"`
+}
+
+type Camt053Refs struct {
+	MsgId      string `xml:"MsgId,omitempty"`
+	PmtInfId   string `xml:"PmtInfId,omitempty"`
+	EndToEndId string `xml:"EndToEndId,omitempty"`
+}
@@ -11,15 +11,18 @@ import (
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/tenancy"
 )
 
-// Context keys
+// Context keys. The tenant is kept by package tenancy, which the database
+// pool reads it from.
 type contextKey string
 
 const (
 	CorrelationIDKey contextKey = "correlation_id"
-	TenantIDKey      contextKey = "tenant_id"
 	UserIDKey        contextKey = "user_id"
+	RolesKey         contextKey = "roles"
 	RequestIDKey     contextKey = "request_id"
 )
 
@@ -33,10 +36,13 @@ func GetCorrelationID(ctx context.Context) string {
 
 // GetTenantID retrieves the tenant ID from context
 func GetTenantID(ctx context.Context) string {
-	if v, ok := ctx.Value(TenantIDKey).(string); ok {
-		return v
-	}
-	return ""
+	return tenancy.TenantID(ctx)
+}
+
+// WithTenantID returns a context for work done on behalf of a tenant outside a
+// request, such as a background job working through tenants
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return tenancy.WithTenantID(ctx, tenantID)
 }
 
 // GetUserID retrieves the user ID from context
@@ -47,6 +53,24 @@ func GetUserID(ctx context.Context) string {
 	return ""
 }
 
+// GetRoles retrieves the roles of the user making the request from context
+func GetRoles(ctx context.Context) []string {
+	if v, ok := ctx.Value(RolesKey).([]string); ok {
+		return v
+	}
+	return nil
+}
+
+// HasRole reports whether the user making the request has the role
+func HasRole(ctx context.Context, role string) bool {
+	for _, r := range GetRoles(ctx) {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // CorrelationID middleware adds a correlation ID to each request
 func CorrelationID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -138,7 +162,7 @@ func TenantExtractor(next http.Handler) http.Handler {
 		}
 
 		if tenantID != "" {
-			ctx := context.WithValue(r.Context(), TenantIDKey, tenantID)
+			ctx := tenancy.WithTenantID(r.Context(), tenantID)
 			r = r.WithContext(ctx)
 		}
 
@@ -146,6 +170,45 @@ func TenantExtractor(next http.Handler) http.Handler {
 	})
 }
 
+// UserExtractor extracts the user ID and roles the gateway authenticated from the
+// X-User-ID and X-User-Roles (comma separated) headers. The headers are trusted as
+// they are, so the gateway must strip any sent by the client and set them only
+// from the identity it authenticated.
+func UserExtractor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if userID := r.Header.Get("X-User-ID"); userID != "" {
+			ctx = context.WithValue(ctx, UserIDKey, userID)
+		}
+
+		var roles []string
+		for _, role := range strings.Split(r.Header.Get("X-User-Roles"), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		if len(roles) > 0 {
+			ctx = context.WithValue(ctx, RolesKey, roles)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireRole ensures the user making the request has the role, as set by
+// UserExtractor from the gateway's X-User-Roles header
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasRole(r.Context(), role) {
+				writeError(w, http.StatusForbidden, "FORBIDDEN", "Role "+role+" is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // APIKeyAuth validates API key authentication
 type APIKeyValidator func(ctx context.Context, apiKey string) (tenantID, userID string, err error)
 
@@ -176,7 +239,7 @@ func APIKeyAuth(validator APIKeyValidator) func(http.Handler) http.Handler {
 			}
 
 			ctx := r.Context()
-			ctx = context.WithValue(ctx, TenantIDKey, tenantID)
+			ctx = tenancy.WithTenantID(ctx, tenantID)
 			ctx = context.WithValue(ctx, UserIDKey, userID)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
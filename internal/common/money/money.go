@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 // Currency represents an ISO 4217 currency code
@@ -215,6 +217,31 @@ func (m Money) ToMajor() float64 {
 	return float64(m.AmountMinor) / divisor
 }
 
+// Decimal returns the amount in major units as an exact decimal, e.g. "-12.34".
+// Currencies without info are taken to have two minor units.
+func (m Money) Decimal() string {
+	units := 2
+	if info, ok := currencies[m.Currency]; ok {
+		units = info.MinorUnits
+	}
+
+	sign := ""
+	abs := uint64(m.AmountMinor)
+	if m.AmountMinor < 0 {
+		sign = "-"
+		abs = uint64(-m.AmountMinor)
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	if units == 0 {
+		return sign + digits
+	}
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-units] + "." + digits[len(digits)-units:]
+}
+
 // String returns a human-readable representation
 func (m Money) String() string {
 	info, ok := currencies[m.Currency]
@@ -0,0 +1,21 @@
+// Package tenancy carries the tenant that work is done for in its context. It
+// imports nothing of the platform, so both the HTTP middleware that sets the
+// tenant and the database pool that scopes connections to it can depend on it.
+package tenancy
+
+import "context"
+
+type tenantIDKey struct{}
+
+// WithTenantID returns a context for work done on behalf of tenantID
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, tenantID)
+}
+
+// TenantID returns the tenant the context's work is done for, or "" if none
+func TenantID(ctx context.Context) string {
+	if v, ok := ctx.Value(tenantIDKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/ledger"
+	"finplatform/internal/ledger/domain"
+)
+
+// maxBulkBodyBytes bounds the body of a bulk posting
+const maxBulkBodyBytes = 64 << 20
+
+// BulkItemResult is what became of one batch of a bulk posting
+type BulkItemResult struct {
+	Index           int        `json:"index"`
+	Status          string     `json:"status"` // posted, existing, failed or not_posted
+	BatchID         string     `json:"batch_id,omitempty"`
+	PostingSequence *int64     `json:"posting_sequence,omitempty"`
+	Error           *api.Error `json:"error,omitempty"`
+}
+
+// BulkPostResponse is the response to a bulk posting
+type BulkPostResponse struct {
+	Mode     string           `json:"mode"`
+	Posted   int              `json:"posted"`
+	Existing int              `json:"existing"`
+	Failed   int              `json:"failed"`
+	Results  []BulkItemResult `json:"results"`
+}
+
+// PostBulk handles POST /batches:bulk. The body is a JSON array of batches shaped
+// like POST /entries requests or, with Content-Type application/x-ndjson, one
+// batch per line. mode is atomic (the default), posting every batch or none, or
+// best_effort. The results are in request order; a rejected atomic posting
+// returns 422 with them.
+func (h *Handler) PostBulk(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = domain.BulkModeAtomic
+	}
+	if mode != domain.BulkModeAtomic && mode != domain.BulkModeBestEffort {
+		api.BadRequest(w, "mode must be atomic or best_effort")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkBodyBytes)
+	batches, err := decodeBulkBatches(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+	if len(batches) == 0 {
+		api.BadRequest(w, "at least one batch is required")
+		return
+	}
+
+	req := ledger.BulkPostRequest{
+		TenantID: tenantID,
+		Mode:     mode,
+		Batches:  make([]ledger.PostEntriesRequest, len(batches)),
+		Rejected: make(map[int]error),
+	}
+	for i, batch := range batches {
+		if err := api.Validate.Struct(batch); err != nil {
+			req.Rejected[i] = err
+			continue
+		}
+		req.Batches[i] = toPostEntriesRequest(tenantID, batch)
+	}
+
+	items, err := h.service.PostBulk(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, database.ErrAlreadyExists) {
+			api.Conflict(w, "a batch with one of the idempotency keys was created concurrently; retry the request")
+			return
+		}
+		api.InternalError(w, "failed to post batches")
+		return
+	}
+
+	resp := BulkPostResponse{Mode: mode, Results: make([]BulkItemResult, len(items))}
+	for i, item := range items {
+		result := BulkItemResult{Index: item.Index, Status: item.Status}
+		switch item.Status {
+		case domain.BulkItemPosted, domain.BulkItemExisting:
+			result.BatchID = item.Batch.ID
+			result.PostingSequence = item.Batch.PostingSequence
+		case domain.BulkItemFailed:
+			result.Error = bulkItemError(item.Err)
+		}
+		resp.Results[i] = result
+
+		switch item.Status {
+		case domain.BulkItemPosted:
+			resp.Posted++
+		case domain.BulkItemExisting:
+			resp.Existing++
+		case domain.BulkItemFailed:
+			resp.Failed++
+		}
+	}
+
+	if mode == domain.BulkModeAtomic && resp.Failed > 0 {
+		api.WriteJSON(w, http.StatusUnprocessableEntity, api.Response[BulkPostResponse]{
+			Data: resp,
+			Error: &api.Error{
+				Code:    api.ErrCodeValidation,
+				Message: fmt.Sprintf("%s: %d of %d batches failed", domain.ErrBulkRejected, resp.Failed, len(items)),
+			},
+		})
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, resp)
+}
+
+// decodeBulkBatches reads the batches of a bulk posting as NDJSON or a JSON array
+func decodeBulkBatches(r *http.Request) ([]PostEntriesRequest, error) {
+	var batches []PostEntriesRequest
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-ndjson" {
+		if err := json.NewDecoder(r.Body).Decode(&batches); err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		if len(batches) > ledger.MaxBulkBatches {
+			return nil, fmt.Errorf("at most %d batches are allowed", ledger.MaxBulkBatches)
+		}
+		return batches, nil
+	}
+
+	dec := json.NewDecoder(r.Body)
+	for {
+		var batch PostEntriesRequest
+		if err := dec.Decode(&batch); err != nil {
+			if errors.Is(err, io.EOF) {
+				return batches, nil
+			}
+			return nil, fmt.Errorf("invalid batch on line %d: %w", len(batches)+1, err)
+		}
+		if len(batches) == ledger.MaxBulkBatches {
+			return nil, fmt.Errorf("at most %d batches are allowed", ledger.MaxBulkBatches)
+		}
+		batches = append(batches, batch)
+	}
+}
+
+// bulkItemError describes why a batch of a bulk posting failed, with the codes
+// and details the single posting endpoints use
+func bulkItemError(err error) *api.Error {
+	var constraintErr *domain.BalanceConstraintError
+	var accountErr *domain.EntryAccountError
+
+	if details, ok := api.ValidationDetails(err); ok {
+		return &api.Error{Code: api.ErrCodeValidation, Message: "Validation failed", Details: details}
+	}
+
+	switch {
+	case errors.As(err, &constraintErr):
+		code := api.ErrCodeValidation
+		if errors.Is(err, domain.ErrInsufficientFunds) {
+			code = api.ErrCodeInsufficientFunds
+		}
+		return &api.Error{Code: code, Message: constraintErr.Err.Error(), Details: map[string]string{
+			"account_id": constraintErr.AccountID,
+			"balance":    strconv.FormatInt(constraintErr.Balance, 10),
+			"limit":      strconv.FormatInt(constraintErr.Limit, 10),
+		}}
+	case errors.As(err, &accountErr):
+		return &api.Error{Code: api.ErrCodeValidation, Message: domain.ErrEntryAccount.Error(), Details: accountErr.Details}
+	case errors.Is(err, domain.ErrPeriodClosed):
+		return &api.Error{Code: api.ErrCodePeriodClosed, Message: err.Error()}
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return &api.Error{Code: api.ErrCodeIdempotencyMismatch, Message: err.Error()}
+	default:
+		return &api.Error{Code: api.ErrCodeValidation, Message: err.Error()}
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/middleware"
+)
+
+// VerifyChain handles GET /chain/verify. It walks the tenant's hash chain and
+// reports the first break, if any; a broken chain is still a 200.
+func (h *Handler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	result, err := h.service.VerifyChain(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w, "failed to verify hash chain")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, result)
+}
+
+// ExportChainCheckpoints handles GET /chain/checkpoints/export, which downloads
+// the tenant's signed checkpoints as a JSON file
+func (h *Handler) ExportChainCheckpoints(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	export, err := h.service.ExportChainCheckpoints(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w, "failed to export checkpoints")
+		return
+	}
+
+	filename := fmt.Sprintf("ledger-checkpoints-%s-%s.json", tenantID, export.ExportedAt.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(export)
+}
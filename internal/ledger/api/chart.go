@@ -0,0 +1,358 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// maxChartBodyBytes bounds the body of a chart of accounts import
+const maxChartBodyBytes = 8 << 20
+
+// chartCSVColumns are the fixed columns of a chart of accounts as CSV. Metadata
+// follows in a metadata.<key> column per key.
+var chartCSVColumns = []string{
+	"code", "name", "description", "account_type", "currency", "parent_code", "is_placeholder", "is_system",
+}
+
+// ChartTemplateSummary describes a built-in chart of accounts template
+type ChartTemplateSummary struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	AccountCount int    `json:"account_count"`
+}
+
+// ApplyChartTemplateRequest is the API request for applying a built-in chart
+type ApplyChartTemplateRequest struct {
+	Currency             string   `json:"currency" validate:"required,len=3"`
+	AdditionalCurrencies []string `json:"additional_currencies" validate:"dive,len=3"`
+}
+
+// ExportChart handles GET /chart. format is json (the default), yaml or csv.
+func (h *Handler) ExportChart(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "yaml" && format != "csv" {
+		api.BadRequest(w, "format must be json, yaml or csv")
+		return
+	}
+
+	chart, err := h.service.ExportChart(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w, "failed to export chart of accounts")
+		return
+	}
+
+	switch format {
+	case "csv":
+		header, rows := chartCSVRows(chart)
+		writeCSVWithHeader(w, "chart-of-accounts.csv", header, rows)
+	case "yaml":
+		data, err := yaml.Marshal(chart)
+		if err != nil {
+			api.InternalError(w, "failed to render chart of accounts")
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "chart-of-accounts.yaml"))
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	default:
+		api.WriteData(w, http.StatusOK, chart)
+	}
+}
+
+// ImportChart handles POST /chart/import. The body is a chart as GET /chart
+// exports it: JSON, YAML with Content-Type application/yaml or text/yaml, or CSV
+// with Content-Type text/csv. currency is the currency
+// of accounts that give none. With dry_run=true the diff is returned without
+// changing anything.
+func (h *Handler) ImportChart(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxChartBodyBytes)
+	var chart *domain.Chart
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		if chart, err = parseChartCSV(r.Body); err != nil {
+			api.BadRequest(w, err.Error())
+			return
+		}
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		if err = yaml.NewDecoder(r.Body).Decode(&chart); err != nil || chart == nil {
+			api.BadRequest(w, "invalid request body")
+			return
+		}
+	default:
+		if err = json.NewDecoder(r.Body).Decode(&chart); err != nil || chart == nil {
+			api.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		if len(currency) != 3 {
+			api.BadRequest(w, "currency must be a three letter code")
+			return
+		}
+		chart.Currency = parseStringToCurrency(currency)
+	}
+
+	h.importChart(w, r, tenantID, chart, dryRun)
+}
+
+// ListChartTemplates handles GET /chart/templates
+func (h *Handler) ListChartTemplates(w http.ResponseWriter, r *http.Request) {
+	names := domain.ChartTemplateNames()
+	templates := make([]ChartTemplateSummary, 0, len(names))
+	for _, name := range names {
+		chart, _ := domain.ChartTemplate(name, "")
+		templates = append(templates, ChartTemplateSummary{
+			Name:         chart.Name,
+			Description:  chart.Description,
+			AccountCount: len(chart.Accounts),
+		})
+	}
+
+	api.WriteData(w, http.StatusOK, templates)
+}
+
+// GetChartTemplate handles GET /chart/templates/{name}, optionally in currency
+func (h *Handler) GetChartTemplate(w http.ResponseWriter, r *http.Request) {
+	chart, ok := domain.ChartTemplate(chi.URLParam(r, "name"), parseStringToCurrency(r.URL.Query().Get("currency")))
+	if !ok {
+		api.NotFound(w, "chart template not found")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, chart)
+}
+
+// ApplyChartTemplate handles POST /chart/templates/{name}/apply, importing the
+// built-in chart in currency, with a copy per additional currency. With
+// dry_run=true the diff is returned without changing anything.
+func (h *Handler) ApplyChartTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	var req ApplyChartTemplateRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+
+	additional := make([]money.Currency, len(req.AdditionalCurrencies))
+	for i, c := range req.AdditionalCurrencies {
+		additional[i] = parseStringToCurrency(c)
+	}
+
+	chart, ok := domain.ChartTemplate(chi.URLParam(r, "name"), parseStringToCurrency(req.Currency), additional...)
+	if !ok {
+		api.NotFound(w, "chart template not found")
+		return
+	}
+
+	h.importChart(w, r, tenantID, chart, dryRun)
+}
+
+// importChart imports a chart and writes its diff. A conflicting chart gets a
+// 409 with the diff naming the conflicts.
+func (h *Handler) importChart(w http.ResponseWriter, r *http.Request, tenantID string, chart *domain.Chart, dryRun bool) {
+	diff, err := h.service.ImportChart(r.Context(), tenantID, chart, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChartConflict):
+			api.WriteJSON(w, http.StatusConflict, api.Response[*domain.ChartDiff]{
+				Data:  diff,
+				Error: &api.Error{Code: api.ErrCodeConflict, Message: err.Error()},
+			})
+		case errors.Is(err, domain.ErrInvalidChart):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		default:
+			api.InternalError(w, "failed to import chart of accounts")
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, diff)
+}
+
+// parseDryRun reads dry_run, which asks for the changes to be worked out but not made
+func parseDryRun(r *http.Request) (bool, error) {
+	s := r.URL.Query().Get("dry_run")
+	if s == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New("dry_run must be true or false")
+	}
+	return dryRun, nil
+}
+
+// chartCSVRows returns the CSV header and rows of a chart, with a metadata.<key>
+// column for each metadata key of any of its accounts
+func chartCSVRows(chart *domain.Chart) ([]string, [][]string) {
+	keySet := make(map[string]bool)
+	for _, a := range chart.Accounts {
+		for key := range a.Metadata {
+			keySet[key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	header := append([]string{}, chartCSVColumns...)
+	for _, key := range keys {
+		header = append(header, "metadata."+key)
+	}
+
+	rows := make([][]string, len(chart.Accounts))
+	for i, a := range chart.Accounts {
+		row := []string{
+			a.Code,
+			a.Name,
+			a.Description,
+			string(a.AccountType),
+			string(a.Currency),
+			a.ParentCode,
+			strconv.FormatBool(a.IsPlaceholder),
+			strconv.FormatBool(a.IsSystem),
+		}
+		for _, key := range keys {
+			row = append(row, a.Metadata[key])
+		}
+		rows[i] = row
+	}
+
+	return header, rows
+}
+
+// parseChartCSV reads a chart of accounts from CSV with a header row naming the
+// columns of chartCSVColumns, of which code, name and account_type are required,
+// and metadata.<key> columns. Empty metadata cells are left out.
+func parseChartCSV(r io.Reader) (*domain.Chart, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		known := strings.HasPrefix(name, "metadata.") && len(name) > len("metadata.")
+		for _, c := range chartCSVColumns {
+			known = known || name == c
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("CSV column %q appears twice", name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"code", "name", "account_type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV column %q is required", required)
+		}
+	}
+
+	chart := &domain.Chart{Accounts: make([]domain.ChartAccount, 0)}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return chart, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		flag := func(name string) (bool, error) {
+			s := cell(name)
+			if s == "" {
+				return false, nil
+			}
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return false, fmt.Errorf("line %d: %s must be true or false", line, name)
+			}
+			return v, nil
+		}
+
+		account := domain.ChartAccount{
+			Code:        cell("code"),
+			Name:        cell("name"),
+			Description: cell("description"),
+			AccountType: domain.AccountType(cell("account_type")),
+			Currency:    parseStringToCurrency(cell("currency")),
+			ParentCode:  cell("parent_code"),
+		}
+		if account.IsPlaceholder, err = flag("is_placeholder"); err != nil {
+			return nil, err
+		}
+		if account.IsSystem, err = flag("is_system"); err != nil {
+			return nil, err
+		}
+		for name, i := range columns {
+			if key, ok := strings.CutPrefix(name, "metadata."); ok && record[i] != "" {
+				if account.Metadata == nil {
+					account.Metadata = make(map[string]string)
+				}
+				account.Metadata[key] = record[i]
+			}
+		}
+
+		chart.Accounts = append(chart.Accounts, account)
+	}
+}
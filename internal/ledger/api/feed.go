@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/ledger"
+)
+
+// GetFeed handles GET /feed. It returns posted batches with their entries in
+// posting sequence order after the sequence in after (default 0). Pass the
+// returned next_after as after to resume. wait (seconds) long-polls when there
+// is nothing new.
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	query := r.URL.Query()
+
+	var after int64
+	if afterStr := query.Get("after"); afterStr != "" {
+		n, err := strconv.ParseInt(afterStr, 10, 64)
+		if err != nil || n < 0 {
+			api.BadRequest(w, "after must be a non-negative posting sequence")
+			return
+		}
+		after = n
+	}
+
+	limit := ledger.DefaultFeedLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 || n > ledger.MaxFeedLimit {
+			api.BadRequest(w, "limit must be between 1 and "+strconv.Itoa(ledger.MaxFeedLimit))
+			return
+		}
+		limit = n
+	}
+
+	var wait time.Duration
+	if waitStr := query.Get("wait"); waitStr != "" {
+		n, err := strconv.Atoi(waitStr)
+		maxWait := int(ledger.MaxFeedWait / time.Second)
+		if err != nil || n < 0 || n > maxWait {
+			api.BadRequest(w, "wait must be between 0 and "+strconv.Itoa(maxWait)+" seconds")
+			return
+		}
+		wait = time.Duration(n) * time.Second
+	}
+
+	page, err := h.service.GetFeed(r.Context(), tenantID, after, limit, wait)
+	if err != nil {
+		api.InternalError(w, "failed to read feed")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, page)
+}
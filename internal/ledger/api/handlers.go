@@ -2,7 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -32,28 +37,99 @@ func (h *Handler) Routes() chi.Router {
 	r.Post("/accounts", h.CreateAccount)
 	r.Get("/accounts", h.ListAccounts)
 	r.Get("/accounts/{id}", h.GetAccount)
+	r.Patch("/accounts/{id}", h.UpdateAccount)
 	r.Get("/accounts/{id}/entries", h.GetAccountEntries)
 	r.Get("/accounts/{id}/balance", h.GetAccountBalance)
+	r.Get("/accounts/{id}/positions", h.GetAccountPositions)
+	r.Get("/accounts/{id}/statement", h.GetAccountStatement)
 
 	// Batch/Entry routes
 	r.Post("/entries", h.PostEntries)
+	r.Get("/entries", h.SearchEntries)
+	r.Post("/entries/from-template", h.PostFromTemplate)
+	r.Post("/batches", h.CreatePendingBatch)
+	r.Get("/batches", h.ListBatches)
+	r.Post("/batches:bulk", h.PostBulk)
 	r.Get("/batches/{id}", h.GetBatch)
+	r.Post("/batches/{id}/post", h.PostBatch)
+	r.Post("/batches/{id}/void", h.VoidBatch)
+	r.Post("/batches/{id}/reverse", h.ReverseBatch)
+
+	// Chart of accounts routes
+	r.Get("/chart", h.ExportChart)
+	r.Post("/chart/import", h.ImportChart)
+	r.Get("/chart/templates", h.ListChartTemplates)
+	r.Get("/chart/templates/{name}", h.GetChartTemplate)
+	r.Post("/chart/templates/{name}/apply", h.ApplyChartTemplate)
+
+	// Wallet account routes
+	r.Post("/wallet-accounts/{id}/ledger-account", h.ProvisionWalletAccount)
+
+	// Posting template routes
+	r.Post("/templates", h.CreateTemplate)
+	r.Get("/templates", h.ListTemplates)
+	r.Get("/templates/{name}", h.GetTemplate)
+	r.Put("/templates/{name}", h.UpdateTemplate)
+	r.Delete("/templates/{name}", h.DeleteTemplate)
+
+	// Change feed
+	r.Get("/feed", h.GetFeed)
+
+	// Hash chain
+	r.Get("/chain/verify", h.VerifyChain)
+	r.Get("/chain/checkpoints/export", h.ExportChainCheckpoints)
+
+	// Report routes
+	r.Get("/reports/trial-balance", h.GetTrialBalance)
+	r.Get("/reports/balance-sheet", h.GetBalanceSheet)
+	r.Get("/reports/income-statement", h.GetIncomeStatement)
+
+	// Period routes
+	r.Get("/periods", h.ListPeriods)
+	r.Get("/periods/{period}/audit", h.GetPeriodAudit)
+	r.Post("/periods/{period}/close", h.ClosePeriod)
 
 	// Admin routes
 	r.Post("/init-system-accounts", h.InitializeSystemAccounts)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(RoleLedgerAdmin))
+		r.Post("/admin/periods/{period}/reopen", h.ReopenPeriod)
+	})
 
 	return r
 }
 
 // CreateAccountRequest is the API request for creating an account
 type CreateAccountRequest struct {
-	Code          string `json:"code" validate:"required,max=50"`
-	Name          string `json:"name" validate:"required,max=255"`
-	Description   string `json:"description"`
-	AccountType   string `json:"account_type" validate:"required,oneof=asset liability equity revenue expense"`
-	Currency      string `json:"currency" validate:"required,len=3"`
-	ParentID      string `json:"parent_id"`
-	IsPlaceholder bool   `json:"is_placeholder"`
+	Code          string                   `json:"code" validate:"required,max=50"`
+	Name          string                   `json:"name" validate:"required,max=255"`
+	Description   string                   `json:"description"`
+	AccountType   string                   `json:"account_type" validate:"required,oneof=asset liability equity revenue expense"`
+	Currency      string                   `json:"currency" validate:"required,len=3"`
+	ParentID      string                   `json:"parent_id"`
+	IsPlaceholder bool                     `json:"is_placeholder"`
+	Constraints   *BalanceConstraintsInput `json:"constraints"`
+	Metadata      map[string]string        `json:"metadata"`
+}
+
+// BalanceConstraintsInput is the API form of an account's balance constraints
+type BalanceConstraintsInput struct {
+	NoNegative     bool   `json:"no_negative"`
+	MinBalance     *int64 `json:"min_balance"`
+	MaxBalance     *int64 `json:"max_balance"`
+	OverdraftLimit *int64 `json:"overdraft_limit" validate:"omitempty,gte=0"`
+}
+
+func (c *BalanceConstraintsInput) toDomain() domain.BalanceConstraints {
+	if c == nil {
+		return domain.BalanceConstraints{}
+	}
+	return domain.BalanceConstraints{
+		NoNegative:     c.NoNegative,
+		MinBalance:     c.MinBalance,
+		MaxBalance:     c.MaxBalance,
+		OverdraftLimit: c.OverdraftLimit,
+	}
 }
 
 // CreateAccount handles POST /accounts
@@ -84,6 +160,8 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		Currency:      parseStringToCurrency(req.Currency),
 		ParentID:      parentID,
 		IsPlaceholder: req.IsPlaceholder,
+		Constraints:   req.Constraints.toDomain(),
+		Metadata:      req.Metadata,
 	}
 
 	account, err := h.service.CreateAccount(r.Context(), svcReq)
@@ -92,6 +170,10 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 			api.Conflict(w, "account with this code already exists")
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidConstraints) || errors.Is(err, domain.ErrInvalidMetadata) {
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+			return
+		}
 		api.InternalError(w, "failed to create account")
 		return
 	}
@@ -99,7 +181,10 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	api.WriteData(w, http.StatusCreated, account)
 }
 
-// ListAccounts handles GET /accounts
+// ListAccounts handles GET /accounts. Accounts are listed in code order, limit
+// at a time; pass the returned next_cursor as cursor for the next page.
+// metadata.<key>=<value> parameters only list accounts with those metadata.
+// include_total=true also counts all matching accounts.
 func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	if tenantID == "" {
@@ -113,22 +198,40 @@ func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 		accountType = &t
 	}
 
-	limit := 50
-	offset := 0
-	// Parse limit/offset from query params if needed
+	metadata, err := parseMetadataFilter(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	params := api.GetPaginationParams(r, ledger.DefaultPageLimit, ledger.MaxPageLimit)
+
+	var after *domain.AccountCursor
+	if params.Cursor != "" {
+		after = &domain.AccountCursor{}
+		if err := api.DecodeCursor(params.Cursor, after); err != nil || after.ID == "" {
+			api.BadRequest(w, "invalid cursor")
+			return
+		}
+	}
+
+	withTotal, err := parseIncludeTotal(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
 
-	accounts, total, err := h.service.ListAccounts(r.Context(), tenantID, accountType, limit, offset)
+	page, err := h.service.ListAccounts(r.Context(), tenantID, accountType, metadata, after, params.Limit, withTotal)
 	if err != nil {
 		api.InternalError(w, "failed to list accounts")
 		return
 	}
 
-	api.WritePaginated(w, accounts, &api.Pagination{
-		Limit:   limit,
-		Offset:  offset,
-		Total:   total,
-		HasMore: int64(offset+len(accounts)) < total,
-	})
+	pagination := &api.Pagination{Limit: params.Limit, Total: page.Total, HasMore: page.Next != nil}
+	if page.Next != nil {
+		pagination.NextCursor = api.EncodeCursor(page.Next)
+	}
+	api.WritePaginated(w, page.Accounts, pagination)
 }
 
 // GetAccount handles GET /accounts/{id}
@@ -158,56 +261,322 @@ func (h *Handler) GetAccount(w http.ResponseWriter, r *http.Request) {
 	api.WriteData(w, http.StatusOK, account)
 }
 
-// GetAccountEntries handles GET /accounts/{id}/entries
+// UpdateAccountRequest is the API request for updating an account
+type UpdateAccountRequest struct {
+	Name        *string                  `json:"name" validate:"omitempty,min=1,max=255"`
+	Description *string                  `json:"description"`
+	Constraints *BalanceConstraintsInput `json:"constraints"`
+	Metadata    map[string]*string       `json:"metadata"` // A null value removes the key
+}
+
+// UpdateAccount handles PATCH /accounts/{id}
+func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "account ID required")
+		return
+	}
+
+	var req UpdateAccountRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+
+	svcReq := ledger.UpdateAccountRequest{
+		TenantID:    tenantID,
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Metadata:    req.Metadata,
+	}
+	if req.Constraints != nil {
+		constraints := req.Constraints.toDomain()
+		svcReq.Constraints = &constraints
+	}
+
+	account, err := h.service.UpdateAccount(r.Context(), svcReq)
+	if err != nil {
+		switch {
+		case database.IsNotFound(err):
+			api.NotFound(w, "account not found")
+		case errors.Is(err, domain.ErrInvalidConstraints), errors.Is(err, domain.ErrInvalidMetadata):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		default:
+			api.InternalError(w, "failed to update account")
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, account)
+}
+
+// GetAccountEntries handles GET /accounts/{id}/entries. Entries are listed by
+// creation time, newest first or with order=asc oldest first, limit at a time;
+// pass the returned next_cursor as cursor for the next page. metadata.<key>=<value>
+// parameters only list entries of batches with those metadata. include_total=true
+// also counts all of the account's matching entries.
 func (h *Handler) GetAccountEntries(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		api.BadRequest(w, "account ID required")
 		return
 	}
 
-	limit := 50
-	offset := 0
+	params := api.GetPaginationParams(r, ledger.DefaultPageLimit, ledger.MaxPageLimit)
 
-	entries, total, err := h.service.GetAccountEntries(r.Context(), id, limit, offset)
+	var after *domain.EntryCursor
+	if params.Cursor != "" {
+		after = &domain.EntryCursor{}
+		if err := api.DecodeCursor(params.Cursor, after); err != nil || after.ID == "" {
+			api.BadRequest(w, "invalid cursor")
+			return
+		}
+	}
+
+	descending, err := parseOrderParam(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	withTotal, err := parseIncludeTotal(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	metadata, err := parseMetadataFilter(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	page, err := h.service.GetAccountEntries(r.Context(), tenantID, id, metadata, after, descending, params.Limit, withTotal)
 	if err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "account not found")
+			return
+		}
 		api.InternalError(w, "failed to get entries")
 		return
 	}
 
-	api.WritePaginated(w, entries, &api.Pagination{
-		Limit:   limit,
-		Offset:  offset,
-		Total:   total,
-		HasMore: int64(offset+len(entries)) < total,
-	})
+	pagination := &api.Pagination{Limit: params.Limit, Total: page.Total, HasMore: page.Next != nil}
+	if page.Next != nil {
+		pagination.NextCursor = api.EncodeCursor(page.Next)
+	}
+	api.WritePaginated(w, page.Entries, pagination)
+}
+
+// parseIncludeTotal reads include_total, which asks a listing to count every
+// matching row as well
+func parseIncludeTotal(r *http.Request) (bool, error) {
+	s := r.URL.Query().Get("include_total")
+	if s == "" {
+		return false, nil
+	}
+	withTotal, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New("include_total must be true or false")
+	}
+	return withTotal, nil
 }
 
-// GetAccountBalance handles GET /accounts/{id}/balance
+// parseMetadataFilter reads the metadata.<key>=<value> parameters a listing is
+// filtered by into the pairs the metadata must contain
+func parseMetadataFilter(r *http.Request) (map[string]string, error) {
+	var metadata map[string]string
+	for name, values := range r.URL.Query() {
+		key, ok := strings.CutPrefix(name, "metadata.")
+		if !ok {
+			continue
+		}
+		if key == "" {
+			return nil, errors.New("metadata filters must name a key, as in metadata.<key>=<value>")
+		}
+		if len(values) > 1 {
+			return nil, fmt.Errorf("metadata.%s must be given once", key)
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[key] = values[0]
+	}
+	return metadata, nil
+}
+
+// parseOrderParam reads order, asc or desc (the default), and reports whether a
+// listing runs newest first
+func parseOrderParam(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+		return true, nil
+	case "asc":
+		return false, nil
+	default:
+		return false, errors.New("order must be asc or desc")
+	}
+}
+
+// GetAccountBalance handles GET /accounts/{id}/balance. With as_of (RFC 3339 or
+// YYYY-MM-DD) it returns the historical balance by basis=posting (default) or value.
+// With include_children=true it returns the current balance of the account and
+// all its descendants together.
 func (h *Handler) GetAccountBalance(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		api.BadRequest(w, "account ID required")
 		return
 	}
 
-	balance, err := h.service.GetAccountBalance(r.Context(), id)
+	if s := r.URL.Query().Get("include_children"); s != "" {
+		includeChildren, err := strconv.ParseBool(s)
+		if err != nil {
+			api.BadRequest(w, "include_children must be true or false")
+			return
+		}
+		if includeChildren {
+			if r.URL.Query().Get("as_of") != "" {
+				api.BadRequest(w, "include_children cannot be combined with as_of")
+				return
+			}
+			h.getRollupBalance(w, r, tenantID, id)
+			return
+		}
+	}
+
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		asOf, err := parseAsOf(asOfStr)
+		if err != nil {
+			api.BadRequest(w, "as_of must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+
+		basis, ok := parseBasisParam(w, r)
+		if !ok {
+			return
+		}
+
+		balance, err := h.service.GetAccountBalanceAsOf(r.Context(), tenantID, id, asOf, basis)
+		if err != nil {
+			if database.IsNotFound(err) {
+				api.NotFound(w, "account not found")
+				return
+			}
+			api.InternalError(w, "failed to get balance")
+			return
+		}
+
+		api.WriteData(w, http.StatusOK, balance)
+		return
+	}
+
+	balance, err := h.service.GetAccountBalance(r.Context(), tenantID, id)
 	if err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "account not found")
+			return
+		}
 		api.InternalError(w, "failed to get balance")
 		return
 	}
 
-	api.WriteData(w, http.StatusOK, map[string]int64{"balance": balance})
+	api.WriteData(w, http.StatusOK, balance)
+}
+
+// GetAccountPositions handles GET /accounts/{id}/positions. Positions are by
+// basis=posting (default) or value date.
+func (h *Handler) GetAccountPositions(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "account ID required")
+		return
+	}
+
+	periodType := r.URL.Query().Get("period_type")
+	if periodType == "" {
+		periodType = domain.PeriodTypeMonthly
+	}
+	if !domain.IsValidPeriodType(periodType) {
+		api.BadRequest(w, "period_type must be daily, monthly or yearly")
+		return
+	}
+
+	basis, ok := parseBasisParam(w, r)
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := parseFrom(fromStr)
+		if err != nil {
+			api.BadRequest(w, "from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		from = &t
+	}
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := parseAsOf(toStr)
+		if err != nil {
+			api.BadRequest(w, "to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		to = &t
+	}
+
+	positions, err := h.service.GetAccountPositions(r.Context(), tenantID, id, periodType, basis, from, to)
+	if err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "account not found")
+			return
+		}
+		api.InternalError(w, "failed to get positions")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, positions)
 }
 
 // PostEntriesRequest is the API request for posting entries
 type PostEntriesRequest struct {
-	Reference   string        `json:"reference"`
-	Description string        `json:"description"`
-	SourceType  string        `json:"source_type" validate:"required,oneof=deposit withdrawal payment fee adjustment transfer"`
-	SourceID    string        `json:"source_id"`
-	Currency    string        `json:"currency" validate:"required,len=3"`
-	Entries     []EntryInput  `json:"entries" validate:"required,min=2,dive"`
+	Reference     string            `json:"reference"`
+	Description   string            `json:"description"`
+	SourceType    string            `json:"source_type" validate:"required,oneof=deposit withdrawal payment fee adjustment transfer"`
+	SourceID      string            `json:"source_id"`
+	Currency      string            `json:"currency" validate:"required,len=3"`
+	Entries       []EntryInput      `json:"entries" validate:"required,min=2,dive"`
+	FXLegs        []FXLegInput      `json:"fx_legs" validate:"dive"`
+	EffectiveDate string            `json:"effective_date" validate:"omitempty,datetime=2006-01-02"` // Value date; defaults to today
+	Metadata      map[string]string `json:"metadata"`
+
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
 }
 
 // EntryInput represents a single entry input
@@ -215,9 +584,23 @@ type EntryInput struct {
 	AccountID   string `json:"account_id" validate:"required"`
 	EntryType   string `json:"entry_type" validate:"required,oneof=debit credit"`
 	Amount      int64  `json:"amount" validate:"required,gt=0"`
+	Currency    string `json:"currency" validate:"omitempty,len=3"`
 	Description string `json:"description"`
 }
 
+// FXLegInput represents a currency conversion input
+type FXLegInput struct {
+	SourceAmount             int64  `json:"source_amount" validate:"required,gt=0"`
+	SourceCurrency           string `json:"source_currency" validate:"required,len=3"`
+	CounterAmount            int64  `json:"counter_amount" validate:"required,gt=0"`
+	CounterCurrency          string `json:"counter_currency" validate:"required,len=3"`
+	Rate                     string `json:"rate" validate:"required,numeric"`
+	SourcePositionAccountID  string `json:"source_position_account_id" validate:"required"`
+	CounterPositionAccountID string `json:"counter_position_account_id" validate:"required"`
+	RevenueAccountID         string `json:"revenue_account_id"`
+	RevenueAmount            int64  `json:"revenue_amount" validate:"gte=0"`
+}
+
 // PostEntries handles POST /entries
 func (h *Handler) PostEntries(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
@@ -232,35 +615,102 @@ func (h *Handler) PostEntries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entries := make([]ledger.EntryRequest, len(req.Entries))
-	for i, e := range req.Entries {
-		entries[i] = ledger.EntryRequest{
-			AccountID:   e.AccountID,
-			EntryType:   domain.EntryType(e.EntryType),
-			Amount:      e.Amount,
-			Description: e.Description,
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if req.IdempotencyKey != "" && req.IdempotencyKey != key {
+			api.BadRequest(w, "Idempotency-Key header does not match idempotency_key")
+			return
 		}
+		if len(key) > 255 {
+			api.BadRequest(w, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		req.IdempotencyKey = key
 	}
 
-	svcReq := ledger.PostEntriesRequest{
-		TenantID:    tenantID,
-		Reference:   req.Reference,
-		Description: req.Description,
-		SourceType:  domain.SourceType(req.SourceType),
-		SourceID:    req.SourceID,
-		Currency:    parseStringToCurrency(req.Currency),
-		Entries:     entries,
-	}
+	svcReq := toPostEntriesRequest(tenantID, req)
 
 	batch, err := h.service.PostEntries(r.Context(), svcReq)
 	if err != nil {
-		api.InternalError(w, err.Error())
+		if errors.Is(err, domain.ErrIdempotencyKeyReused) {
+			api.WriteError(w, http.StatusConflict, api.ErrCodeIdempotencyMismatch, err.Error())
+			return
+		}
+		writePostingError(w, err)
 		return
 	}
 
 	api.WriteData(w, http.StatusCreated, batch)
 }
 
+// CreatePendingBatch handles POST /batches
+func (h *Handler) CreatePendingBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	var req PostEntriesRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+
+	batch, err := h.service.CreatePendingBatch(r.Context(), toPostEntriesRequest(tenantID, req))
+	if err != nil {
+		writePostingError(w, err)
+		return
+	}
+
+	api.WriteData(w, http.StatusCreated, batch)
+}
+
+// PostBatch handles POST /batches/{id}/post
+func (h *Handler) PostBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "batch ID required")
+		return
+	}
+
+	batch, err := h.service.PostBatch(r.Context(), tenantID, id, middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeBatchStateError(w, err, "failed to post batch")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, batch)
+}
+
+// VoidBatch handles POST /batches/{id}/void
+func (h *Handler) VoidBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "batch ID required")
+		return
+	}
+
+	batch, err := h.service.VoidBatch(r.Context(), tenantID, id, middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeBatchStateError(w, err, "failed to void batch")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, batch)
+}
+
 // GetBatch handles GET /batches/{id}
 func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
@@ -288,6 +738,63 @@ func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
 	api.WriteData(w, http.StatusOK, batch)
 }
 
+// ReverseBatchRequest is the API request for reversing a batch
+type ReverseBatchRequest struct {
+	Amount        int64  `json:"amount" validate:"gte=0"`
+	Reason        string `json:"reason" validate:"required,max=1000"`
+	EffectiveDate string `json:"effective_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ReverseBatch handles POST /batches/{id}/reverse
+func (h *Handler) ReverseBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "batch ID required")
+		return
+	}
+
+	var req ReverseBatchRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+
+	reversal, err := h.service.ReverseBatch(r.Context(), ledger.ReverseBatchRequest{
+		TenantID: tenantID,
+		BatchID:  id,
+		Amount:   req.Amount,
+		Reason:   req.Reason,
+		UserID:   middleware.GetUserID(r.Context()),
+
+		EffectiveDate: parseEffectiveDate(req.EffectiveDate),
+	})
+	if err != nil {
+		if writeRuleError(w, err) {
+			return
+		}
+
+		switch {
+		case database.IsNotFound(err):
+			api.NotFound(w, "batch not found")
+		case errors.Is(err, domain.ErrBatchNotPosted):
+			api.Conflict(w, err.Error())
+		case errors.Is(err, domain.ErrReversalExceedsRemaining):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		default:
+			api.InternalError(w, "failed to reverse batch")
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusCreated, reversal)
+}
+
 // InitSystemAccountsRequest is the request for initializing system accounts
 type InitSystemAccountsRequest struct {
 	Currency string `json:"currency" validate:"required,len=3"`
@@ -316,6 +823,140 @@ func (h *Handler) InitializeSystemAccounts(w http.ResponseWriter, r *http.Reques
 	api.WriteData(w, http.StatusOK, map[string]string{"status": "initialized"})
 }
 
+// writeBatchStateError maps errors from batch state transitions to responses
+func writeBatchStateError(w http.ResponseWriter, err error, message string) {
+	if writeRuleError(w, err) {
+		return
+	}
+
+	switch {
+	case database.IsNotFound(err):
+		api.NotFound(w, "batch not found")
+	case errors.Is(err, domain.ErrBatchNotPending):
+		api.Conflict(w, err.Error())
+	default:
+		api.InternalError(w, message)
+	}
+}
+
+// writePostingError maps errors from creating or posting new entries to responses
+func writePostingError(w http.ResponseWriter, err error) {
+	if !writeRuleError(w, err) {
+		api.InternalError(w, err.Error())
+	}
+}
+
+// writeRuleError writes the response for a posting the ledger's rules rejected,
+// returning false (and writing nothing) for any other error
+func writeRuleError(w http.ResponseWriter, err error) bool {
+	var constraintErr *domain.BalanceConstraintError
+	switch {
+	case errors.As(err, &constraintErr):
+		writeBalanceConstraintError(w, constraintErr)
+	case errors.Is(err, domain.ErrEntryAccount):
+		writeEntryAccountError(w, err)
+	case errors.Is(err, domain.ErrPeriodClosed):
+		writePeriodClosed(w, err)
+	case errors.Is(err, domain.ErrInvalidMetadata):
+		api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// writeBalanceConstraintError writes a 422 naming the account and limit that a
+// posting would have broken
+func writeBalanceConstraintError(w http.ResponseWriter, err *domain.BalanceConstraintError) {
+	code := api.ErrCodeValidation
+	if errors.Is(err, domain.ErrInsufficientFunds) {
+		code = api.ErrCodeInsufficientFunds
+	}
+	api.WriteErrorWithDetails(w, http.StatusUnprocessableEntity, code, err.Err.Error(), map[string]string{
+		"account_id": err.AccountID,
+		"balance":    strconv.FormatInt(err.Balance, 10),
+		"limit":      strconv.FormatInt(err.Limit, 10),
+	})
+}
+
+// writeEntryAccountError writes a 422 with the entries their accounts cannot take
+func writeEntryAccountError(w http.ResponseWriter, err error) {
+	var accountErr *domain.EntryAccountError
+	if !errors.As(err, &accountErr) {
+		api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		return
+	}
+	api.WriteErrorWithDetails(w, http.StatusUnprocessableEntity, api.ErrCodeValidation,
+		domain.ErrEntryAccount.Error(), accountErr.Details)
+}
+
+func toPostEntriesRequest(tenantID string, req PostEntriesRequest) ledger.PostEntriesRequest {
+	entries := make([]ledger.EntryRequest, len(req.Entries))
+	for i, e := range req.Entries {
+		entries[i] = ledger.EntryRequest{
+			AccountID:   e.AccountID,
+			EntryType:   domain.EntryType(e.EntryType),
+			Amount:      e.Amount,
+			Currency:    parseStringToCurrency(e.Currency),
+			Description: e.Description,
+		}
+	}
+
+	fxLegs := make([]ledger.FXLegRequest, len(req.FXLegs))
+	for i, l := range req.FXLegs {
+		fxLegs[i] = ledger.FXLegRequest{
+			SourceAmount:             l.SourceAmount,
+			SourceCurrency:           parseStringToCurrency(l.SourceCurrency),
+			CounterAmount:            l.CounterAmount,
+			CounterCurrency:          parseStringToCurrency(l.CounterCurrency),
+			Rate:                     l.Rate,
+			SourcePositionAccountID:  l.SourcePositionAccountID,
+			CounterPositionAccountID: l.CounterPositionAccountID,
+			RevenueAccountID:         l.RevenueAccountID,
+			RevenueAmount:            l.RevenueAmount,
+		}
+	}
+
+	return ledger.PostEntriesRequest{
+		TenantID:    tenantID,
+		Reference:   req.Reference,
+		Description: req.Description,
+		SourceType:  domain.SourceType(req.SourceType),
+		SourceID:    req.SourceID,
+		Currency:    parseStringToCurrency(req.Currency),
+		Entries:     entries,
+		FXLegs:      fxLegs,
+
+		EffectiveDate:  parseEffectiveDate(req.EffectiveDate),
+		Metadata:       req.Metadata,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+}
+
+// parseEffectiveDate parses an already validated YYYY-MM-DD value date; empty means none
+func parseEffectiveDate(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
+
+// parseAsOf parses an RFC 3339 timestamp, or a date meaning the end of that day (UTC)
+func parseAsOf(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return d.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
 func parseStringToCurrency(s string) money.Currency {
 	return money.Currency(s)
 }
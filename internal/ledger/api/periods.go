@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/ledger/domain"
+)
+
+// ListPeriods handles GET /periods
+func (h *Handler) ListPeriods(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	periods, err := h.service.ListPeriods(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w, "failed to list periods")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, periods)
+}
+
+// GetPeriodAudit handles GET /periods/{period}/audit
+func (h *Handler) GetPeriodAudit(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	month, ok := parsePeriodParam(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetPeriodAudit(r.Context(), tenantID, month)
+	if err != nil {
+		writePeriodError(w, err, "failed to get period audit")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, entries)
+}
+
+// ClosePeriod handles POST /periods/{period}/close
+func (h *Handler) ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	month, ok := parsePeriodParam(w, r)
+	if !ok {
+		return
+	}
+
+	period, err := h.service.ClosePeriod(r.Context(), tenantID, month, middleware.GetUserID(r.Context()))
+	if err != nil {
+		writePeriodError(w, err, "failed to close period")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, period)
+}
+
+// ReopenPeriodRequest is the API request for reopening a closed period
+type ReopenPeriodRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+// RoleLedgerAdmin is the role a user needs for the ledger's admin actions
+const RoleLedgerAdmin = "ledger_admin"
+
+// ReopenPeriod handles POST /admin/periods/{period}/reopen. Only users with
+// RoleLedgerAdmin may reopen a period; Routes enforces it.
+func (h *Handler) ReopenPeriod(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	// Reopens are audit-logged against the user making them
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		api.Unauthorized(w, "user ID required to reopen a period")
+		return
+	}
+
+	month, ok := parsePeriodParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReopenPeriodRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+
+	period, err := h.service.ReopenPeriod(r.Context(), tenantID, month, userID, req.Reason)
+	if err != nil {
+		writePeriodError(w, err, "failed to reopen period")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, period)
+}
+
+// parsePeriodParam parses the {period} URL parameter (YYYY-MM)
+func parsePeriodParam(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	month, err := time.Parse("2006-01", chi.URLParam(r, "period"))
+	if err != nil {
+		api.BadRequest(w, "period must be a month (YYYY-MM)")
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// writePeriodError maps errors from period workflows to responses
+func writePeriodError(w http.ResponseWriter, err error, message string) {
+	if writeRuleError(w, err) {
+		return
+	}
+
+	switch {
+	case database.IsNotFound(err):
+		api.NotFound(w, "period not found")
+	case errors.Is(err, domain.ErrPeriodNotEnded), errors.Is(err, domain.ErrPeriodNotClosed):
+		api.Conflict(w, err.Error())
+	default:
+		api.InternalError(w, message)
+	}
+}
+
+// writePeriodClosed writes the response for a posting into a closed period
+func writePeriodClosed(w http.ResponseWriter, err error) {
+	api.WriteError(w, http.StatusConflict, api.ErrCodePeriodClosed, err.Error())
+}
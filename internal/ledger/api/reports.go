@@ -0,0 +1,239 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// GetTrialBalance handles GET /reports/trial-balance
+func (h *Handler) GetTrialBalance(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	asOf, basis, currency, ok := parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	reports, err := h.service.GetTrialBalance(r.Context(), tenantID, asOf, basis, currency)
+	if err != nil {
+		api.InternalError(w, "failed to build trial balance")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, reports)
+}
+
+// GetBalanceSheet handles GET /reports/balance-sheet. Add format=csv for CSV output.
+func (h *Handler) GetBalanceSheet(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	asOf, basis, currency, ok := parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	sheets, err := h.service.GetBalanceSheet(r.Context(), tenantID, asOf, basis, currency)
+	if err != nil {
+		api.InternalError(w, "failed to build balance sheet")
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		var rows [][]string
+		for _, sheet := range sheets {
+			rows = append(rows, sectionRows(sheet.Currency, "assets", sheet.Assets)...)
+			rows = append(rows, sectionRows(sheet.Currency, "liabilities", sheet.Liabilities)...)
+			rows = append(rows, sectionRows(sheet.Currency, "equity", sheet.Equity)...)
+			rows = append(rows, totalRow(sheet.Currency, "total", "Total Liabilities and Equity", sheet.TotalLiabilitiesAndEquity))
+		}
+		writeCSV(w, fmt.Sprintf("balance-sheet-%s.csv", reportDate(asOf)), rows)
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, sheets)
+}
+
+// GetIncomeStatement handles GET /reports/income-statement. The range defaults to
+// the current month to date. Add format=csv for CSV output.
+func (h *Handler) GetIncomeStatement(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	currency, ok := parseCurrencyParam(w, r)
+	if !ok {
+		return
+	}
+
+	basis, ok := parseBasisParam(w, r)
+	if !ok {
+		return
+	}
+
+	end := time.Now().UTC()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := parseAsOf(toStr)
+		if err != nil {
+			api.BadRequest(w, "to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		end = t
+	}
+
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := parseFrom(fromStr)
+		if err != nil {
+			api.BadRequest(w, "from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		start = t
+	}
+
+	if end.Before(start) {
+		api.BadRequest(w, "to must not be before from")
+		return
+	}
+
+	statements, err := h.service.GetIncomeStatement(r.Context(), tenantID, start, end, basis, currency)
+	if err != nil {
+		api.InternalError(w, "failed to build income statement")
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		var rows [][]string
+		for _, statement := range statements {
+			rows = append(rows, sectionRows(statement.Currency, "revenue", statement.Revenue)...)
+			rows = append(rows, sectionRows(statement.Currency, "expenses", statement.Expenses)...)
+			rows = append(rows, totalRow(statement.Currency, "total", "Net Income", statement.NetIncome))
+		}
+		writeCSV(w, fmt.Sprintf("income-statement-%s-%s.csv", start.Format("2006-01-02"), end.Format("2006-01-02")), rows)
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, statements)
+}
+
+// parseReportParams parses the as_of, basis and currency query parameters,
+// writing a bad request response and returning false if any is invalid
+func parseReportParams(w http.ResponseWriter, r *http.Request) (*time.Time, domain.DateBasis, money.Currency, bool) {
+	var asOf *time.Time
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		t, err := parseAsOf(asOfStr)
+		if err != nil {
+			api.BadRequest(w, "as_of must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return nil, "", "", false
+		}
+		asOf = &t
+	}
+
+	basis, ok := parseBasisParam(w, r)
+	if !ok {
+		return nil, "", "", false
+	}
+
+	currency, ok := parseCurrencyParam(w, r)
+	if !ok {
+		return nil, "", "", false
+	}
+
+	return asOf, basis, currency, true
+}
+
+// parseBasisParam parses the optional basis query parameter (posting or value),
+// which defaults to posting
+func parseBasisParam(w http.ResponseWriter, r *http.Request) (domain.DateBasis, bool) {
+	basis := domain.DateBasis(r.URL.Query().Get("basis"))
+	if basis == "" {
+		return domain.DateBasisPosting, true
+	}
+	if !domain.IsValidDateBasis(basis) {
+		api.BadRequest(w, "basis must be posting or value")
+		return "", false
+	}
+	return basis, true
+}
+
+// parseCurrencyParam parses the optional currency query parameter
+func parseCurrencyParam(w http.ResponseWriter, r *http.Request) (money.Currency, bool) {
+	currency := r.URL.Query().Get("currency")
+	if currency != "" && len(currency) != 3 {
+		api.BadRequest(w, "currency must be a 3-letter code")
+		return "", false
+	}
+	return parseStringToCurrency(currency), true
+}
+
+// parseFrom parses an RFC 3339 timestamp, or a date meaning the start of that day (UTC)
+func parseFrom(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+var reportCSVHeader = []string{"currency", "section", "code", "name", "path", "depth", "balance", "total"}
+
+// sectionRows returns the CSV rows of a report section followed by its total
+func sectionRows(currency money.Currency, name string, section domain.ReportSection) [][]string {
+	rows := make([][]string, 0, len(section.Lines)+1)
+	for _, line := range section.Lines {
+		rows = append(rows, []string{
+			string(currency),
+			name,
+			line.Code,
+			line.Name,
+			line.Path,
+			strconv.Itoa(line.Depth),
+			strconv.FormatInt(line.Balance, 10),
+			strconv.FormatInt(line.Total, 10),
+		})
+	}
+	return append(rows, totalRow(currency, name, "Total "+name, section.Total))
+}
+
+func totalRow(currency money.Currency, section, name string, total int64) []string {
+	return []string{string(currency), section, "", name, "", "", "", strconv.FormatInt(total, 10)}
+}
+
+// writeCSV writes report rows as a CSV attachment
+func writeCSV(w http.ResponseWriter, filename string, rows [][]string) {
+	writeCSVWithHeader(w, filename, reportCSVHeader, rows)
+}
+
+// writeCSVWithHeader writes rows under header as a CSV attachment
+func writeCSVWithHeader(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+}
+
+func reportDate(asOf *time.Time) string {
+	if asOf == nil {
+		return time.Now().UTC().Format("2006-01-02")
+	}
+	return asOf.UTC().Format("2006-01-02")
+}
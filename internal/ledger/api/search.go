@@ -0,0 +1,212 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/ledger"
+	"finplatform/internal/ledger/domain"
+)
+
+// SearchEntries handles GET /entries, a search of all of the tenant's entries.
+// Filters: account_id, account_code, account_type, source_type, source_id,
+// reference and metadata.<key>=<value> (the batch's), description (a case-insensitive substring of the
+// entry's or batch's), entry_type, currency, min_amount and max_amount (minor
+// units, inclusive) and from and to (RFC 3339 or YYYY-MM-DD, inclusive) dated by
+// basis=posting (default) or value. Entries are listed like GetAccountEntries.
+func (h *Handler) SearchEntries(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	filter, err := parseEntryFilter(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	params := api.GetPaginationParams(r, ledger.DefaultPageLimit, ledger.MaxPageLimit)
+
+	var after *domain.EntryCursor
+	if params.Cursor != "" {
+		after = &domain.EntryCursor{}
+		if err := api.DecodeCursor(params.Cursor, after); err != nil || after.ID == "" {
+			api.BadRequest(w, "invalid cursor")
+			return
+		}
+	}
+
+	descending, err := parseOrderParam(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	withTotal, err := parseIncludeTotal(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	page, err := h.service.SearchEntries(r.Context(), tenantID, filter, after, descending, params.Limit, withTotal)
+	if err != nil {
+		api.InternalError(w, "failed to search entries")
+		return
+	}
+
+	pagination := &api.Pagination{Limit: params.Limit, Total: page.Total, HasMore: page.Next != nil}
+	if page.Next != nil {
+		pagination.NextCursor = api.EncodeCursor(page.Next)
+	}
+	api.WritePaginated(w, page.Entries, pagination)
+}
+
+// parseEntryFilter reads the filters of an entry search from the query
+func parseEntryFilter(r *http.Request) (domain.EntryFilter, error) {
+	q := r.URL.Query()
+	filter := domain.EntryFilter{
+		AccountID:   q.Get("account_id"),
+		AccountCode: q.Get("account_code"),
+		SourceID:    q.Get("source_id"),
+		Reference:   q.Get("reference"),
+		Description: q.Get("description"),
+		Currency:    parseStringToCurrency(q.Get("currency")),
+		Basis:       domain.DateBasis(q.Get("basis")),
+	}
+
+	if s := q.Get("account_type"); s != "" {
+		t := domain.AccountType(s)
+		filter.AccountType = &t
+	}
+	if s := q.Get("source_type"); s != "" {
+		t := domain.SourceType(s)
+		filter.SourceType = &t
+	}
+	if s := q.Get("entry_type"); s != "" {
+		t := domain.EntryType(s)
+		if t != domain.EntryTypeDebit && t != domain.EntryTypeCredit {
+			return filter, errors.New("entry_type must be debit or credit")
+		}
+		filter.EntryType = &t
+	}
+	if filter.Currency != "" && len(filter.Currency) != 3 {
+		return filter, errors.New("currency must be a 3-letter code")
+	}
+
+	metadata, err := parseMetadataFilter(r)
+	if err != nil {
+		return filter, err
+	}
+	filter.Metadata = metadata
+
+	if s := q.Get("min_amount"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return filter, errors.New("min_amount must be an integer in minor units")
+		}
+		filter.MinAmount = &n
+	}
+	if s := q.Get("max_amount"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return filter, errors.New("max_amount must be an integer in minor units")
+		}
+		filter.MaxAmount = &n
+	}
+
+	if filter.Basis == "" {
+		filter.Basis = domain.DateBasisPosting
+	} else if !domain.IsValidDateBasis(filter.Basis) {
+		return filter, errors.New("basis must be posting or value")
+	}
+	if s := q.Get("from"); s != "" {
+		t, err := parseFrom(s)
+		if err != nil {
+			return filter, errors.New("from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		filter.From = &t
+	}
+	if s := q.Get("to"); s != "" {
+		t, err := parseAsOf(s)
+		if err != nil {
+			return filter, errors.New("to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		filter.To = &t
+	}
+
+	return filter, nil
+}
+
+// ListBatches handles GET /batches, a listing of the tenant's batches without
+// their entries. Filters: status, source_type, source_id, reference and
+// metadata.<key>=<value>. Batches are listed by creation time, newest first or
+// with order=asc oldest first, limit at a time; pass the returned next_cursor as
+// cursor for the next page. include_total=true also counts all matching batches.
+func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := domain.BatchFilter{
+		SourceID:  q.Get("source_id"),
+		Reference: q.Get("reference"),
+	}
+	if s := q.Get("status"); s != "" {
+		status := domain.BatchStatus(s)
+		filter.Status = &status
+	}
+	if s := q.Get("source_type"); s != "" {
+		sourceType := domain.SourceType(s)
+		filter.SourceType = &sourceType
+	}
+
+	metadata, err := parseMetadataFilter(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+	filter.Metadata = metadata
+
+	params := api.GetPaginationParams(r, ledger.DefaultPageLimit, ledger.MaxPageLimit)
+
+	var after *domain.BatchCursor
+	if params.Cursor != "" {
+		after = &domain.BatchCursor{}
+		if err := api.DecodeCursor(params.Cursor, after); err != nil || after.ID == "" {
+			api.BadRequest(w, "invalid cursor")
+			return
+		}
+	}
+
+	descending, err := parseOrderParam(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	withTotal, err := parseIncludeTotal(r)
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	page, err := h.service.ListBatches(r.Context(), tenantID, filter, after, descending, params.Limit, withTotal)
+	if err != nil {
+		api.InternalError(w, "failed to list batches")
+		return
+	}
+
+	pagination := &api.Pagination{Limit: params.Limit, Total: page.Total, HasMore: page.Next != nil}
+	if page.Next != nil {
+		pagination.NextCursor = api.EncodeCursor(page.Next)
+	}
+	api.WritePaginated(w, page.Batches, pagination)
+}
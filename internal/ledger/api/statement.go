@@ -0,0 +1,242 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/iso20022"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// Statement formats
+const (
+	statementFormatJSON    = "json"
+	statementFormatCSV     = "csv"
+	statementFormatCamt053 = "camt.053"
+)
+
+var statementCSVHeader = []string{
+	"booking_date", "value_date", "entry_id", "batch_id", "reference", "description",
+	"source_type", "debit", "credit", "balance", "currency",
+}
+
+// GetAccountStatement handles GET /accounts/{id}/statement. It covers the entries
+// dated by basis (posting, the default, or value) in [from, to], which default to
+// the current month to date. format is json (the default), csv or camt.053.
+func (h *Handler) GetAccountStatement(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "account ID required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = statementFormatJSON
+	case statementFormatJSON, statementFormatCSV, statementFormatCamt053:
+	default:
+		api.BadRequest(w, "format must be json, csv or camt.053")
+		return
+	}
+
+	basis, ok := parseBasisParam(w, r)
+	if !ok {
+		return
+	}
+
+	end := time.Now().UTC()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := parseAsOf(toStr)
+		if err != nil {
+			api.BadRequest(w, "to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		end = t
+	}
+
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := parseFrom(fromStr)
+		if err != nil {
+			api.BadRequest(w, "from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		start = t
+	}
+
+	if end.Before(start) {
+		api.BadRequest(w, "to must not be before from")
+		return
+	}
+
+	statement, err := h.service.GetAccountStatement(r.Context(), tenantID, id, start, end, basis)
+	if err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "account not found")
+			return
+		}
+		api.InternalError(w, "failed to build statement")
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s-%s", statement.AccountCode,
+		statement.From.Format("2006-01-02"), statement.To.Format("2006-01-02"))
+
+	switch format {
+	case statementFormatCSV:
+		writeCSVWithHeader(w, filename+".csv", statementCSVHeader, statementCSVRows(statement))
+	case statementFormatCamt053:
+		data, err := xml.MarshalIndent(statementCamt053(statement), "", "  ")
+		if err != nil {
+			api.InternalError(w, "failed to render statement")
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".xml"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(xml.Header))
+		w.Write(data)
+	default:
+		api.WriteData(w, http.StatusOK, statement)
+	}
+}
+
+// statementCSVRows returns the CSV rows of a statement: the opening balance, its
+// entries and the closing balance. Amounts are decimals in major units.
+func statementCSVRows(st *domain.Statement) [][]string {
+	decimal := func(amount int64) string {
+		return money.New(amount, st.Currency).Decimal()
+	}
+
+	rows := make([][]string, 0, len(st.Lines)+2)
+	rows = append(rows, []string{
+		st.From.Format("2006-01-02"), "", "", "", "", "Opening balance", "", "", "",
+		decimal(st.OpeningBalance), string(st.Currency),
+	})
+	for _, line := range st.Lines {
+		var debit, credit string
+		if line.EntryType == domain.EntryTypeDebit {
+			debit = decimal(line.Amount)
+		} else {
+			credit = decimal(line.Amount)
+		}
+		rows = append(rows, []string{
+			line.BookingDate.UTC().Format("2006-01-02"),
+			line.ValueDate.UTC().Format("2006-01-02"),
+			line.EntryID,
+			line.BatchID,
+			line.Reference,
+			line.Description,
+			string(line.SourceType),
+			debit,
+			credit,
+			decimal(line.Balance),
+			string(st.Currency),
+		})
+	}
+	rows = append(rows, []string{
+		st.To.Format("2006-01-02"), "", "", "", "", "Closing balance", "", "", "",
+		decimal(st.ClosingBalance), string(st.Currency),
+	})
+	return rows
+}
+
+// statementCamt053 renders a statement as a camt.053 Bank to Customer Statement.
+// Entries are booked on the day they were posted with their value date; camt.053
+// has no running balance per entry, so it is given in the entry's additional info.
+func statementCamt053(st *domain.Statement) *iso20022.Camt053Document {
+	decimal := func(amount int64) string {
+		if amount < 0 {
+			amount = -amount
+		}
+		return money.New(amount, st.Currency).Decimal()
+	}
+	amount := func(amount int64) iso20022.Camt053Amt {
+		return iso20022.Camt053Amt{Value: decimal(amount), Ccy: string(st.Currency)}
+	}
+	indicator := func(side domain.EntryType) string {
+		if side == domain.EntryTypeDebit {
+			return iso20022.Debit
+		}
+		return iso20022.Credit
+	}
+	balance := func(code string, balance int64, on time.Time) iso20022.Camt053Bal {
+		return iso20022.Camt053Bal{
+			Tp:        iso20022.Camt053BalTp{CdOrPrtry: iso20022.Camt053CdOrPrtry{Cd: code}},
+			Amt:       amount(balance),
+			CdtDbtInd: indicator(st.BalanceSide(balance)),
+			Dt:        iso20022.Camt053Dt{Dt: on.Format("2006-01-02")},
+		}
+	}
+
+	created := st.GeneratedAt.Format(time.RFC3339)
+	stmt := iso20022.Camt053Stmt{
+		Id:      st.ID,
+		CreDtTm: created,
+		FrToDt: &iso20022.Camt053FrToDt{
+			FrDtTm: st.From.Format(time.RFC3339),
+			ToDtTm: st.To.Format(time.RFC3339),
+		},
+		Acct: &iso20022.Camt053Acct{
+			Id:  iso20022.Camt053AcctId{Othr: &iso20022.Camt053Othr{Id: st.AccountID}},
+			Ccy: string(st.Currency),
+			Nm:  st.AccountName,
+		},
+		Bal: []iso20022.Camt053Bal{
+			balance(iso20022.BalanceOpeningBooked, st.OpeningBalance, st.From),
+			balance(iso20022.BalanceClosingBooked, st.ClosingBalance, st.To),
+		},
+		TxsSummry: &iso20022.Camt053TxsSummry{
+			TtlCdtNtries: iso20022.Camt053NumberAndSum{NbOfNtries: strconv.Itoa(st.CreditCount), Sum: decimal(st.TotalCredits)},
+			TtlDbtNtries: iso20022.Camt053NumberAndSum{NbOfNtries: strconv.Itoa(st.DebitCount), Sum: decimal(st.TotalDebits)},
+		},
+		Ntry: make([]iso20022.Camt053Ntry, len(st.Lines)),
+	}
+
+	for i, line := range st.Lines {
+		ntry := iso20022.Camt053Ntry{
+			NtryRef:      line.EntryID,
+			Amt:          amount(line.Amount),
+			CdtDbtInd:    indicator(line.EntryType),
+			Sts:          iso20022.EntryBooked,
+			BookgDt:      iso20022.Camt053Dt{Dt: line.BookingDate.UTC().Format("2006-01-02")},
+			ValDt:        &iso20022.Camt053Dt{Dt: line.ValueDate.UTC().Format("2006-01-02")},
+			AcctSvcrRef:  line.BatchID,
+			BkTxCd:       &iso20022.Camt053BkTxCd{Prtry: iso20022.Camt053Prtry{Cd: string(line.SourceType)}},
+			AddtlNtryInf: fmt.Sprintf("Balance after entry: %s %s", decimal(line.Balance), indicator(st.BalanceSide(line.Balance))),
+		}
+		if line.Reference != "" || line.Description != "" {
+			ntry.NtryDtls = []iso20022.Camt053NtryDtls{{
+				TxDtls: []iso20022.Camt053TxDtls{{
+					Refs:       iso20022.Camt053Refs{EndToEndId: line.Reference},
+					AddtlTxInf: line.Description,
+				}},
+			}}
+		}
+		stmt.Ntry[i] = ntry
+	}
+
+	return &iso20022.Camt053Document{
+		Xmlns: iso20022.Camt053Namespace,
+		BkToCstmrStmt: iso20022.Camt053BkToCstmrStmt{
+			GrpHdr: iso20022.Camt053GrpHdr{MsgId: st.ID, CreDtTm: created},
+			Stmt:   []iso20022.Camt053Stmt{stmt},
+		},
+	}
+}
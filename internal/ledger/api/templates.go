@@ -0,0 +1,256 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/ledger"
+	"finplatform/internal/ledger/domain"
+)
+
+// TemplateRequest is the API request for creating or replacing a posting template
+type TemplateRequest struct {
+	Name        string              `json:"name" validate:"omitempty,max=100"` // Taken from the path on PUT
+	Description string              `json:"description"`
+	SourceType  string              `json:"source_type" validate:"required,oneof=deposit withdrawal payment fee adjustment transfer"`
+	Roles       map[string]string   `json:"roles" validate:"required"`
+	Lines       []TemplateLineInput `json:"lines" validate:"required,min=2,dive"`
+}
+
+// TemplateLineInput is one line of a posting template
+type TemplateLineInput struct {
+	Role        string `json:"role" validate:"required"`
+	EntryType   string `json:"entry_type" validate:"required,oneof=debit credit"`
+	Amount      string `json:"amount" validate:"required"`
+	Description string `json:"description"`
+}
+
+// PostFromTemplateRequest is the API request for posting from a template
+type PostFromTemplateRequest struct {
+	Template      string            `json:"template" validate:"required"`
+	Accounts      map[string]string `json:"accounts"` // Role -> account ID
+	Amounts       map[string]int64  `json:"amounts" validate:"required"`
+	Reference     string            `json:"reference"`
+	Description   string            `json:"description"`
+	SourceID      string            `json:"source_id"`
+	Currency      string            `json:"currency" validate:"required,len=3"`
+	EffectiveDate string            `json:"effective_date" validate:"omitempty,datetime=2006-01-02"`
+	Metadata      map[string]string `json:"metadata"`
+
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+func (req TemplateRequest) toService(tenantID, name string) ledger.SaveTemplateRequest {
+	lines := make([]domain.TemplateLine, len(req.Lines))
+	for i, l := range req.Lines {
+		lines[i] = domain.TemplateLine{
+			Role:        l.Role,
+			EntryType:   domain.EntryType(l.EntryType),
+			Amount:      l.Amount,
+			Description: l.Description,
+		}
+	}
+	return ledger.SaveTemplateRequest{
+		TenantID:    tenantID,
+		Name:        name,
+		Description: req.Description,
+		SourceType:  domain.SourceType(req.SourceType),
+		Roles:       req.Roles,
+		Lines:       lines,
+	}
+}
+
+// CreateTemplate handles POST /templates
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	var req TemplateRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+	if req.Name == "" {
+		api.BadRequest(w, "name required")
+		return
+	}
+
+	template, err := h.service.CreateTemplate(r.Context(), req.toService(tenantID, req.Name))
+	if err != nil {
+		switch {
+		case database.IsUniqueViolation(err):
+			api.Conflict(w, "posting template with this name already exists")
+		case errors.Is(err, domain.ErrInvalidTemplate):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		default:
+			api.InternalError(w, "failed to create posting template")
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusCreated, template)
+}
+
+// ListTemplates handles GET /templates
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	templates, err := h.service.ListTemplates(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w, "failed to list posting templates")
+		return
+	}
+	if templates == nil {
+		templates = make([]*domain.PostingTemplate, 0)
+	}
+
+	api.WriteData(w, http.StatusOK, templates)
+}
+
+// GetTemplate handles GET /templates/{name}
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	template, err := h.service.GetTemplate(r.Context(), tenantID, chi.URLParam(r, "name"))
+	if err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "posting template not found")
+			return
+		}
+		api.InternalError(w, "failed to get posting template")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, template)
+}
+
+// UpdateTemplate handles PUT /templates/{name}, replacing the template
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+
+	var req TemplateRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+	if req.Name != "" && req.Name != name {
+		api.BadRequest(w, "name cannot be changed")
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(r.Context(), req.toService(tenantID, name))
+	if err != nil {
+		switch {
+		case database.IsNotFound(err):
+			api.NotFound(w, "posting template not found")
+		case errors.Is(err, domain.ErrInvalidTemplate):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		default:
+			api.InternalError(w, "failed to update posting template")
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, template)
+}
+
+// DeleteTemplate handles DELETE /templates/{name}
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	if err := h.service.DeleteTemplate(r.Context(), tenantID, chi.URLParam(r, "name")); err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "posting template not found")
+			return
+		}
+		api.InternalError(w, "failed to delete posting template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PostFromTemplate handles POST /entries/from-template. It posts the entries the
+// named template expands to, given the accounts bound to its roles and its
+// amounts, with the same idempotency as POST /entries.
+func (h *Handler) PostFromTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	var req PostFromTemplateRequest
+	if err := api.DecodeAndValidate(r, &req); err != nil {
+		api.ValidationError(w, err)
+		return
+	}
+
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if req.IdempotencyKey != "" && req.IdempotencyKey != key {
+			api.BadRequest(w, "Idempotency-Key header does not match idempotency_key")
+			return
+		}
+		if len(key) > 255 {
+			api.BadRequest(w, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		req.IdempotencyKey = key
+	}
+
+	batch, err := h.service.PostFromTemplate(r.Context(), ledger.PostFromTemplateRequest{
+		TenantID:       tenantID,
+		Template:       req.Template,
+		Accounts:       req.Accounts,
+		Amounts:        req.Amounts,
+		Reference:      req.Reference,
+		Description:    req.Description,
+		SourceID:       req.SourceID,
+		Currency:       parseStringToCurrency(req.Currency),
+		EffectiveDate:  parseEffectiveDate(req.EffectiveDate),
+		Metadata:       req.Metadata,
+		IdempotencyKey: req.IdempotencyKey,
+	})
+	if err != nil {
+		switch {
+		case database.IsNotFound(err):
+			api.NotFound(w, "posting template not found")
+		case errors.Is(err, domain.ErrTemplateArguments):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		case errors.Is(err, domain.ErrIdempotencyKeyReused):
+			api.WriteError(w, http.StatusConflict, api.ErrCodeIdempotencyMismatch, err.Error())
+		default:
+			writePostingError(w, err)
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusCreated, batch)
+}
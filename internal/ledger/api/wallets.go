@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"finplatform/internal/common/api"
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/ledger/domain"
+)
+
+// ProvisionWalletAccount handles POST /wallet-accounts/{id}/ledger-account,
+// creating the wallet account's ledger account under 2000 if it has none yet.
+// It returns the wallet account's ledger account either way.
+func (h *Handler) ProvisionWalletAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.BadRequest(w, "tenant ID required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		api.BadRequest(w, "wallet account ID required")
+		return
+	}
+
+	account, err := h.service.ProvisionWalletAccount(r.Context(), tenantID, id)
+	if err != nil {
+		switch {
+		case database.IsNotFound(err):
+			api.NotFound(w, "wallet account not found")
+		case errors.Is(err, domain.ErrNoWalletParent):
+			api.WriteError(w, http.StatusUnprocessableEntity, api.ErrCodeValidation, err.Error())
+		case errors.Is(err, database.ErrAlreadyExists):
+			api.Conflict(w, "wallet account is linked to another ledger account")
+		default:
+			api.InternalError(w, "failed to provision wallet ledger account")
+		}
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, account)
+}
+
+// getRollupBalance writes the current balance of an account and its descendants
+func (h *Handler) getRollupBalance(w http.ResponseWriter, r *http.Request, tenantID, id string) {
+	balance, err := h.service.GetAccountRollupBalance(r.Context(), tenantID, id)
+	if err != nil {
+		if database.IsNotFound(err) {
+			api.NotFound(w, "account not found")
+			return
+		}
+		api.InternalError(w, "failed to get balance")
+		return
+	}
+
+	api.WriteData(w, http.StatusOK, balance)
+}
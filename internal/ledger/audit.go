@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/events"
+	"finplatform/internal/ledger/domain"
+)
+
+// RunAudit runs the integrity audit over a tenant's ledger and stores the run
+// and its findings. If anything was found, a ledger.audit.discrepancies event
+// is queued in the outbox with them.
+func (s *Service) RunAudit(ctx context.Context, tenantID string) (*domain.AuditRun, error) {
+	run := &domain.AuditRun{
+		ID:        ulid.Make().String(),
+		TenantID:  tenantID,
+		StartedAt: time.Now().UTC(),
+	}
+
+	findings, err := s.store.AuditTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing tenant %s: %w", tenantID, err)
+	}
+	run.Finish(findings)
+
+	var event *events.Event
+	if run.FindingCount > 0 {
+		event, err = s.CreateAuditFailedEvent(run)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.SaveAuditRun(ctx, run, event); err != nil {
+		return nil, err
+	}
+
+	if run.FindingCount > 0 {
+		s.logger.Error("ledger audit found discrepancies",
+			"run_id", run.ID,
+			"tenant_id", tenantID,
+			"findings", run.FindingCount,
+			"checks", run.Checks(),
+		)
+	} else {
+		s.logger.Info("ledger audit clean", "run_id", run.ID, "tenant_id", tenantID)
+	}
+
+	return run, nil
+}
+
+// RunAudits runs the audit for a tenant, or for every tenant if tenantID is
+// empty, and returns the runs. A tenant whose audit fails is logged and skipped;
+// the failures are returned together once every tenant has been audited.
+func (s *Service) RunAudits(ctx context.Context, tenantID string) ([]*domain.AuditRun, error) {
+	tenants := []string{tenantID}
+	if tenantID == "" {
+		var err error
+		tenants, err = s.store.ListLedgerTenants(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	runs := make([]*domain.AuditRun, 0, len(tenants))
+	var errs []error
+	for _, id := range tenants {
+		run, err := s.RunAudit(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return runs, err
+			}
+			s.logger.Error("ledger audit failed", "tenant_id", id, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, errors.Join(errs...)
+}
+
+// CreateAuditFailedEvent creates an event for an audit run that found discrepancies
+func (s *Service) CreateAuditFailedEvent(run *domain.AuditRun) (*events.Event, error) {
+	data := events.LedgerAuditFailedData{
+		RunID:        run.ID,
+		FindingCount: run.FindingCount,
+		Checks:       run.Checks(),
+	}
+
+	return events.NewEvent(
+		events.EventLedgerAuditFailed,
+		run.TenantID,
+		"ledger_audit_run",
+		run.ID,
+		data,
+	)
+}
+
+// AuditJob periodically runs the integrity audit over every tenant
+type AuditJob struct {
+	service  *Service
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+// NewAuditJob creates a job that audits every tenant's ledger every interval
+func NewAuditJob(service *Service, logger *slog.Logger, interval time.Duration) *AuditJob {
+	return &AuditJob{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run runs the job until the context is cancelled
+func (j *AuditJob) Run(ctx context.Context) {
+	// The job works through every tenant
+	ctx = database.WithSystemScope(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.service.RunAudits(ctx, ""); err != nil && ctx.Err() == nil {
+			j.logger.Error("audit job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"finplatform/internal/ledger/domain"
+)
+
+// MaxBulkBatches is the most batches a bulk posting may hold
+const MaxBulkBatches = 50000
+
+// BulkPostRequest is a request to post many batches of one tenant at once
+type BulkPostRequest struct {
+	TenantID string
+	Mode     string               // domain.BulkModeAtomic (the default) or domain.BulkModeBestEffort
+	Batches  []PostEntriesRequest // Their tenant is set from TenantID
+	Rejected map[int]error        // Batches the caller already found invalid, by index
+}
+
+// PostBulk creates and posts many batches in one go, returning what became of each
+// in request order. In atomic mode every batch is posted or, if any fails, none;
+// in best effort mode the valid batches are posted and the rest reported failed.
+// Idempotency keys work as they do for PostEntries.
+func (s *Service) PostBulk(ctx context.Context, req BulkPostRequest) ([]*domain.BulkItem, error) {
+	if len(req.Batches) > MaxBulkBatches {
+		return nil, fmt.Errorf("bulk posting holds %d batches, at most %d are allowed", len(req.Batches), MaxBulkBatches)
+	}
+	atomic := req.Mode != domain.BulkModeBestEffort
+
+	items := make([]*domain.BulkItem, len(req.Batches))
+	keys := make(map[string]int)
+	for i, r := range req.Batches {
+		item := &domain.BulkItem{Index: i}
+		items[i] = item
+
+		if err := req.Rejected[i]; err != nil {
+			item.Fail(err)
+			continue
+		}
+
+		r.TenantID = req.TenantID
+		batch, err := s.buildBatch(r)
+		if err != nil {
+			item.Fail(err)
+			continue
+		}
+
+		if key := r.idempotencyKey(); key != "" {
+			if j, ok := keys[key]; ok {
+				item.Fail(fmt.Errorf("%w: %s is also used by batch %d", domain.ErrIdempotencyKeyReused, key, j))
+				continue
+			}
+			keys[key] = i
+
+			batch.IdempotencyKey = key
+			batch.Fingerprint, err = r.fingerprint()
+			if err != nil {
+				return nil, err
+			}
+		}
+		item.Batch = batch
+	}
+
+	if atomic && domain.BulkFailed(items) {
+		domain.RejectBulk(items)
+		return items, nil
+	}
+
+	err := s.store.PostBulk(ctx, req.TenantID, items, atomic)
+	if err != nil && !errors.Is(err, domain.ErrBulkRejected) {
+		return nil, err
+	}
+
+	var posted, failed int
+	for _, item := range items {
+		switch item.Status {
+		case domain.BulkItemPosted:
+			posted++
+		case domain.BulkItemFailed:
+			failed++
+		}
+	}
+	s.logger.Info("bulk batches posted",
+		"tenant_id", req.TenantID,
+		"atomic", atomic,
+		"batches", len(items),
+		"posted", posted,
+		"failed", failed,
+	)
+
+	return items, nil
+}
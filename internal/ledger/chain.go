@@ -0,0 +1,220 @@
+package ledger
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+// ErrNoCheckpointKey is returned when checkpoints are requested without a signing key
+var ErrNoCheckpointKey = errors.New("no checkpoint signing key configured")
+
+// chainPageSize is how many batches VerifyChain loads at a time
+const chainPageSize = 500
+
+// SetCheckpointKey sets the key chain checkpoints are signed with. Unless
+// SetCheckpointPublicKey sets another, checkpoints are verified with its public key.
+func (s *Service) SetCheckpointKey(key ed25519.PrivateKey) {
+	s.checkpointKey = key
+}
+
+// SetCheckpointPublicKey sets the key chain checkpoints are verified with, for a
+// service that verifies checkpoints without signing them
+func (s *Service) SetCheckpointPublicKey(key ed25519.PublicKey) {
+	s.checkpointPublicKey = key
+}
+
+// checkpointVerifyKey returns the key checkpoints are verified with, nil if none
+// is configured
+func (s *Service) checkpointVerifyKey() ed25519.PublicKey {
+	if s.checkpointPublicKey != nil {
+		return s.checkpointPublicKey
+	}
+	if s.checkpointKey != nil {
+		return s.checkpointKey.Public().(ed25519.PublicKey)
+	}
+	return nil
+}
+
+// VerifyChain walks a tenant's hash chain from its start to its current head,
+// recomputing the hash of every batch, and checks the tenant's checkpoints
+// against it. Without a configured checkpoint key the checkpoints cannot be
+// trusted, so they are only counted as unverified. The result reports the first
+// break found, if any.
+func (s *Service) VerifyChain(ctx context.Context, tenantID string) (*domain.ChainVerification, error) {
+	// Checkpoints first, so none can be newer than the head
+	checkpoints, err := s.store.ListChainCheckpoints(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := s.store.GetChainHead(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ChainVerification{
+		TenantID:   tenantID,
+		ChainStart: head.ChainStart,
+		Head:       head,
+		VerifiedAt: time.Now().UTC(),
+	}
+
+	key := s.checkpointVerifyKey()
+	if key == nil {
+		result.Unverified = len(checkpoints)
+		checkpoints = nil
+	}
+
+	bySeq := make(map[int64]*domain.ChainCheckpoint, len(checkpoints))
+	for _, c := range checkpoints {
+		if !c.VerifySignature(key) {
+			result.Break = &domain.ChainBreak{Sequence: c.Sequence, Reason: domain.ChainBreakSignature, Actual: c.Signature}
+			return result, nil
+		}
+		if c.Sequence < head.ChainStart || c.Sequence > head.Sequence {
+			result.Break = &domain.ChainBreak{Sequence: c.Sequence, Reason: domain.ChainBreakCheckpoint, Expected: c.ChainHash}
+			return result, nil
+		}
+		bySeq[c.Sequence] = c
+	}
+
+	// Batches posted after head was read are left for the next run
+	seq, previous := head.ChainStart-1, ""
+	for seq < head.Sequence {
+		batches, err := s.store.ListFeed(ctx, tenantID, seq, chainPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(batches) == 0 {
+			break
+		}
+
+		for _, batch := range batches {
+			if seq == head.Sequence {
+				break
+			}
+			if brk := batch.CheckChainLink(seq+1, previous); brk != nil {
+				result.Break = brk
+				return result, nil
+			}
+			seq, previous = seq+1, batch.ChainHash
+			result.Checked++
+
+			if c, ok := bySeq[seq]; ok {
+				if c.ChainHash != previous {
+					result.Break = &domain.ChainBreak{Sequence: seq, BatchID: batch.ID, Reason: domain.ChainBreakCheckpoint, Expected: c.ChainHash, Actual: previous}
+					return result, nil
+				}
+				result.Checkpoints++
+			}
+		}
+	}
+
+	if seq != head.Sequence {
+		result.Break = &domain.ChainBreak{Sequence: seq + 1, Reason: domain.ChainBreakMissingBatch}
+		return result, nil
+	}
+	if previous != head.Hash {
+		result.Break = &domain.ChainBreak{Sequence: seq, Reason: domain.ChainBreakHead, Expected: head.Hash, Actual: previous}
+		return result, nil
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+// CreateChainCheckpoints signs the chain head of every tenant that has posted
+// since its last checkpoint
+func (s *Service) CreateChainCheckpoints(ctx context.Context) error {
+	if s.checkpointKey == nil {
+		return ErrNoCheckpointKey
+	}
+
+	heads, err := s.store.ListUncheckpointedHeads(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, head := range heads {
+		checkpoint := domain.NewChainCheckpoint(ulid.Make().String(), head, s.checkpointKey)
+		if err := s.store.CreateChainCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+
+		s.logger.Info("chain checkpoint created",
+			"tenant_id", head.TenantID,
+			"sequence", head.Sequence,
+			"chain_hash", head.Hash,
+		)
+	}
+
+	return nil
+}
+
+// ExportChainCheckpoints returns a tenant's chain head and all its checkpoints
+func (s *Service) ExportChainCheckpoints(ctx context.Context, tenantID string) (*domain.CheckpointExport, error) {
+	head, err := s.store.GetChainHead(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints, err := s.store.ListChainCheckpoints(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoints == nil {
+		checkpoints = []*domain.ChainCheckpoint{}
+	}
+
+	return &domain.CheckpointExport{
+		TenantID:    tenantID,
+		ExportedAt:  time.Now().UTC(),
+		Head:        head,
+		Checkpoints: checkpoints,
+	}, nil
+}
+
+// CheckpointJob periodically signs chain checkpoints in the background
+type CheckpointJob struct {
+	service  *Service
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+// NewCheckpointJob creates a job that checkpoints every tenant's chain every interval
+func NewCheckpointJob(service *Service, logger *slog.Logger, interval time.Duration) *CheckpointJob {
+	return &CheckpointJob{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run runs the job until the context is cancelled
+func (j *CheckpointJob) Run(ctx context.Context) {
+	// The job works through every tenant
+	ctx = database.WithSystemScope(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.service.CreateChainCheckpoints(ctx); err != nil && ctx.Err() == nil {
+			j.logger.Error("checkpoint job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/ledger/domain"
+)
+
+// ExportChart exports a tenant's accounts as a chart of accounts
+func (s *Service) ExportChart(ctx context.Context, tenantID string) (*domain.Chart, error) {
+	accounts, err := s.store.ListAllAccounts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ChartFromAccounts(accounts), nil
+}
+
+// ImportChart imports a chart of accounts into a tenant's ledger: accounts it
+// lists that do not exist are created and existing ones get its names,
+// descriptions and metadata, all in one transaction. Nothing is deleted, so
+// importing the same chart again changes nothing. The diff is returned either
+// way; with dryRun nothing is written, and if the chart conflicts with the
+// existing accounts the import fails with domain.ErrChartConflict.
+func (s *Service) ImportChart(ctx context.Context, tenantID string, chart *domain.Chart, dryRun bool) (*domain.ChartDiff, error) {
+	if err := chart.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.ListAllAccounts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := domain.DiffChart(chart, existing)
+	if dryRun {
+		return diff, nil
+	}
+	if len(diff.Conflicts) > 0 {
+		return diff, fmt.Errorf("%w: %d of the accounts conflict", domain.ErrChartConflict, len(diff.Conflicts))
+	}
+
+	byCode := make(map[string]*domain.Account, len(existing)+len(diff.Create))
+	for _, a := range existing {
+		byCode[a.Code] = a
+	}
+	inChart := make(map[string]domain.ChartAccount, len(chart.Accounts))
+	for _, ca := range chart.Accounts {
+		inChart[ca.Code] = ca
+	}
+
+	// diff.Create is in chart order, parents first
+	create := make([]*domain.Account, 0, len(diff.Create))
+	for _, ca := range diff.Create {
+		account, err := domain.NewAccount(ulid.Make().String(), tenantID, ca.Code, ca.Name, ca.AccountType, ca.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("creating account %s: %w", ca.Code, err)
+		}
+		account.Description = ca.Description
+		account.IsPlaceholder = ca.IsPlaceholder
+		account.IsSystem = ca.IsSystem
+		for key, value := range ca.Metadata {
+			account.Metadata[key] = value
+		}
+		if ca.ParentCode != "" {
+			if err := account.SetParent(byCode[ca.ParentCode]); err != nil {
+				return nil, fmt.Errorf("creating account %s: %w", ca.Code, err)
+			}
+		}
+		byCode[ca.Code] = account
+		create = append(create, account)
+	}
+
+	now := time.Now().UTC()
+	update := make([]*domain.Account, 0, len(diff.Update))
+	for _, change := range diff.Update {
+		account, ca := byCode[change.Code], inChart[change.Code]
+		account.Name = ca.Name
+		account.Description = ca.Description
+		if account.Metadata == nil {
+			account.Metadata = make(map[string]string, len(ca.Metadata))
+		}
+		for key, value := range ca.Metadata {
+			account.Metadata[key] = value
+		}
+		if err := domain.ValidateMetadata(account.Metadata); err != nil {
+			return nil, fmt.Errorf("%w: account %s: %w", domain.ErrInvalidChart, account.Code, err)
+		}
+		account.UpdatedAt = now
+		update = append(update, account)
+	}
+
+	if err := s.store.ImportAccounts(ctx, create, update); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("chart of accounts imported",
+		"tenant_id", tenantID,
+		"created", len(create),
+		"updated", len(update),
+	)
+
+	return diff, nil
+}
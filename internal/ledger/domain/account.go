@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"finplatform/internal/common/money"
@@ -37,22 +38,164 @@ const (
 
 // Account represents a ledger account
 type Account struct {
-	ID            string            `json:"id"`
-	TenantID      string            `json:"tenant_id"`
-	Code          string            `json:"code"`
-	Name          string            `json:"name"`
-	Description   string            `json:"description,omitempty"`
-	AccountType   AccountType       `json:"account_type"`
-	NormalBalance NormalBalance     `json:"normal_balance"`
-	Currency      money.Currency    `json:"currency"`
-	ParentID      *string           `json:"parent_id,omitempty"`
-	Path          string            `json:"path"`
-	IsSystem      bool              `json:"is_system"`
-	IsPlaceholder bool              `json:"is_placeholder"`
-	Status        AccountStatus     `json:"status"`
-	Metadata      map[string]string `json:"metadata,omitempty"`
-	CreatedAt     time.Time         `json:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at"`
+	ID            string             `json:"id"`
+	TenantID      string             `json:"tenant_id"`
+	Code          string             `json:"code"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	AccountType   AccountType        `json:"account_type"`
+	NormalBalance NormalBalance      `json:"normal_balance"`
+	Currency      money.Currency     `json:"currency"`
+	ParentID      *string            `json:"parent_id,omitempty"`
+	Path          string             `json:"path"`
+	IsSystem      bool               `json:"is_system"`
+	IsPlaceholder bool               `json:"is_placeholder"`
+	Status        AccountStatus      `json:"status"`
+	Metadata      map[string]string  `json:"metadata,omitempty"`
+	Constraints   BalanceConstraints `json:"constraints"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// Balance constraint errors
+var (
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrMaxBalanceExceeded = errors.New("maximum balance exceeded")
+	ErrInvalidConstraints = errors.New("invalid balance constraints")
+)
+
+// BalanceConstraints limit the posted balance of an account, signed by its normal side
+type BalanceConstraints struct {
+	NoNegative     bool   `json:"no_negative"` // Not below zero, less any overdraft limit
+	MinBalance     *int64 `json:"min_balance,omitempty"`
+	MaxBalance     *int64 `json:"max_balance,omitempty"`
+	OverdraftLimit *int64 `json:"overdraft_limit,omitempty"` // How far below zero the balance may go
+}
+
+// Validate checks the constraints are consistent
+func (c BalanceConstraints) Validate() error {
+	if c.OverdraftLimit != nil && *c.OverdraftLimit < 0 {
+		return fmt.Errorf("%w: overdraft limit must not be negative", ErrInvalidConstraints)
+	}
+	if floor, ok := c.Floor(); ok && c.MaxBalance != nil && floor > *c.MaxBalance {
+		return fmt.Errorf("%w: maximum balance is below the lowest allowed balance", ErrInvalidConstraints)
+	}
+	return nil
+}
+
+// Floor returns the lowest balance allowed, if there is one
+func (c BalanceConstraints) Floor() (int64, bool) {
+	var floor int64
+	ok := false
+	if c.NoNegative || c.OverdraftLimit != nil {
+		ok = true
+		if c.OverdraftLimit != nil {
+			floor = -*c.OverdraftLimit
+		}
+	}
+	if c.MinBalance != nil && (!ok || *c.MinBalance > floor) {
+		floor, ok = *c.MinBalance, true
+	}
+	return floor, ok
+}
+
+// Check returns a *BalanceConstraintError if moving the balance from before to
+// after breaks a constraint. A balance moving back towards its limits is allowed
+// even while it is still outside them.
+func (c BalanceConstraints) Check(accountID string, before, after int64) error {
+	if after < before {
+		if floor, ok := c.Floor(); ok && after < floor {
+			return &BalanceConstraintError{AccountID: accountID, Balance: after, Limit: floor, Err: ErrInsufficientFunds}
+		}
+	}
+	if after > before && c.MaxBalance != nil && after > *c.MaxBalance {
+		return &BalanceConstraintError{AccountID: accountID, Balance: after, Limit: *c.MaxBalance, Err: ErrMaxBalanceExceeded}
+	}
+	return nil
+}
+
+// BalanceConstraintError reports a posting that would take an account's balance
+// past one of its limits. It wraps ErrInsufficientFunds or ErrMaxBalanceExceeded.
+type BalanceConstraintError struct {
+	AccountID string
+	Balance   int64 // The balance the posting would have left
+	Limit     int64
+	Err       error
+}
+
+func (e *BalanceConstraintError) Error() string {
+	return fmt.Sprintf("%s: account %s balance would be %d, limit is %d", e.Err, e.AccountID, e.Balance, e.Limit)
+}
+
+func (e *BalanceConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Balance represents the posted and pending balance of an account.
+// PendingBalance includes the posted balance plus all pending batches.
+type Balance struct {
+	AccountID      string         `json:"account_id"`
+	Currency       money.Currency `json:"currency"`
+	Balance        int64          `json:"balance"`
+	PendingBalance int64          `json:"pending_balance"`
+	TotalDebits    int64          `json:"total_debits"`
+	TotalCredits   int64          `json:"total_credits"`
+	Version        int64          `json:"version"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	NormalBalance NormalBalance      `json:"-"`
+	Constraints   BalanceConstraints `json:"-"`
+}
+
+// DateBasis selects which date of a batch historical queries run by
+type DateBasis string
+
+const (
+	DateBasisPosting DateBasis = "posting" // When the batch was posted
+	DateBasisValue   DateBasis = "value"   // The value date of the batch
+)
+
+// DateBases lists the date bases positions are kept for
+var DateBases = []DateBasis{DateBasisPosting, DateBasisValue}
+
+// IsValidDateBasis returns whether historical queries can run by the date basis
+func IsValidDateBasis(basis DateBasis) bool {
+	return basis == DateBasisPosting || basis == DateBasisValue
+}
+
+// HistoricalBalance is the posted balance of an account at a point in time
+type HistoricalBalance struct {
+	AccountID string         `json:"account_id"`
+	Currency  money.Currency `json:"currency"`
+	Balance   int64          `json:"balance"`
+	AsOf      time.Time      `json:"as_of"`
+	Basis     DateBasis      `json:"basis"`
+}
+
+// AddPending applies an entry of a newly created pending batch
+func (b *Balance) AddPending(entry *Entry) {
+	b.PendingBalance += entry.SignedAmount(b.NormalBalance)
+}
+
+// DropPending removes an entry of a voided pending batch
+func (b *Balance) DropPending(entry *Entry) {
+	b.PendingBalance -= entry.SignedAmount(b.NormalBalance)
+}
+
+// Post applies a posted entry and returns the posted balance after it.
+// Entries of batches that were pending are already in the pending balance.
+func (b *Balance) Post(entry *Entry, wasPending bool) int64 {
+	signed := entry.SignedAmount(b.NormalBalance)
+	b.Balance += signed
+	if !wasPending {
+		b.PendingBalance += signed
+	}
+	if entry.EntryType == EntryTypeDebit {
+		b.TotalDebits += entry.Amount.AmountMinor
+	} else {
+		b.TotalCredits += entry.Amount.AmountMinor
+	}
+	return b.Balance
 }
 
 // NewAccount creates a new account
@@ -125,6 +268,51 @@ func (a *Account) CanHaveEntries() bool {
 	return !a.IsPlaceholder && a.Status == AccountStatusActive
 }
 
+// ErrEntryAccount is wrapped by every EntryAccountError
+var ErrEntryAccount = errors.New("entries cannot be posted to their accounts")
+
+// EntryAccountError lists the entries of a batch that their accounts cannot take,
+// keyed by position in the batch ("entries[0]")
+type EntryAccountError struct {
+	Details map[string]string
+}
+
+func (e *EntryAccountError) Error() string {
+	return fmt.Sprintf("%s (%d entries)", ErrEntryAccount, len(e.Details))
+}
+
+func (e *EntryAccountError) Unwrap() error {
+	return ErrEntryAccount
+}
+
+// CheckEntryAccounts checks every entry of a batch against its account, given the
+// accounts found by ID. The account must belong to the batch's tenant, be able to
+// have entries and be in the entry's currency.
+func CheckEntryAccounts(batch *Batch, accounts map[string]*Account) error {
+	details := make(map[string]string)
+	for i, entry := range batch.Entries {
+		key := fmt.Sprintf("entries[%d]", i)
+
+		account, ok := accounts[entry.AccountID]
+		switch {
+		case !ok || account.TenantID != batch.TenantID:
+			details[key] = fmt.Sprintf("account %s not found", entry.AccountID)
+		case account.IsPlaceholder:
+			details[key] = fmt.Sprintf("account %s is a placeholder and cannot have entries", account.Code)
+		case !account.CanHaveEntries():
+			details[key] = fmt.Sprintf("account %s is %s", account.Code, account.Status)
+		case account.Currency != entry.Amount.Currency:
+			details[key] = fmt.Sprintf("%s: entry is in %s, account %s is in %s",
+				ErrCurrencyMismatch, entry.Amount.Currency, account.Code, account.Currency)
+		}
+	}
+
+	if len(details) > 0 {
+		return &EntryAccountError{Details: details}
+	}
+	return nil
+}
+
 // SystemAccounts returns the standard system account codes
 func SystemAccounts() []struct {
 	Code        string
@@ -141,6 +329,7 @@ func SystemAccounts() []struct {
 		{"1100", "Customer Wallet Assets", AccountTypeAsset},
 		{"1200", "Accounts Receivable", AccountTypeAsset},
 		{"1300", "Pending Settlements", AccountTypeAsset},
+		{"1400", "FX Position", AccountTypeAsset},
 
 		// Liabilities
 		{"2000", "Customer Wallet Liabilities", AccountTypeLiability},
@@ -156,6 +345,7 @@ func SystemAccounts() []struct {
 		{"4000", "Fee Revenue", AccountTypeRevenue},
 		{"4100", "Transaction Fees", AccountTypeRevenue},
 		{"4200", "Service Fees", AccountTypeRevenue},
+		{"4300", "FX Revenue", AccountTypeRevenue},
 
 		// Expenses
 		{"5000", "Operating Expenses", AccountTypeExpense},
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestBalanceConstraintsFloor(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints BalanceConstraints
+		floor       int64
+		ok          bool
+	}{
+		{name: "none"},
+		{name: "max only", constraints: BalanceConstraints{MaxBalance: int64Ptr(1000)}},
+		{name: "no negative", constraints: BalanceConstraints{NoNegative: true}, floor: 0, ok: true},
+		{name: "overdraft", constraints: BalanceConstraints{OverdraftLimit: int64Ptr(500)}, floor: -500, ok: true},
+		{name: "no negative with overdraft", constraints: BalanceConstraints{NoNegative: true, OverdraftLimit: int64Ptr(500)}, floor: -500, ok: true},
+		{name: "min", constraints: BalanceConstraints{MinBalance: int64Ptr(100)}, floor: 100, ok: true},
+		{name: "negative min", constraints: BalanceConstraints{MinBalance: int64Ptr(-300)}, floor: -300, ok: true},
+		{name: "min above no negative", constraints: BalanceConstraints{NoNegative: true, MinBalance: int64Ptr(100)}, floor: 100, ok: true},
+		{name: "min below no negative", constraints: BalanceConstraints{NoNegative: true, MinBalance: int64Ptr(-100)}, floor: 0, ok: true},
+		{name: "min above overdraft", constraints: BalanceConstraints{OverdraftLimit: int64Ptr(500), MinBalance: int64Ptr(-200)}, floor: -200, ok: true},
+		{name: "min below overdraft", constraints: BalanceConstraints{OverdraftLimit: int64Ptr(500), MinBalance: int64Ptr(-800)}, floor: -500, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			floor, ok := tt.constraints.Floor()
+			if floor != tt.floor || ok != tt.ok {
+				t.Errorf("floor %d, %v, want %d, %v", floor, ok, tt.floor, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBalanceConstraintsCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints BalanceConstraints
+		before      int64
+		after       int64
+		err         error
+		limit       int64
+	}{
+		{name: "unconstrained below zero", before: 0, after: -1000},
+
+		{name: "no negative to zero", constraints: BalanceConstraints{NoNegative: true}, before: 100, after: 0},
+		{name: "no negative below zero", constraints: BalanceConstraints{NoNegative: true}, before: 100, after: -1, err: ErrInsufficientFunds, limit: 0},
+		{name: "no negative rising while negative", constraints: BalanceConstraints{NoNegative: true}, before: -50, after: -10},
+		{name: "no negative falling while negative", constraints: BalanceConstraints{NoNegative: true}, before: -10, after: -50, err: ErrInsufficientFunds, limit: 0},
+
+		{name: "overdraft within limit", constraints: BalanceConstraints{NoNegative: true, OverdraftLimit: int64Ptr(500)}, before: 100, after: -500},
+		{name: "overdraft past limit", constraints: BalanceConstraints{NoNegative: true, OverdraftLimit: int64Ptr(500)}, before: 100, after: -501, err: ErrInsufficientFunds, limit: -500},
+		{name: "overdraft without no negative", constraints: BalanceConstraints{OverdraftLimit: int64Ptr(0)}, before: 100, after: -1, err: ErrInsufficientFunds, limit: 0},
+
+		{name: "min reached", constraints: BalanceConstraints{MinBalance: int64Ptr(100)}, before: 500, after: 100},
+		{name: "min passed", constraints: BalanceConstraints{MinBalance: int64Ptr(100)}, before: 500, after: 99, err: ErrInsufficientFunds, limit: 100},
+		{name: "min rising while below", constraints: BalanceConstraints{MinBalance: int64Ptr(100)}, before: 10, after: 50},
+
+		{name: "max reached", constraints: BalanceConstraints{MaxBalance: int64Ptr(1000)}, before: 500, after: 1000},
+		{name: "max passed", constraints: BalanceConstraints{MaxBalance: int64Ptr(1000)}, before: 500, after: 1001, err: ErrMaxBalanceExceeded, limit: 1000},
+		{name: "max falling while above", constraints: BalanceConstraints{MaxBalance: int64Ptr(1000)}, before: 1500, after: 1200},
+		{name: "max rising while above", constraints: BalanceConstraints{MaxBalance: int64Ptr(1000)}, before: 1200, after: 1500, err: ErrMaxBalanceExceeded, limit: 1000},
+
+		{name: "min and max within", constraints: BalanceConstraints{MinBalance: int64Ptr(100), MaxBalance: int64Ptr(1000)}, before: 500, after: 900},
+		{name: "unchanged outside limits", constraints: BalanceConstraints{MinBalance: int64Ptr(100), MaxBalance: int64Ptr(1000)}, before: 50, after: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constraints.Check("acc1", tt.before, tt.after)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			var ce *BalanceConstraintError
+			if !errors.As(err, &ce) || !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want a *BalanceConstraintError wrapping %v", err, tt.err)
+			}
+			if ce.AccountID != "acc1" || ce.Balance != tt.after || ce.Limit != tt.limit {
+				t.Errorf("error %+v, want balance %d and limit %d on acc1", ce, tt.after, tt.limit)
+			}
+		})
+	}
+}
+
+func TestBalanceConstraintsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints BalanceConstraints
+		valid       bool
+	}{
+		{name: "none", valid: true},
+		{name: "min below max", constraints: BalanceConstraints{MinBalance: int64Ptr(100), MaxBalance: int64Ptr(1000)}, valid: true},
+		{name: "min equal to max", constraints: BalanceConstraints{MinBalance: int64Ptr(1000), MaxBalance: int64Ptr(1000)}, valid: true},
+		{name: "overdraft", constraints: BalanceConstraints{OverdraftLimit: int64Ptr(500)}, valid: true},
+		{name: "negative overdraft", constraints: BalanceConstraints{OverdraftLimit: int64Ptr(-1)}},
+		{name: "min above max", constraints: BalanceConstraints{MinBalance: int64Ptr(1001), MaxBalance: int64Ptr(1000)}},
+		{name: "no negative with negative max", constraints: BalanceConstraints{NoNegative: true, MaxBalance: int64Ptr(-1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constraints.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidConstraints) {
+				t.Errorf("error %v, want %v", err, ErrInvalidConstraints)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// Audit checks
+const (
+	AuditCheckBatchTotals    = "batch_totals"        // Entries sum to their batch's totals
+	AuditCheckEntryCount     = "entry_count"         // A batch has as many entries as it says
+	AuditCheckRunningBalance = "running_balance"     // balance_after replays from the account's entries
+	AuditCheckAccountBalance = "account_balance"     // Stored account balances match the entries
+	AuditCheckEquation       = "accounting_equation" // Assets + expenses = liabilities + equity + revenue
+	AuditCheckEntryStatus    = "entry_status"        // Entries count only in posted batches
+)
+
+// Audit run statuses
+const (
+	AuditStatusClean         = "clean"
+	AuditStatusDiscrepancies = "discrepancies"
+)
+
+// AuditRun is one run of the integrity audit over a tenant's ledger
+type AuditRun struct {
+	ID           string          `json:"id"`
+	TenantID     string          `json:"tenant_id"`
+	Status       string          `json:"status"`
+	FindingCount int             `json:"finding_count"`
+	StartedAt    time.Time       `json:"started_at"`
+	FinishedAt   time.Time       `json:"finished_at"`
+	Findings     []*AuditFinding `json:"findings,omitempty"`
+}
+
+// AuditFinding is one discrepancy an audit found
+type AuditFinding struct {
+	ID          string    `json:"id"`
+	RunID       string    `json:"run_id"`
+	TenantID    string    `json:"tenant_id"`
+	Check       string    `json:"check"`
+	SubjectType string    `json:"subject_type"` // batch, entry, account or currency
+	SubjectID   string    `json:"subject_id"`
+	Expected    string    `json:"expected"`
+	Actual      string    `json:"actual"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewAuditFinding creates a finding comparing an expected and actual amount
+func NewAuditFinding(check, subjectType, subjectID string, expected, actual int64, message string) *AuditFinding {
+	return &AuditFinding{
+		Check:       check,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Expected:    strconv.FormatInt(expected, 10),
+		Actual:      strconv.FormatInt(actual, 10),
+		Message:     message,
+	}
+}
+
+// Finish records the findings of the run and when it finished
+func (r *AuditRun) Finish(findings []*AuditFinding) {
+	r.FinishedAt = time.Now().UTC()
+	r.Findings = findings
+	r.FindingCount = len(findings)
+	r.Status = AuditStatusClean
+	if len(findings) > 0 {
+		r.Status = AuditStatusDiscrepancies
+	}
+	for _, f := range findings {
+		f.RunID = r.ID
+		f.TenantID = r.TenantID
+		f.CreatedAt = r.FinishedAt
+	}
+}
+
+// Checks returns the distinct checks the run's findings failed
+func (r *AuditRun) Checks() []string {
+	seen := make(map[string]bool)
+	var checks []string
+	for _, f := range r.Findings {
+		if !seen[f.Check] {
+			seen[f.Check] = true
+			checks = append(checks, f.Check)
+		}
+	}
+	sort.Strings(checks)
+	return checks
+}
+
+// CheckAccountingEquation checks, per currency, that assets plus expenses equal
+// liabilities plus equity plus revenue. net holds the net debit balance (debits
+// less credits) of each account type in each currency.
+func CheckAccountingEquation(net map[money.Currency]map[AccountType]int64) []*AuditFinding {
+	currencies := make([]string, 0, len(net))
+	for c := range net {
+		currencies = append(currencies, string(c))
+	}
+	sort.Strings(currencies)
+
+	var findings []*AuditFinding
+	for _, c := range currencies {
+		byType := net[money.Currency(c)]
+		debitSide := byType[AccountTypeAsset] + byType[AccountTypeExpense]
+		creditSide := -(byType[AccountTypeLiability] + byType[AccountTypeEquity] + byType[AccountTypeRevenue])
+		if debitSide != creditSide {
+			findings = append(findings, NewAuditFinding(AuditCheckEquation, "currency", c, debitSide, creditSide,
+				fmt.Sprintf("assets + expenses (%d) do not equal liabilities + equity + revenue (%d)", debitSide, creditSide)))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"finplatform/internal/common/money"
+)
+
+func TestCheckAccountingEquation(t *testing.T) {
+	tests := []struct {
+		name     string
+		net      map[money.Currency]map[AccountType]int64
+		findings []string // Currencies with a finding, in order
+	}{
+		{
+			name: "empty ledger",
+			net:  map[money.Currency]map[AccountType]int64{},
+		},
+		{
+			name: "balanced",
+			net: map[money.Currency]map[AccountType]int64{
+				money.USD: {
+					AccountTypeAsset:     1000,
+					AccountTypeExpense:   200,
+					AccountTypeLiability: -700,
+					AccountTypeEquity:    -100,
+					AccountTypeRevenue:   -400,
+				},
+			},
+		},
+		{
+			name: "contra balances",
+			net: map[money.Currency]map[AccountType]int64{
+				money.USD: {
+					AccountTypeAsset:     -50,
+					AccountTypeLiability: 80,
+					AccountTypeEquity:    -30,
+				},
+			},
+		},
+		{
+			name: "unbalanced",
+			net: map[money.Currency]map[AccountType]int64{
+				money.USD: {
+					AccountTypeAsset:     1000,
+					AccountTypeLiability: -999,
+				},
+			},
+			findings: []string{"USD"},
+		},
+		{
+			name: "one side missing",
+			net: map[money.Currency]map[AccountType]int64{
+				money.EUR: {AccountTypeRevenue: -10},
+			},
+			findings: []string{"EUR"},
+		},
+		{
+			name: "checked per currency",
+			net: map[money.Currency]map[AccountType]int64{
+				money.USD: {AccountTypeAsset: 100, AccountTypeEquity: -100},
+				money.GBP: {AccountTypeAsset: 100, AccountTypeEquity: -90},
+				money.EUR: {AccountTypeExpense: 5},
+			},
+			findings: []string{"EUR", "GBP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := CheckAccountingEquation(tt.net)
+
+			var currencies []string
+			for _, f := range findings {
+				if f.Check != AuditCheckEquation || f.SubjectType != "currency" {
+					t.Errorf("finding %+v is not an accounting equation finding on a currency", f)
+				}
+				currencies = append(currencies, f.SubjectID)
+			}
+			if !reflect.DeepEqual(currencies, tt.findings) {
+				t.Errorf("findings on %v, want %v", currencies, tt.findings)
+			}
+		})
+	}
+}
+
+func TestCheckAccountingEquationAmounts(t *testing.T) {
+	findings := CheckAccountingEquation(map[money.Currency]map[AccountType]int64{
+		money.USD: {
+			AccountTypeAsset:     1000,
+			AccountTypeExpense:   50,
+			AccountTypeLiability: -600,
+			AccountTypeRevenue:   -400,
+		},
+	})
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	if findings[0].Expected != "1050" || findings[0].Actual != "1000" {
+		t.Errorf("expected %s and actual %s, want 1050 and 1000", findings[0].Expected, findings[0].Actual)
+	}
+}
+
+func TestAuditRunFinish(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []*AuditFinding
+		status   string
+	}{
+		{name: "no findings", status: AuditStatusClean},
+		{name: "empty findings", findings: []*AuditFinding{}, status: AuditStatusClean},
+		{
+			name: "findings",
+			findings: []*AuditFinding{
+				NewAuditFinding(AuditCheckBatchTotals, "batch", "b1", 100, 90, "totals differ"),
+				NewAuditFinding(AuditCheckEntryCount, "batch", "b2", 2, 3, "entry count differs"),
+			},
+			status: AuditStatusDiscrepancies,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &AuditRun{ID: "run1", TenantID: "tenant1"}
+			run.Finish(tt.findings)
+
+			if run.Status != tt.status {
+				t.Errorf("status %q, want %q", run.Status, tt.status)
+			}
+			if run.FindingCount != len(tt.findings) {
+				t.Errorf("finding count %d, want %d", run.FindingCount, len(tt.findings))
+			}
+			if run.FinishedAt.IsZero() {
+				t.Error("finished at not set")
+			}
+			for _, f := range run.Findings {
+				if f.RunID != "run1" || f.TenantID != "tenant1" || !f.CreatedAt.Equal(run.FinishedAt) {
+					t.Errorf("finding %+v not stamped with the run", f)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditRunChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []*AuditFinding
+		checks   []string
+	}{
+		{name: "no findings"},
+		{
+			name: "distinct and sorted",
+			findings: []*AuditFinding{
+				{Check: AuditCheckRunningBalance},
+				{Check: AuditCheckBatchTotals},
+				{Check: AuditCheckRunningBalance},
+				{Check: AuditCheckAccountBalance},
+			},
+			checks: []string{AuditCheckAccountBalance, AuditCheckBatchTotals, AuditCheckRunningBalance},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &AuditRun{Findings: tt.findings}
+			if checks := run.Checks(); !reflect.DeepEqual(checks, tt.checks) {
+				t.Errorf("checks %v, want %v", checks, tt.checks)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "errors"
+
+// Bulk posting modes
+const (
+	BulkModeAtomic     = "atomic"      // Post every batch or none of them
+	BulkModeBestEffort = "best_effort" // Post every batch that can be posted
+)
+
+// Bulk item statuses
+const (
+	BulkItemPosted    = "posted"
+	BulkItemExisting  = "existing"   // An earlier request with the same idempotency key created the batch
+	BulkItemFailed    = "failed"     // The batch was rejected; see the item's error
+	BulkItemNotPosted = "not_posted" // The batch was valid but an atomic posting was rejected
+)
+
+// ErrBulkRejected is returned when an atomic bulk posting is rejected because at
+// least one of its batches failed
+var ErrBulkRejected = errors.New("bulk posting rejected")
+
+// BulkItem is one batch of a bulk posting and what became of it
+type BulkItem struct {
+	Index  int    // Position of the batch in the request
+	Batch  *Batch // The batch built from the request, or the existing batch
+	Status string
+	Err    error // Why the batch failed
+}
+
+// Pending returns whether the item has not been posted or rejected yet
+func (i *BulkItem) Pending() bool {
+	return i.Status == ""
+}
+
+// Fail marks the item failed with err
+func (i *BulkItem) Fail(err error) {
+	i.Status = BulkItemFailed
+	i.Err = err
+}
+
+// RejectBulk marks every pending item not posted, after an atomic bulk posting
+// was rejected
+func RejectBulk(items []*BulkItem) {
+	for _, item := range items {
+		if item.Pending() {
+			item.Status = BulkItemNotPosted
+		}
+	}
+}
+
+// BulkFailed returns whether any item failed
+func BulkFailed(items []*BulkItem) bool {
+	for _, item := range items {
+		if item.Status == BulkItemFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Reasons a hash chain check fails
+const (
+	ChainBreakMissingBatch = "missing_batch" // A posting sequence is missing
+	ChainBreakContent      = "content_hash"  // The batch or its entries were changed
+	ChainBreakLink         = "chain_hash"    // The stored chain hash does not follow from the previous one
+	ChainBreakHead         = "head"          // The chain ends before the tenant's last posting
+	ChainBreakCheckpoint   = "checkpoint"    // A checkpoint does not match the chain
+	ChainBreakSignature    = "signature"     // A checkpoint signature is not valid
+)
+
+// canonicalBatch is the content of a posted batch that its hash covers. Fields
+// that change after posting (status, reversal totals and running balances) are
+// left out. Metadata is marshalled with its keys sorted.
+type canonicalBatch struct {
+	ID           string            `json:"id"`
+	TenantID     string            `json:"tenant_id"`
+	Sequence     int64             `json:"sequence"`
+	Reference    string            `json:"reference"`
+	Description  string            `json:"description"`
+	SourceType   SourceType        `json:"source_type"`
+	SourceID     string            `json:"source_id"`
+	Currency     string            `json:"currency"`
+	TotalDebits  int64             `json:"total_debits"`
+	TotalCredits int64             `json:"total_credits"`
+	EntryCount   int               `json:"entry_count"`
+	Effective    string            `json:"effective_date"`
+	PostedAt     string            `json:"posted_at"`
+	PostedBy     string            `json:"posted_by"`
+	ReversalOfID string            `json:"reversal_of_batch_id"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Entries      []canonicalEntry  `json:"entries"`
+}
+
+type canonicalEntry struct {
+	ID              string    `json:"id"`
+	AccountID       string    `json:"account_id"`
+	EntryType       EntryType `json:"entry_type"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	Sequence        int       `json:"sequence"`
+	Effective       string    `json:"effective_date"`
+	Description     string    `json:"description"`
+	ReversesEntryID string    `json:"reverses_entry_id"`
+}
+
+// ComputeContentHash returns the SHA-256 of the batch's canonical posted content,
+// including its entries and posting sequence. Timestamps are taken at the
+// microsecond precision the database stores.
+func (batch *Batch) ComputeContentHash() string {
+	c := canonicalBatch{
+		ID:           batch.ID,
+		TenantID:     batch.TenantID,
+		Reference:    batch.Reference,
+		Description:  batch.Description,
+		SourceType:   batch.SourceType,
+		SourceID:     batch.SourceID,
+		Currency:     string(batch.TotalDebits.Currency),
+		TotalDebits:  batch.TotalDebits.AmountMinor,
+		TotalCredits: batch.TotalCredits.AmountMinor,
+		EntryCount:   batch.EntryCount,
+		Effective:    batch.EffectiveDate.UTC().Format("2006-01-02"),
+		Metadata:     batch.Metadata,
+		Entries:      make([]canonicalEntry, len(batch.Entries)),
+	}
+	if batch.PostingSequence != nil {
+		c.Sequence = *batch.PostingSequence
+	}
+	if batch.PostedAt != nil {
+		c.PostedAt = batch.PostedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+	}
+	if batch.PostedBy != nil {
+		c.PostedBy = *batch.PostedBy
+	}
+	if batch.ReversalOfID != nil {
+		c.ReversalOfID = *batch.ReversalOfID
+	}
+
+	for i, e := range batch.Entries {
+		c.Entries[i] = canonicalEntry{
+			ID:          e.ID,
+			AccountID:   e.AccountID,
+			EntryType:   e.EntryType,
+			Amount:      e.Amount.AmountMinor,
+			Currency:    string(e.Amount.Currency),
+			Sequence:    e.Sequence,
+			Effective:   e.EffectiveDate.UTC().Format("2006-01-02"),
+			Description: e.Description,
+		}
+		if e.ReversesEntryID != nil {
+			c.Entries[i].ReversesEntryID = *e.ReversesEntryID
+		}
+	}
+	sort.Slice(c.Entries, func(i, j int) bool { return c.Entries[i].Sequence < c.Entries[j].Sequence })
+
+	// Marshalling a struct of plain fields cannot fail
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainHash links a batch's content hash to the chain hash of the batch posted
+// before it. The first batch of a tenant's chain follows the empty hash.
+func ChainHash(previous, content string) string {
+	sum := sha256.Sum256([]byte(previous + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckChainLink recomputes the batch's hashes and checks it is the batch with
+// posting sequence seq following the chain hash previous. It returns nil if so.
+func (batch *Batch) CheckChainLink(seq int64, previous string) *ChainBreak {
+	if batch.PostingSequence == nil || *batch.PostingSequence != seq {
+		return &ChainBreak{Sequence: seq, Reason: ChainBreakMissingBatch}
+	}
+	if content := batch.ComputeContentHash(); content != batch.ContentHash {
+		return &ChainBreak{Sequence: seq, BatchID: batch.ID, Reason: ChainBreakContent, Expected: content, Actual: batch.ContentHash}
+	}
+	if link := ChainHash(previous, batch.ContentHash); link != batch.ChainHash {
+		return &ChainBreak{Sequence: seq, BatchID: batch.ID, Reason: ChainBreakLink, Expected: link, Actual: batch.ChainHash}
+	}
+	return nil
+}
+
+// ChainHead is the last link of a tenant's hash chain
+type ChainHead struct {
+	TenantID   string `json:"tenant_id"`
+	ChainStart int64  `json:"chain_start"` // First posting sequence in the chain
+	Sequence   int64  `json:"sequence"`    // Last posting sequence, 0 if none
+	Hash       string `json:"hash"`        // Chain hash of the last batch
+}
+
+// ChainCheckpoint is a signed statement of a tenant's chain head at a point in
+// time. Anyone holding the public key can check it against an export of the ledger.
+type ChainCheckpoint struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Sequence  int64     `json:"sequence"`
+	ChainHash string    `json:"chain_hash"`
+	PublicKey string    `json:"public_key"` // Hex ed25519 public key
+	Signature string    `json:"signature"`  // Base64 ed25519 signature of SigningPayload
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewChainCheckpoint creates a checkpoint of a chain head signed with key
+func NewChainCheckpoint(id string, head *ChainHead, key ed25519.PrivateKey) *ChainCheckpoint {
+	c := &ChainCheckpoint{
+		ID:        id,
+		TenantID:  head.TenantID,
+		Sequence:  head.Sequence,
+		ChainHash: head.Hash,
+		PublicKey: hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, c.SigningPayload()))
+	return c
+}
+
+// SigningPayload returns the bytes the checkpoint signature covers
+func (c *ChainCheckpoint) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("ledger-checkpoint:v1\n%s\n%d\n%s\n%s",
+		c.TenantID, c.Sequence, c.ChainHash, c.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// VerifySignature checks the checkpoint was signed by the holder of key
+func (c *ChainCheckpoint) VerifySignature(key ed25519.PublicKey) bool {
+	if c.PublicKey != hex.EncodeToString(key) {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, c.SigningPayload(), sig)
+}
+
+// CheckpointExport is the JSON file of a tenant's checkpoints given to auditors
+type CheckpointExport struct {
+	TenantID    string             `json:"tenant_id"`
+	ExportedAt  time.Time          `json:"exported_at"`
+	Head        *ChainHead         `json:"head"`
+	Checkpoints []*ChainCheckpoint `json:"checkpoints"`
+}
+
+// ChainBreak describes the first point where a hash chain fails to verify
+type ChainBreak struct {
+	Sequence int64  `json:"sequence"`
+	BatchID  string `json:"batch_id,omitempty"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// ChainVerification is the result of walking a tenant's hash chain
+type ChainVerification struct {
+	TenantID    string      `json:"tenant_id"`
+	ChainStart  int64       `json:"chain_start"`
+	Checked     int64       `json:"batches_checked"`
+	Checkpoints int         `json:"checkpoints_checked"`
+	Unverified  int         `json:"checkpoints_unverified"` // Not checked, as no checkpoint key is configured
+	Head        *ChainHead  `json:"head"`
+	Valid       bool        `json:"valid"`
+	Break       *ChainBreak `json:"break,omitempty"`
+	VerifiedAt  time.Time   `json:"verified_at"`
+}
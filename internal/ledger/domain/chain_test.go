@@ -0,0 +1,246 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"regexp"
+	"testing"
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// chainTestBatch returns a posted batch of two entries with posting sequence seq
+func chainTestBatch(seq int64) *Batch {
+	postedAt := time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.UTC)
+	value := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+	postedBy := "user1"
+	return &Batch{
+		ID:              "batch1",
+		TenantID:        "tenant1",
+		Reference:       "ref-1",
+		Description:     "Wallet funding",
+		SourceType:      SourceTypeDeposit,
+		SourceID:        "src-1",
+		TotalDebits:     money.New(2500, money.EUR),
+		TotalCredits:    money.New(2500, money.EUR),
+		EntryCount:      2,
+		Status:          BatchStatusPosted,
+		EffectiveDate:   value,
+		PostingSequence: &seq,
+		PostedAt:        &postedAt,
+		PostedBy:        &postedBy,
+		Entries: []*Entry{
+			{ID: "entry1", BatchID: "batch1", AccountID: "cash", EntryType: EntryTypeDebit, Amount: money.New(2500, money.EUR), Sequence: 1, EffectiveDate: value},
+			{ID: "entry2", BatchID: "batch1", AccountID: "wallet", EntryType: EntryTypeCredit, Amount: money.New(2500, money.EUR), Sequence: 2, EffectiveDate: value, Description: "funding"},
+		},
+	}
+}
+
+// chainTestLink hashes a batch and links it to previous
+func chainTestLink(batch *Batch, previous string) *Batch {
+	batch.ContentHash = batch.ComputeContentHash()
+	batch.ChainHash = ChainHash(previous, batch.ContentHash)
+	return batch
+}
+
+func TestComputeContentHashFormat(t *testing.T) {
+	hash := chainTestBatch(1).ComputeContentHash()
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(hash) {
+		t.Errorf("hash %q is not hex SHA-256", hash)
+	}
+	if again := chainTestBatch(1).ComputeContentHash(); again != hash {
+		t.Errorf("hash of the same batch changed from %s to %s", hash, again)
+	}
+
+	// Stored chains are verified against this canonical form, so it must not
+	// change
+	if want := "b26c4857a411d964da1f60ea6d422c3b2b9872bc75793b36dac79ed5f50c4445"; hash != want {
+		t.Errorf("hash %s, want %s", hash, want)
+	}
+}
+
+func TestComputeContentHashCovers(t *testing.T) {
+	other := "other"
+	tests := []struct {
+		name    string
+		mutate  func(b *Batch)
+		changes bool
+	}{
+		{name: "posting sequence", mutate: func(b *Batch) { seq := int64(2); b.PostingSequence = &seq }, changes: true},
+		{name: "no posting sequence", mutate: func(b *Batch) { b.PostingSequence = nil }, changes: true},
+		{name: "reference", mutate: func(b *Batch) { b.Reference = other }, changes: true},
+		{name: "description", mutate: func(b *Batch) { b.Description = other }, changes: true},
+		{name: "source", mutate: func(b *Batch) { b.SourceID = other }, changes: true},
+		{name: "total", mutate: func(b *Batch) { b.TotalDebits.AmountMinor++ }, changes: true},
+		{name: "value date", mutate: func(b *Batch) { b.EffectiveDate = b.EffectiveDate.AddDate(0, 0, 1) }, changes: true},
+		{name: "posted at", mutate: func(b *Batch) { t := b.PostedAt.Add(time.Microsecond); b.PostedAt = &t }, changes: true},
+		{name: "posted by", mutate: func(b *Batch) { b.PostedBy = &other }, changes: true},
+		{name: "reversal of", mutate: func(b *Batch) { b.ReversalOfID = &other }, changes: true},
+		{name: "entry account", mutate: func(b *Batch) { b.Entries[0].AccountID = other }, changes: true},
+		{name: "entry type", mutate: func(b *Batch) { b.Entries[0].EntryType = EntryTypeCredit }, changes: true},
+		{name: "entry amount", mutate: func(b *Batch) { b.Entries[1].Amount.AmountMinor-- }, changes: true},
+		{name: "entry currency", mutate: func(b *Batch) { b.Entries[1].Amount.Currency = money.USD }, changes: true},
+		{name: "entry description", mutate: func(b *Batch) { b.Entries[1].Description = other }, changes: true},
+		{name: "entry reverses", mutate: func(b *Batch) { b.Entries[1].ReversesEntryID = &other }, changes: true},
+		{name: "entry removed", mutate: func(b *Batch) { b.Entries = b.Entries[:1] }, changes: true},
+		{name: "metadata", mutate: func(b *Batch) { b.Metadata = map[string]string{"k": "v"} }, changes: true},
+
+		// Left out: they change after posting or are not stored exactly
+		{name: "status", mutate: func(b *Batch) { b.Status = BatchStatusReversed }},
+		{name: "empty metadata", mutate: func(b *Batch) { b.Metadata = map[string]string{} }},
+		{name: "stored hashes", mutate: func(b *Batch) { b.ContentHash, b.ChainHash = other, other }},
+		{name: "entry running balance", mutate: func(b *Batch) { v := int64(7); b.Entries[0].BalanceAfter = &v }},
+		{name: "entry reversed amount", mutate: func(b *Batch) { b.Entries[0].ReversedAmount = 100 }},
+		{name: "entry order", mutate: func(b *Batch) { b.Entries[0], b.Entries[1] = b.Entries[1], b.Entries[0] }},
+		{name: "posted at below microseconds", mutate: func(b *Batch) { t := b.PostedAt.Add(999); b.PostedAt = &t }},
+		{name: "posted at time zone", mutate: func(b *Batch) { t := b.PostedAt.In(time.FixedZone("CET", 3600)); b.PostedAt = &t }},
+	}
+
+	want := chainTestBatch(1).ComputeContentHash()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := chainTestBatch(1)
+			tt.mutate(batch)
+			if changed := batch.ComputeContentHash() != want; changed != tt.changes {
+				t.Errorf("hash changed: %v, want %v", changed, tt.changes)
+			}
+		})
+	}
+}
+
+func TestChainHash(t *testing.T) {
+	// SHA-256 of the empty string
+	if got := ChainHash("", ""); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("ChainHash of empty hashes = %s", got)
+	}
+	if ChainHash("a", "b") == ChainHash("b", "a") {
+		t.Error("ChainHash does not depend on the order of its hashes")
+	}
+}
+
+func TestCheckChainLink(t *testing.T) {
+	const previous = "5d41402abc4b2a76b9719d911017c592"
+
+	tests := []struct {
+		name     string
+		batch    func() *Batch
+		seq      int64
+		previous string
+		reason   string // Empty if the link holds
+	}{
+		{
+			name:     "valid",
+			batch:    func() *Batch { return chainTestLink(chainTestBatch(5), previous) },
+			seq:      5,
+			previous: previous,
+		},
+		{
+			name:  "first in chain",
+			batch: func() *Batch { return chainTestLink(chainTestBatch(1), "") },
+			seq:   1,
+		},
+		{
+			name: "no posting sequence",
+			batch: func() *Batch {
+				b := chainTestLink(chainTestBatch(5), previous)
+				b.PostingSequence = nil
+				return b
+			},
+			seq:      5,
+			previous: previous,
+			reason:   ChainBreakMissingBatch,
+		},
+		{
+			name:     "other posting sequence",
+			batch:    func() *Batch { return chainTestLink(chainTestBatch(6), previous) },
+			seq:      5,
+			previous: previous,
+			reason:   ChainBreakMissingBatch,
+		},
+		{
+			name: "entry changed",
+			batch: func() *Batch {
+				b := chainTestLink(chainTestBatch(5), previous)
+				b.Entries[0].Amount.AmountMinor = 1
+				return b
+			},
+			seq:      5,
+			previous: previous,
+			reason:   ChainBreakContent,
+		},
+		{
+			name: "content hash replaced",
+			batch: func() *Batch {
+				b := chainTestLink(chainTestBatch(5), previous)
+				b.Entries[0].Amount.AmountMinor = 1
+				return chainTestLink(b, previous)
+			},
+			seq:      5,
+			previous: "0000",
+			reason:   ChainBreakLink,
+		},
+		{
+			name:     "other previous hash",
+			batch:    func() *Batch { return chainTestLink(chainTestBatch(5), previous) },
+			seq:      5,
+			previous: "",
+			reason:   ChainBreakLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := tt.batch()
+			brk := batch.CheckChainLink(tt.seq, tt.previous)
+			if tt.reason == "" {
+				if brk != nil {
+					t.Fatalf("unexpected break %+v", brk)
+				}
+				return
+			}
+			if brk == nil {
+				t.Fatalf("no break, want %s", tt.reason)
+			}
+			if brk.Reason != tt.reason || brk.Sequence != tt.seq {
+				t.Errorf("break %+v, want %s at %d", brk, tt.reason, tt.seq)
+			}
+			if tt.reason != ChainBreakMissingBatch && (brk.BatchID != batch.ID || brk.Expected == brk.Actual) {
+				t.Errorf("break %+v does not name the batch and differing hashes", brk)
+			}
+		})
+	}
+}
+
+func TestChainCheckpointSignature(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	key := ed25519.NewKeyFromSeed(seed)
+	seed[0] = 1
+	otherKey := ed25519.NewKeyFromSeed(seed)
+
+	head := &ChainHead{TenantID: "tenant1", Sequence: 42, Hash: "abc123"}
+
+	tests := []struct {
+		name   string
+		mutate func(c *ChainCheckpoint)
+		key    ed25519.PublicKey
+		valid  bool
+	}{
+		{name: "valid", mutate: func(c *ChainCheckpoint) {}, key: key.Public().(ed25519.PublicKey), valid: true},
+		{name: "other key", mutate: func(c *ChainCheckpoint) {}, key: otherKey.Public().(ed25519.PublicKey)},
+		{name: "sequence changed", mutate: func(c *ChainCheckpoint) { c.Sequence++ }, key: key.Public().(ed25519.PublicKey)},
+		{name: "hash changed", mutate: func(c *ChainCheckpoint) { c.ChainHash = "abc124" }, key: key.Public().(ed25519.PublicKey)},
+		{name: "tenant changed", mutate: func(c *ChainCheckpoint) { c.TenantID = "tenant2" }, key: key.Public().(ed25519.PublicKey)},
+		{name: "time changed", mutate: func(c *ChainCheckpoint) { c.CreatedAt = c.CreatedAt.Add(time.Second) }, key: key.Public().(ed25519.PublicKey)},
+		{name: "signature not base64", mutate: func(c *ChainCheckpoint) { c.Signature = "!" }, key: key.Public().(ed25519.PublicKey)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChainCheckpoint("cp1", head, key)
+			tt.mutate(c)
+			if valid := c.VerifySignature(tt.key); valid != tt.valid {
+				t.Errorf("signature valid: %v, want %v", valid, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+
+	"finplatform/internal/common/money"
+)
+
+// Chart of accounts errors
+var (
+	ErrInvalidChart  = errors.New("invalid chart of accounts")
+	ErrChartConflict = errors.New("chart of accounts conflicts with the existing accounts")
+)
+
+// Chart is a chart of accounts, as imported into or exported from a tenant's
+// ledger. Parents are named by code and may be existing accounts of the tenant.
+type Chart struct {
+	Name        string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Currency    money.Currency `json:"currency,omitempty" yaml:"currency,omitempty"` // Of the accounts that name none
+	Accounts    []ChartAccount `json:"accounts" yaml:"accounts"`
+}
+
+// ChartAccount is one account of a chart of accounts
+type ChartAccount struct {
+	Code          string            `json:"code" yaml:"code"`
+	Name          string            `json:"name" yaml:"name"`
+	Description   string            `json:"description,omitempty" yaml:"description,omitempty"`
+	AccountType   AccountType       `json:"account_type" yaml:"account_type"`
+	Currency      money.Currency    `json:"currency,omitempty" yaml:"currency,omitempty"`
+	ParentCode    string            `json:"parent_code,omitempty" yaml:"parent_code,omitempty"`
+	IsPlaceholder bool              `json:"is_placeholder,omitempty" yaml:"is_placeholder,omitempty"`
+	IsSystem      bool              `json:"is_system,omitempty" yaml:"is_system,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// ChartDiff is what importing a chart of accounts changes. Accounts are only
+// ever created or have their name, description and metadata updated; existing
+// accounts the chart leaves out are kept and listed as unlisted.
+type ChartDiff struct {
+	Create    []ChartAccount  `json:"create"`
+	Update    []ChartChange   `json:"update"`
+	Unchanged int             `json:"unchanged"`
+	Unlisted  []string        `json:"unlisted"`
+	Conflicts []ChartConflict `json:"conflicts"`
+}
+
+// ChartChange is an existing account a chart import updates
+type ChartChange struct {
+	Code   string   `json:"code"`
+	Fields []string `json:"fields"` // name, description and/or metadata
+}
+
+// ChartConflict is a chart account that cannot be imported over the existing accounts
+type ChartConflict struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// CurrencyAccountCode returns the code of the copy of an account kept in another
+// currency, e.g. 2000-EUR
+func CurrencyAccountCode(code string, currency money.Currency) string {
+	return code + "-" + string(currency)
+}
+
+// Validate fills in each account's currency from the chart's and checks the
+// accounts: codes are unique, parents within the chart share their children's
+// currency and no account is its own ancestor
+func (c *Chart) Validate() error {
+	byCode := make(map[string]*ChartAccount, len(c.Accounts))
+	for i := range c.Accounts {
+		a := &c.Accounts[i]
+		if a.Currency == "" {
+			a.Currency = c.Currency
+		}
+
+		switch {
+		case a.Code == "" || len(a.Code) > 50:
+			return fmt.Errorf("%w: account %d code must be 1 to 50 characters", ErrInvalidChart, i)
+		case a.Name == "" || len(a.Name) > 255:
+			return fmt.Errorf("%w: account %s name must be 1 to 255 characters", ErrInvalidChart, a.Code)
+		case len(a.Currency) != 3:
+			return fmt.Errorf("%w: account %s needs a three letter currency", ErrInvalidChart, a.Code)
+		case a.ParentCode == a.Code:
+			return fmt.Errorf("%w: account %s is its own parent", ErrInvalidChart, a.Code)
+		}
+		switch a.AccountType {
+		case AccountTypeAsset, AccountTypeLiability, AccountTypeEquity, AccountTypeRevenue, AccountTypeExpense:
+		default:
+			return fmt.Errorf("%w: account %s has unknown type %q", ErrInvalidChart, a.Code, a.AccountType)
+		}
+		if err := ValidateMetadata(a.Metadata); err != nil {
+			return fmt.Errorf("%w: account %s: %w", ErrInvalidChart, a.Code, err)
+		}
+		if _, ok := byCode[a.Code]; ok {
+			return fmt.Errorf("%w: account %s is listed twice", ErrInvalidChart, a.Code)
+		}
+		byCode[a.Code] = a
+	}
+
+	for _, a := range c.Accounts {
+		seen := map[string]bool{a.Code: true}
+		for parent := byCode[a.ParentCode]; parent != nil; parent = byCode[parent.ParentCode] {
+			if seen[parent.Code] {
+				return fmt.Errorf("%w: account %s is its own ancestor", ErrInvalidChart, a.Code)
+			}
+			seen[parent.Code] = true
+		}
+		if parent := byCode[a.ParentCode]; parent != nil && parent.Currency != a.Currency {
+			return fmt.Errorf("%w: account %s must have the currency of its parent %s", ErrInvalidChart, a.Code, parent.Code)
+		}
+	}
+
+	return nil
+}
+
+// Sorted returns the chart's accounts with every parent before its children and
+// otherwise in code order
+func (c *Chart) Sorted() []ChartAccount {
+	byCode := make(map[string]ChartAccount, len(c.Accounts))
+	for _, a := range c.Accounts {
+		byCode[a.Code] = a
+	}
+	depth := func(a ChartAccount) int {
+		d := 0
+		for parent, ok := byCode[a.ParentCode]; ok && d <= len(byCode); parent, ok = byCode[parent.ParentCode] {
+			d++
+		}
+		return d
+	}
+
+	sorted := make([]ChartAccount, len(c.Accounts))
+	copy(sorted, c.Accounts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		di, dj := depth(sorted[i]), depth(sorted[j])
+		if di != dj {
+			return di < dj
+		}
+		return sorted[i].Code < sorted[j].Code
+	})
+	return sorted
+}
+
+// ChartFromAccounts exports accounts as a chart of accounts, parents first
+func ChartFromAccounts(accounts []*Account) *Chart {
+	codes := make(map[string]string, len(accounts))
+	for _, a := range accounts {
+		codes[a.ID] = a.Code
+	}
+
+	chart := &Chart{Accounts: make([]ChartAccount, 0, len(accounts))}
+	for _, a := range accounts {
+		ca := ChartAccount{
+			Code:          a.Code,
+			Name:          a.Name,
+			Description:   a.Description,
+			AccountType:   a.AccountType,
+			Currency:      a.Currency,
+			IsPlaceholder: a.IsPlaceholder,
+			IsSystem:      a.IsSystem,
+		}
+		if a.ParentID != nil {
+			ca.ParentCode = codes[*a.ParentID]
+		}
+		if len(a.Metadata) > 0 {
+			ca.Metadata = maps.Clone(a.Metadata)
+		}
+		chart.Accounts = append(chart.Accounts, ca)
+	}
+	chart.Accounts = chart.Sorted()
+
+	return chart
+}
+
+// DiffChart works out what importing a validated chart over a tenant's existing
+// accounts changes. An existing account's type, currency, parent and placeholder
+// flag cannot change, and every parent must be in the chart or already exist.
+func DiffChart(chart *Chart, existing []*Account) *ChartDiff {
+	byCode := make(map[string]*Account, len(existing))
+	codes := make(map[string]string, len(existing))
+	for _, a := range existing {
+		byCode[a.Code] = a
+		codes[a.ID] = a.Code
+	}
+	inChart := make(map[string]*ChartAccount, len(chart.Accounts))
+	for i := range chart.Accounts {
+		inChart[chart.Accounts[i].Code] = &chart.Accounts[i]
+	}
+
+	diff := &ChartDiff{
+		Create:    make([]ChartAccount, 0),
+		Update:    make([]ChartChange, 0),
+		Unlisted:  make([]string, 0),
+		Conflicts: make([]ChartConflict, 0),
+	}
+	conflict := func(code, format string, args ...any) {
+		diff.Conflicts = append(diff.Conflicts, ChartConflict{Code: code, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	for _, ca := range chart.Sorted() {
+		if ca.ParentCode != "" && inChart[ca.ParentCode] == nil {
+			parent, ok := byCode[ca.ParentCode]
+			switch {
+			case !ok:
+				conflict(ca.Code, "parent %s does not exist", ca.ParentCode)
+				continue
+			case parent.Currency != ca.Currency:
+				conflict(ca.Code, "parent %s is in %s, not %s", ca.ParentCode, parent.Currency, ca.Currency)
+				continue
+			}
+		}
+
+		a, ok := byCode[ca.Code]
+		if !ok {
+			diff.Create = append(diff.Create, ca)
+			continue
+		}
+
+		parentCode := ""
+		if a.ParentID != nil {
+			parentCode = codes[*a.ParentID]
+		}
+		switch {
+		case a.AccountType != ca.AccountType:
+			conflict(ca.Code, "account is %s, not %s", a.AccountType, ca.AccountType)
+			continue
+		case a.Currency != ca.Currency:
+			conflict(ca.Code, "account is in %s, not %s", a.Currency, ca.Currency)
+			continue
+		case parentCode != ca.ParentCode:
+			conflict(ca.Code, "account's parent is %q, not %q", parentCode, ca.ParentCode)
+			continue
+		case a.IsPlaceholder != ca.IsPlaceholder:
+			conflict(ca.Code, "placeholder flag cannot be changed")
+			continue
+		}
+
+		var fields []string
+		if a.Name != ca.Name {
+			fields = append(fields, "name")
+		}
+		if a.Description != ca.Description {
+			fields = append(fields, "description")
+		}
+		for key, value := range ca.Metadata {
+			if current, ok := a.Metadata[key]; !ok || current != value {
+				fields = append(fields, "metadata")
+				break
+			}
+		}
+		if len(fields) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Update = append(diff.Update, ChartChange{Code: ca.Code, Fields: fields})
+	}
+
+	for _, a := range existing {
+		if inChart[a.Code] == nil {
+			diff.Unlisted = append(diff.Unlisted, a.Code)
+		}
+	}
+	sort.Strings(diff.Unlisted)
+
+	return diff
+}
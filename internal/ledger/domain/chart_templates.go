@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"sort"
+
+	"finplatform/internal/common/money"
+)
+
+// Built-in chart of accounts templates
+const (
+	ChartTemplateEMIWallet   = "emi_wallet"
+	ChartTemplateMarketplace = "marketplace"
+	ChartTemplateLending     = "lending"
+)
+
+// chartTemplates are the built-in charts. Each holds the system accounts, which
+// the ledger itself posts to, and the accounts of its business under them.
+var chartTemplates = map[string]struct {
+	description string
+	accounts    []ChartAccount
+}{
+	ChartTemplateEMIWallet: {
+		description: "E-money institution holding safeguarded customer wallets",
+		accounts: []ChartAccount{
+			{Code: "1010", Name: "Safeguarding Account", AccountType: AccountTypeAsset, ParentCode: "1000"},
+			{Code: "1020", Name: "Operating Bank Account", AccountType: AccountTypeAsset, ParentCode: "1000"},
+			{Code: "1310", Name: "FPS Settlement", AccountType: AccountTypeAsset, ParentCode: "1300"},
+			{Code: "1320", Name: "SEPA Settlement", AccountType: AccountTypeAsset, ParentCode: "1300"},
+			{Code: "1330", Name: "Card Settlement", AccountType: AccountTypeAsset, ParentCode: "1300"},
+			{Code: "1340", Name: "Open Banking Settlement", AccountType: AccountTypeAsset, ParentCode: "1300"},
+			{Code: "4110", Name: "Funding Fees", AccountType: AccountTypeRevenue, ParentCode: "4100"},
+			{Code: "4120", Name: "Payout Fees", AccountType: AccountTypeRevenue, ParentCode: "4100"},
+			{Code: "5110", Name: "Card Scheme Fees", AccountType: AccountTypeExpense, ParentCode: "5100"},
+			{Code: "5120", Name: "Bank Charges", AccountType: AccountTypeExpense, ParentCode: "5100"},
+		},
+	},
+	ChartTemplateMarketplace: {
+		description: "Marketplace collecting from buyers and paying out sellers",
+		accounts: []ChartAccount{
+			{Code: "1020", Name: "Operating Bank Account", AccountType: AccountTypeAsset, ParentCode: "1000"},
+			{Code: "1330", Name: "Card Settlement", AccountType: AccountTypeAsset, ParentCode: "1300"},
+			{Code: "1350", Name: "Bank Transfer Settlement", AccountType: AccountTypeAsset, ParentCode: "1300"},
+			{Code: "2110", Name: "Seller Payables", AccountType: AccountTypeLiability, ParentCode: "2100"},
+			{Code: "2210", Name: "Scheduled Seller Payouts", AccountType: AccountTypeLiability, ParentCode: "2200"},
+			{Code: "2310", Name: "Dispute Reserve", AccountType: AccountTypeLiability, ParentCode: "2300"},
+			{Code: "4130", Name: "Commission Revenue", AccountType: AccountTypeRevenue, ParentCode: "4100"},
+			{Code: "4210", Name: "Listing Fees", AccountType: AccountTypeRevenue, ParentCode: "4200"},
+			{Code: "5130", Name: "Chargeback Losses", AccountType: AccountTypeExpense, ParentCode: "5100"},
+		},
+	},
+	ChartTemplateLending: {
+		description: "Lender disbursing loans and collecting repayments",
+		accounts: []ChartAccount{
+			{Code: "1020", Name: "Operating Bank Account", AccountType: AccountTypeAsset, ParentCode: "1000"},
+			{Code: "1500", Name: "Loans Receivable", AccountType: AccountTypeAsset, IsPlaceholder: true},
+			{Code: "1510", Name: "Principal Outstanding", AccountType: AccountTypeAsset, ParentCode: "1500"},
+			{Code: "1520", Name: "Interest Receivable", AccountType: AccountTypeAsset, ParentCode: "1500"},
+			{Code: "1530", Name: "Fees Receivable", AccountType: AccountTypeAsset, ParentCode: "1500"},
+			{Code: "1590", Name: "Loan Loss Allowance", AccountType: AccountTypeAsset, ParentCode: "1500"},
+			{Code: "2400", Name: "Unapplied Repayments", AccountType: AccountTypeLiability},
+			{Code: "4500", Name: "Interest Income", AccountType: AccountTypeRevenue},
+			{Code: "4600", Name: "Loan Fee Income", AccountType: AccountTypeRevenue},
+			{Code: "5400", Name: "Credit Loss Expense", AccountType: AccountTypeExpense},
+		},
+	},
+}
+
+// ChartTemplateNames lists the built-in chart templates
+func ChartTemplateNames() []string {
+	names := make([]string, 0, len(chartTemplates))
+	for name := range chartTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ChartTemplate returns the built-in chart named name in currency. Each of the
+// additional currencies gets a copy of every account coded by
+// CurrencyAccountCode, e.g. 1010-EUR under 1000-EUR.
+func ChartTemplate(name string, currency money.Currency, additional ...money.Currency) (*Chart, bool) {
+	tmpl, ok := chartTemplates[name]
+	if !ok {
+		return nil, false
+	}
+
+	base := make([]ChartAccount, 0, len(SystemAccounts())+len(tmpl.accounts))
+	for _, sa := range SystemAccounts() {
+		base = append(base, ChartAccount{Code: sa.Code, Name: sa.Name, AccountType: sa.AccountType, IsSystem: true})
+	}
+	for _, a := range tmpl.accounts {
+		a.IsSystem = true
+		base = append(base, a)
+	}
+
+	chart := &Chart{Name: name, Description: tmpl.description, Currency: currency}
+	for _, a := range base {
+		a.Currency = currency
+		chart.Accounts = append(chart.Accounts, a)
+	}
+	for _, ccy := range additional {
+		if ccy == currency {
+			continue
+		}
+		for _, a := range base {
+			a.Code = CurrencyAccountCode(a.Code, ccy)
+			a.Name = a.Name + " (" + string(ccy) + ")"
+			a.Currency = ccy
+			if a.ParentCode != "" {
+				a.ParentCode = CurrencyAccountCode(a.ParentCode, ccy)
+			}
+			chart.Accounts = append(chart.Accounts, a)
+		}
+	}
+	chart.Accounts = chart.Sorted()
+
+	return chart, true
+}
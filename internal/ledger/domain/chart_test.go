@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"finplatform/internal/common/money"
+)
+
+func TestChartYAML(t *testing.T) {
+	const doc = `
+name: Wallets
+currency: EUR
+accounts:
+  - code: "1000"
+    name: Assets
+    account_type: asset
+    is_placeholder: true
+  - code: "1010"
+    name: Safeguarding
+    description: Client money
+    account_type: asset
+    currency: GBP
+    parent_code: "1000"
+    is_system: true
+    metadata:
+      bank: acme
+`
+	want := &Chart{
+		Name:     "Wallets",
+		Currency: money.EUR,
+		Accounts: []ChartAccount{
+			{Code: "1000", Name: "Assets", AccountType: AccountTypeAsset, IsPlaceholder: true},
+			{Code: "1010", Name: "Safeguarding", Description: "Client money", AccountType: AccountTypeAsset, Currency: money.GBP,
+				ParentCode: "1000", IsSystem: true, Metadata: map[string]string{"bank": "acme"}},
+		},
+	}
+
+	var chart *Chart
+	if err := yaml.Unmarshal([]byte(doc), &chart); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(chart, want) {
+		t.Fatalf("chart %+v, want %+v", chart, want)
+	}
+
+	// YAML and JSON name the fields alike, so either form converts to the other
+	data, err := yaml.Marshal(chart)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var fromYAML, fromJSON map[string]any
+	if err := yaml.Unmarshal(data, &fromYAML); err != nil {
+		t.Fatalf("unmarshal exported YAML: %v", err)
+	}
+	if data, err = json.Marshal(chart); err != nil {
+		t.Fatalf("marshal JSON: %v", err)
+	}
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatalf("unmarshal JSON: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("YAML export %v differs from JSON export %v", fromYAML, fromJSON)
+	}
+}
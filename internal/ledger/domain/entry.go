@@ -2,6 +2,9 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"math/big"
+	"sort"
 	"time"
 
 	"finplatform/internal/common/money"
@@ -22,6 +25,7 @@ const (
 	BatchStatusPending  BatchStatus = "pending"
 	BatchStatusPosted   BatchStatus = "posted"
 	BatchStatusReversed BatchStatus = "reversed"
+	BatchStatusVoided   BatchStatus = "voided"
 )
 
 // SourceType represents the source of a ledger batch
@@ -34,19 +38,38 @@ const (
 	SourceTypeFee        SourceType = "fee"
 	SourceTypeAdjustment SourceType = "adjustment"
 	SourceTypeTransfer   SourceType = "transfer"
+	SourceTypeReversal   SourceType = "reversal"
+
+	// SourceTypePeriodClose sweeps revenue and expense into retained earnings
+	SourceTypePeriodClose SourceType = "period_close"
+)
+
+// Batch errors
+var (
+	ErrBatchNotPending          = errors.New("batch is not pending")
+	ErrBatchNotPosted           = errors.New("only posted batches can be reversed")
+	ErrReversalExceedsRemaining = errors.New("reversal amount exceeds the unreversed amount of the batch")
+	ErrCurrencyMismatch         = errors.New("entry currency does not match account currency")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
 )
 
 // Entry represents a single ledger entry
 type Entry struct {
-	ID           string         `json:"id"`
-	BatchID      string         `json:"batch_id"`
-	AccountID    string         `json:"account_id"`
-	EntryType    EntryType      `json:"entry_type"`
-	Amount       money.Money    `json:"amount"`
-	BalanceAfter *int64         `json:"balance_after,omitempty"`
-	Description  string         `json:"description,omitempty"`
-	Sequence     int            `json:"sequence"`
-	CreatedAt    time.Time      `json:"created_at"`
+	ID            string      `json:"id"`
+	BatchID       string      `json:"batch_id"`
+	AccountID     string      `json:"account_id"`
+	EntryType     EntryType   `json:"entry_type"`
+	Amount        money.Money `json:"amount"`
+	BalanceAfter  *int64      `json:"balance_after,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Sequence      int         `json:"sequence"`
+	EffectiveDate time.Time   `json:"effective_date"`
+	PostedAt      *time.Time  `json:"posted_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+
+	// Reversal tracking
+	ReversesEntryID *string `json:"reverses_entry_id,omitempty"`
+	ReversedAmount  int64   `json:"reversed_amount,omitempty"`
 }
 
 // NewEntry creates a new ledger entry
@@ -77,32 +100,64 @@ func NewEntry(id, batchID, accountID string, entryType EntryType, amount money.M
 
 // Batch represents a ledger batch (a group of balanced entries)
 type Batch struct {
-	ID             string            `json:"id"`
-	TenantID       string            `json:"tenant_id"`
-	Reference      string            `json:"reference,omitempty"`
-	Description    string            `json:"description,omitempty"`
-	SourceType     SourceType        `json:"source_type"`
-	SourceID       string            `json:"source_id,omitempty"`
-	TotalDebits    money.Money       `json:"total_debits"`
-	TotalCredits   money.Money       `json:"total_credits"`
-	EntryCount     int               `json:"entry_count"`
-	Status         BatchStatus       `json:"status"`
-	PostedAt       *time.Time        `json:"posted_at,omitempty"`
-	PostedBy       *string           `json:"posted_by,omitempty"`
-	ReversedAt     *time.Time        `json:"reversed_at,omitempty"`
-	ReversedBy     *string           `json:"reversed_by,omitempty"`
-	ReversalReason string            `json:"reversal_reason,omitempty"`
-	Metadata       map[string]string `json:"metadata,omitempty"`
-	CreatedAt      time.Time         `json:"created_at"`
-	Entries        []*Entry          `json:"entries,omitempty"`
+	ID              string            `json:"id"`
+	TenantID        string            `json:"tenant_id"`
+	Reference       string            `json:"reference,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	SourceType      SourceType        `json:"source_type"`
+	SourceID        string            `json:"source_id,omitempty"`
+	IdempotencyKey  string            `json:"idempotency_key,omitempty"`
+	Fingerprint     string            `json:"-"` // Hash of the request that created the batch
+	TotalDebits     money.Money       `json:"total_debits"`
+	TotalCredits    money.Money       `json:"total_credits"`
+	EntryCount      int               `json:"entry_count"`
+	Status          BatchStatus       `json:"status"`
+	EffectiveDate   time.Time         `json:"effective_date"`             // Value date (UTC), when the batch counts
+	PostingSequence *int64            `json:"posting_sequence,omitempty"` // Position in the tenant's change feed
+	ContentHash     string            `json:"content_hash,omitempty"`     // See ComputeContentHash
+	ChainHash       string            `json:"chain_hash,omitempty"`       // Links the batch to the one posted before it
+	PostedAt        *time.Time        `json:"posted_at,omitempty"`
+	PostedBy        *string           `json:"posted_by,omitempty"`
+	ReversedAt      *time.Time        `json:"reversed_at,omitempty"`
+	ReversedBy      *string           `json:"reversed_by,omitempty"`
+	ReversalReason  string            `json:"reversal_reason,omitempty"`
+	ReversedAmount  int64             `json:"reversed_amount,omitempty"`
+	ReversalOfID    *string           `json:"reversal_of_batch_id,omitempty"`
+	VoidedAt        *time.Time        `json:"voided_at,omitempty"`
+	VoidedBy        *string           `json:"voided_by,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	Entries         []*Entry          `json:"entries,omitempty"`
+
+	// Multi-currency batches balance per currency; TotalDebits and TotalCredits
+	// hold the totals of the batch (base) currency only.
+	Totals []CurrencyTotal `json:"totals,omitempty"`
+	FXLegs []*FXLeg        `json:"fx_legs,omitempty"`
+}
+
+// FeedPage is a page of the change feed: posted batches in posting sequence order
+type FeedPage struct {
+	Batches   []*Batch `json:"batches"`
+	NextAfter int64    `json:"next_after"` // Resume the feed after this sequence
+	HasMore   bool     `json:"has_more"`
+}
+
+// CurrencyTotal holds the totals of a batch in one currency
+type CurrencyTotal struct {
+	Currency     money.Currency `json:"currency"`
+	TotalDebits  int64          `json:"total_debits"`
+	TotalCredits int64          `json:"total_credits"`
+	EntryCount   int            `json:"entry_count"`
 }
 
 // BatchBuilder helps construct valid ledger batches
 type BatchBuilder struct {
 	batch   *Batch
 	entries []*Entry
-	debits  int64
-	credits int64
+	fxLegs  []*FXLeg
+	debits  map[money.Currency]int64
+	credits map[money.Currency]int64
+	counts  map[money.Currency]int
 	seq     int
 	err     error
 }
@@ -113,18 +168,25 @@ func NewBatchBuilder(id, tenantID string, sourceType SourceType, currency money.
 		return &BatchBuilder{err: errors.New("id and tenant_id are required")}
 	}
 
+	now := time.Now().UTC()
+	today, _ := PeriodBounds(PeriodTypeDaily, now)
+
 	return &BatchBuilder{
 		batch: &Batch{
-			ID:           id,
-			TenantID:     tenantID,
-			SourceType:   sourceType,
-			TotalDebits:  money.Zero(currency),
-			TotalCredits: money.Zero(currency),
-			Status:       BatchStatusPending,
-			Metadata:     make(map[string]string),
-			CreatedAt:    time.Now().UTC(),
+			ID:            id,
+			TenantID:      tenantID,
+			SourceType:    sourceType,
+			TotalDebits:   money.Zero(currency),
+			TotalCredits:  money.Zero(currency),
+			Status:        BatchStatusPending,
+			EffectiveDate: today,
+			Metadata:      make(map[string]string),
+			CreatedAt:     now,
 		},
 		entries: make([]*Entry, 0),
+		debits:  make(map[money.Currency]int64),
+		credits: make(map[money.Currency]int64),
+		counts:  make(map[money.Currency]int),
 		seq:     0,
 	}
 }
@@ -156,6 +218,19 @@ func (b *BatchBuilder) WithSourceID(sourceID string) *BatchBuilder {
 	return b
 }
 
+// WithEffectiveDate sets the value date; it defaults to the day the batch is built
+func (b *BatchBuilder) WithEffectiveDate(date time.Time) *BatchBuilder {
+	if b.err != nil {
+		return b
+	}
+	if date.IsZero() {
+		b.err = errors.New("effective date is required")
+		return b
+	}
+	b.batch.EffectiveDate, _ = PeriodBounds(PeriodTypeDaily, date)
+	return b
+}
+
 // WithMetadata adds metadata
 func (b *BatchBuilder) WithMetadata(key, value string) *BatchBuilder {
 	if b.err != nil {
@@ -171,8 +246,8 @@ func (b *BatchBuilder) Debit(entryID, accountID string, amount money.Money, desc
 		return b
 	}
 
-	if amount.Currency != b.batch.TotalDebits.Currency {
-		b.err = errors.New("entry currency must match batch currency")
+	if amount.Currency == "" {
+		b.err = errors.New("entry currency is required")
 		return b
 	}
 
@@ -185,7 +260,8 @@ func (b *BatchBuilder) Debit(entryID, accountID string, amount money.Money, desc
 	entry.Description = description
 
 	b.entries = append(b.entries, entry)
-	b.debits += amount.AmountMinor
+	b.debits[amount.Currency] += amount.AmountMinor
+	b.counts[amount.Currency]++
 	return b
 }
 
@@ -195,8 +271,8 @@ func (b *BatchBuilder) Credit(entryID, accountID string, amount money.Money, des
 		return b
 	}
 
-	if amount.Currency != b.batch.TotalCredits.Currency {
-		b.err = errors.New("entry currency must match batch currency")
+	if amount.Currency == "" {
+		b.err = errors.New("entry currency is required")
 		return b
 	}
 
@@ -209,7 +285,35 @@ func (b *BatchBuilder) Credit(entryID, accountID string, amount money.Money, des
 	entry.Description = description
 
 	b.entries = append(b.entries, entry)
-	b.credits += amount.AmountMinor
+	b.credits[amount.Currency] += amount.AmountMinor
+	b.counts[amount.Currency]++
+	return b
+}
+
+// FX adds a currency conversion leg. The source amount is credited to the source
+// position account and the counter amount plus any FX revenue is debited from the
+// counter position account, so that each currency balances on its own.
+func (b *BatchBuilder) FX(leg *FXLeg, sourceEntryID, counterEntryID, revenueEntryID string) *BatchBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := leg.Validate(); err != nil {
+		b.err = err
+		return b
+	}
+	leg.BatchID = b.batch.ID
+
+	counterTotal := money.New(leg.CounterAmount.AmountMinor+leg.RevenueAmount, leg.CounterAmount.Currency)
+	description := fmt.Sprintf("FX %s/%s @ %s", leg.SourceAmount.Currency, leg.CounterAmount.Currency, leg.Rate)
+
+	b.Credit(sourceEntryID, leg.SourcePositionAccountID, leg.SourceAmount, description)
+	b.Debit(counterEntryID, leg.CounterPositionAccountID, counterTotal, description)
+	if leg.RevenueAmount > 0 {
+		b.Credit(revenueEntryID, leg.RevenueAccountID, money.New(leg.RevenueAmount, leg.CounterAmount.Currency), description)
+	}
+
+	b.fxLegs = append(b.fxLegs, leg)
 	return b
 }
 
@@ -223,19 +327,39 @@ func (b *BatchBuilder) Build() (*Batch, error) {
 		return nil, errors.New("batch must have at least one entry")
 	}
 
-	if b.debits != b.credits {
-		return nil, errors.New("batch must be balanced (debits must equal credits)")
+	base := b.batch.TotalDebits.Currency
+	if b.counts[base] == 0 {
+		return nil, fmt.Errorf("batch must have entries in its currency %s", base)
 	}
 
-	b.batch.TotalDebits.AmountMinor = b.debits
-	b.batch.TotalCredits.AmountMinor = b.credits
+	totals := make([]CurrencyTotal, 0, len(b.counts))
+	for currency, count := range b.counts {
+		if b.debits[currency] != b.credits[currency] {
+			return nil, fmt.Errorf("batch must be balanced in %s (debits must equal credits)", currency)
+		}
+		totals = append(totals, CurrencyTotal{
+			Currency:     currency,
+			TotalDebits:  b.debits[currency],
+			TotalCredits: b.credits[currency],
+			EntryCount:   count,
+		})
+	}
+	SortCurrencyTotals(totals, base)
+
+	b.batch.TotalDebits.AmountMinor = b.debits[base]
+	b.batch.TotalCredits.AmountMinor = b.credits[base]
 	b.batch.EntryCount = len(b.entries)
 	b.batch.Entries = b.entries
+	for _, entry := range b.entries {
+		entry.EffectiveDate = b.batch.EffectiveDate
+	}
+	b.batch.Totals = totals
+	b.batch.FXLegs = b.fxLegs
 
 	return b.batch, nil
 }
 
-// Validate validates a batch is balanced
+// Validate validates a batch is balanced in every currency
 func (batch *Batch) Validate() error {
 	if batch.TotalDebits.AmountMinor != batch.TotalCredits.AmountMinor {
 		return errors.New("batch is not balanced")
@@ -249,26 +373,73 @@ func (batch *Batch) Validate() error {
 		return errors.New("entry count mismatch")
 	}
 
-	var debits, credits int64
+	totals := batch.CurrencyTotals()
+	expected := make(map[money.Currency]CurrencyTotal, len(totals))
+	for _, t := range totals {
+		if t.TotalDebits != t.TotalCredits {
+			return fmt.Errorf("batch is not balanced in %s", t.Currency)
+		}
+		expected[t.Currency] = t
+	}
+
+	actual := make(map[money.Currency]CurrencyTotal)
 	for _, entry := range batch.Entries {
+		t := actual[entry.Amount.Currency]
+		t.Currency = entry.Amount.Currency
 		if entry.EntryType == EntryTypeDebit {
-			debits += entry.Amount.AmountMinor
+			t.TotalDebits += entry.Amount.AmountMinor
 		} else {
-			credits += entry.Amount.AmountMinor
+			t.TotalCredits += entry.Amount.AmountMinor
 		}
+		t.EntryCount++
+		actual[entry.Amount.Currency] = t
 	}
 
-	if debits != batch.TotalDebits.AmountMinor || credits != batch.TotalCredits.AmountMinor {
-		return errors.New("entry totals do not match batch totals")
+	if len(actual) != len(expected) {
+		return errors.New("entry currencies do not match batch totals")
+	}
+	for currency, t := range actual {
+		if t != expected[currency] {
+			return fmt.Errorf("entry totals do not match batch totals in %s", currency)
+		}
 	}
 
 	return nil
 }
 
+// CurrencyTotals returns the per-currency totals of the batch. Batches without
+// stored per-currency totals are single-currency and use the batch totals.
+func (batch *Batch) CurrencyTotals() []CurrencyTotal {
+	if len(batch.Totals) > 0 {
+		return batch.Totals
+	}
+	return []CurrencyTotal{{
+		Currency:     batch.TotalDebits.Currency,
+		TotalDebits:  batch.TotalDebits.AmountMinor,
+		TotalCredits: batch.TotalCredits.AmountMinor,
+		EntryCount:   batch.EntryCount,
+	}}
+}
+
+// IsMultiCurrency returns whether the batch has entries in more than one currency
+func (batch *Batch) IsMultiCurrency() bool {
+	return len(batch.CurrencyTotals()) > 1
+}
+
+// SortCurrencyTotals orders totals with the base currency first, then alphabetically
+func SortCurrencyTotals(totals []CurrencyTotal, base money.Currency) {
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Currency == base || totals[j].Currency == base {
+			return totals[i].Currency == base
+		}
+		return totals[i].Currency < totals[j].Currency
+	})
+}
+
 // Post marks the batch as posted
 func (batch *Batch) Post(userID string) error {
 	if batch.Status != BatchStatusPending {
-		return errors.New("only pending batches can be posted")
+		return ErrBatchNotPending
 	}
 
 	now := time.Now().UTC()
@@ -280,10 +451,48 @@ func (batch *Batch) Post(userID string) error {
 	return nil
 }
 
+// AccountIDs returns the distinct accounts the batch's entries post to
+func (batch *Batch) AccountIDs() []string {
+	seen := make(map[string]bool, len(batch.Entries))
+	ids := make([]string, 0, len(batch.Entries))
+	for _, entry := range batch.Entries {
+		if !seen[entry.AccountID] {
+			seen[entry.AccountID] = true
+			ids = append(ids, entry.AccountID)
+		}
+	}
+	return ids
+}
+
+// IsBackdated returns whether a posted batch has a value date before the day it
+// was posted on
+func (batch *Batch) IsBackdated() bool {
+	if batch.PostedAt == nil {
+		return false
+	}
+	postedOn, _ := PeriodBounds(PeriodTypeDaily, *batch.PostedAt)
+	return batch.EffectiveDate.Before(postedOn)
+}
+
+// Void discards a pending batch without ever affecting posted balances
+func (batch *Batch) Void(userID string) error {
+	if batch.Status != BatchStatusPending {
+		return ErrBatchNotPending
+	}
+
+	now := time.Now().UTC()
+	batch.Status = BatchStatusVoided
+	batch.VoidedAt = &now
+	if userID != "" {
+		batch.VoidedBy = &userID
+	}
+	return nil
+}
+
 // Reverse marks the batch as reversed
 func (batch *Batch) Reverse(userID, reason string) error {
 	if batch.Status != BatchStatusPosted {
-		return errors.New("only posted batches can be reversed")
+		return ErrBatchNotPosted
 	}
 
 	now := time.Now().UTC()
@@ -296,6 +505,118 @@ func (batch *Batch) Reverse(userID, reason string) error {
 	return nil
 }
 
+// RemainingReversible returns the part of the batch total that has not been reversed yet
+func (batch *Batch) RemainingReversible() int64 {
+	return batch.TotalDebits.AmountMinor - batch.ReversedAmount
+}
+
+// ApplyReversal records a (partial) reversal of amount against the batch.
+// The batch is marked reversed once its full total has been reversed.
+func (batch *Batch) ApplyReversal(amount int64, userID, reason string) error {
+	if batch.Status != BatchStatusPosted {
+		return ErrBatchNotPosted
+	}
+	if amount <= 0 || amount > batch.RemainingReversible() {
+		return ErrReversalExceedsRemaining
+	}
+
+	batch.ReversedAmount += amount
+	if batch.RemainingReversible() == 0 {
+		return batch.Reverse(userID, reason)
+	}
+	return nil
+}
+
+// ReversalAmounts returns, for each entry of the batch, the amount to reverse so
+// that amount (in the batch currency) is taken out of the batch. Other currencies
+// of a multi-currency batch are reversed in the same proportion. Within each
+// currency the amount is spread pro rata over the unreversed part of the entries
+// on each side, using the largest remainder method, so the reversal is always
+// balanced per currency and never reverses an entry more than once.
+// Entries that get nothing are returned as 0.
+func (batch *Batch) ReversalAmounts(amount int64) ([]int64, error) {
+	remainingBase := batch.RemainingReversible()
+	if amount <= 0 || amount > remainingBase {
+		return nil, ErrReversalExceedsRemaining
+	}
+
+	amounts := make([]int64, len(batch.Entries))
+	for _, total := range batch.CurrencyTotals() {
+		currency := total.Currency
+		for _, side := range []EntryType{EntryTypeDebit, EntryTypeCredit} {
+			var indexes []int
+			var remaining int64
+			for i, entry := range batch.Entries {
+				if entry.Amount.Currency == currency && entry.EntryType == side && entry.RemainingReversible() > 0 {
+					indexes = append(indexes, i)
+					remaining += entry.RemainingReversible()
+				}
+			}
+
+			part := amount
+			if currency != batch.TotalDebits.Currency {
+				scaled := new(big.Int).Mul(big.NewInt(remaining), big.NewInt(amount))
+				part = scaled.Quo(scaled, big.NewInt(remainingBase)).Int64()
+			}
+
+			if err := allocateProRata(batch.Entries, indexes, remaining, part, amounts); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return amounts, nil
+}
+
+// allocateProRata spreads amount over the entries at indexes in proportion to
+// their unreversed amounts, writing the result into amounts
+func allocateProRata(entries []*Entry, indexes []int, remaining, amount int64, amounts []int64) error {
+	if amount > remaining {
+		return ErrReversalExceedsRemaining
+	}
+
+	type fraction struct {
+		index     int
+		remainder *big.Int
+	}
+
+	total := big.NewInt(remaining)
+	fractions := make([]fraction, 0, len(indexes))
+	var allocated int64
+	for _, i := range indexes {
+		share, rem := new(big.Int).QuoRem(
+			new(big.Int).Mul(big.NewInt(entries[i].RemainingReversible()), big.NewInt(amount)),
+			total,
+			new(big.Int),
+		)
+		amounts[i] = share.Int64()
+		allocated += amounts[i]
+		fractions = append(fractions, fraction{index: i, remainder: rem})
+	}
+
+	sort.SliceStable(fractions, func(a, b int) bool {
+		return fractions[a].remainder.Cmp(fractions[b].remainder) > 0
+	})
+	for k := int64(0); k < amount-allocated; k++ {
+		amounts[fractions[k].index]++
+	}
+
+	return nil
+}
+
+// RemainingReversible returns the part of the entry that has not been reversed yet
+func (e *Entry) RemainingReversible() int64 {
+	return e.Amount.AmountMinor - e.ReversedAmount
+}
+
+// SignedAmount returns the entry amount as it affects a balance with the given normal side
+func (e *Entry) SignedAmount(normalBalance NormalBalance) int64 {
+	if (normalBalance == NormalBalanceDebit) == (e.EntryType == EntryTypeDebit) {
+		return e.Amount.AmountMinor
+	}
+	return -e.Amount.AmountMinor
+}
+
 // Position represents an account's position for a period
 type Position struct {
 	ID             string         `json:"id"`
@@ -310,6 +631,8 @@ type Position struct {
 	ClosingBalance int64          `json:"closing_balance"`
 	EntryCount     int            `json:"entry_count"`
 	Currency       money.Currency `json:"currency"`
+	DateBasis      DateBasis      `json:"date_basis"`
+	Stale          bool           `json:"stale,omitempty"` // A backdated posting changed the period since it was computed
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 }
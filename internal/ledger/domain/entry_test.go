@@ -0,0 +1,409 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"finplatform/internal/common/money"
+)
+
+// reversalTestBatch returns a posted EUR batch debiting and crediting the
+// amounts given, in that order
+func reversalTestBatch(t *testing.T, debits, credits []int64) *Batch {
+	t.Helper()
+	b := NewBatchBuilder("batch1", "tenant1", SourceTypePayment, money.EUR)
+	for i, amount := range debits {
+		b.Debit(fmt.Sprintf("d%d", i+1), "debit-account", money.New(amount, money.EUR), "")
+	}
+	for i, amount := range credits {
+		b.Credit(fmt.Sprintf("c%d", i+1), "credit-account", money.New(amount, money.EUR), "")
+	}
+	batch, err := b.Build()
+	if err != nil {
+		t.Fatalf("building batch: %v", err)
+	}
+	if err := batch.Post("user1"); err != nil {
+		t.Fatalf("posting batch: %v", err)
+	}
+	return batch
+}
+
+// applyReversalAmounts records amounts against the batch's entries and amount
+// against the batch, as posting the reversal does
+func applyReversalAmounts(t *testing.T, batch *Batch, amount int64, amounts []int64) {
+	t.Helper()
+	for i, a := range amounts {
+		batch.Entries[i].ReversedAmount += a
+	}
+	if err := batch.ApplyReversal(amount, "user1", "test"); err != nil {
+		t.Fatalf("applying reversal: %v", err)
+	}
+}
+
+// checkReversalBalanced fails the test unless amounts reverse amount on each side
+// and no entry more than its unreversed part
+func checkReversalBalanced(t *testing.T, batch *Batch, amount int64, amounts []int64) {
+	t.Helper()
+	sides := make(map[EntryType]int64)
+	for i, a := range amounts {
+		entry := batch.Entries[i]
+		if a < 0 || a > entry.RemainingReversible() {
+			t.Errorf("entry %s reverses %d of its unreversed %d", entry.ID, a, entry.RemainingReversible())
+		}
+		sides[entry.EntryType] += a
+	}
+	if sides[EntryTypeDebit] != amount || sides[EntryTypeCredit] != amount {
+		t.Errorf("reverses %d debits and %d credits, want %d each", sides[EntryTypeDebit], sides[EntryTypeCredit], amount)
+	}
+}
+
+func TestReversalAmounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		debits  []int64
+		credits []int64
+		amount  int64
+		want    []int64 // Debits then credits
+	}{
+		{name: "full", debits: []int64{1000}, credits: []int64{600, 400}, amount: 1000, want: []int64{1000, 600, 400}},
+		{name: "partial", debits: []int64{1000}, credits: []int64{600, 400}, amount: 500, want: []int64{500, 300, 200}},
+		{name: "smallest", debits: []int64{1000}, credits: []int64{600, 400}, amount: 1, want: []int64{1, 1, 0}},
+		{name: "largest remainder first", debits: []int64{10}, credits: []int64{3, 3, 4}, amount: 5, want: []int64{5, 2, 1, 2}},
+		{name: "remainder ties kept in entry order", debits: []int64{3}, credits: []int64{1, 1, 1}, amount: 2, want: []int64{2, 1, 1, 0}},
+		{name: "rounding on both sides", debits: []int64{333, 333, 334}, credits: []int64{500, 250, 250}, amount: 101, want: []int64{34, 33, 34, 51, 25, 25}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := reversalTestBatch(t, tt.debits, tt.credits)
+			amounts, err := batch.ReversalAmounts(tt.amount)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(amounts, tt.want) {
+				t.Errorf("amounts %v, want %v", amounts, tt.want)
+			}
+			checkReversalBalanced(t, batch, tt.amount, amounts)
+		})
+	}
+}
+
+func TestReversalAmountsRepeated(t *testing.T) {
+	tests := []struct {
+		name    string
+		debits  []int64
+		credits []int64
+		steps   []int64
+	}{
+		{name: "halves", debits: []int64{1000}, credits: []int64{600, 400}, steps: []int64{500, 500}},
+		{name: "thirds", debits: []int64{100}, credits: []int64{33, 33, 34}, steps: []int64{33, 33, 34}},
+		{name: "one unit at a time", debits: []int64{2, 1}, credits: []int64{1, 1, 1}, steps: []int64{1, 1, 1}},
+		{name: "uneven", debits: []int64{777, 223}, credits: []int64{1, 999}, steps: []int64{1, 998, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := reversalTestBatch(t, tt.debits, tt.credits)
+			for i, step := range tt.steps {
+				amounts, err := batch.ReversalAmounts(step)
+				if err != nil {
+					t.Fatalf("step %d: unexpected error %v", i, err)
+				}
+				checkReversalBalanced(t, batch, step, amounts)
+				applyReversalAmounts(t, batch, step, amounts)
+			}
+
+			if batch.Status != BatchStatusReversed || batch.RemainingReversible() != 0 {
+				t.Errorf("batch %s with %d unreversed, want fully reversed", batch.Status, batch.RemainingReversible())
+			}
+			for _, entry := range batch.Entries {
+				if entry.RemainingReversible() != 0 {
+					t.Errorf("entry %s has %d unreversed", entry.ID, entry.RemainingReversible())
+				}
+			}
+		})
+	}
+}
+
+func TestReversalAmountsRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		reversed int64 // Reversed before
+		amount   int64
+	}{
+		{name: "zero", amount: 0},
+		{name: "negative", amount: -1},
+		{name: "more than the total", amount: 1001},
+		{name: "more than the rest", reversed: 400, amount: 601},
+		{name: "after a full reversal", reversed: 1000, amount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := reversalTestBatch(t, []int64{1000}, []int64{600, 400})
+			if tt.reversed > 0 {
+				amounts, err := batch.ReversalAmounts(tt.reversed)
+				if err != nil {
+					t.Fatalf("reversing %d: %v", tt.reversed, err)
+				}
+				applyReversalAmounts(t, batch, tt.reversed, amounts)
+			}
+
+			if _, err := batch.ReversalAmounts(tt.amount); !errors.Is(err, ErrReversalExceedsRemaining) {
+				t.Errorf("error %v, want %v", err, ErrReversalExceedsRemaining)
+			}
+		})
+	}
+}
+
+func TestApplyReversal(t *testing.T) {
+	batch := reversalTestBatch(t, []int64{1000}, []int64{1000})
+
+	if err := batch.ApplyReversal(400, "user1", "partial"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if batch.Status != BatchStatusPosted || batch.RemainingReversible() != 600 {
+		t.Errorf("after partial reversal: %s with %d unreversed, want posted with 600", batch.Status, batch.RemainingReversible())
+	}
+
+	if err := batch.ApplyReversal(601, "user1", "over"); !errors.Is(err, ErrReversalExceedsRemaining) {
+		t.Errorf("over-reversal error %v, want %v", err, ErrReversalExceedsRemaining)
+	}
+
+	if err := batch.ApplyReversal(600, "user1", "rest"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if batch.Status != BatchStatusReversed || batch.ReversedAt == nil || batch.ReversalReason != "rest" {
+		t.Errorf("after full reversal: %s at %v for %q, want reversed", batch.Status, batch.ReversedAt, batch.ReversalReason)
+	}
+
+	if err := batch.ApplyReversal(1, "user1", "again"); !errors.Is(err, ErrBatchNotPosted) {
+		t.Errorf("reversing a reversed batch: error %v, want %v", err, ErrBatchNotPosted)
+	}
+}
+
+func TestAllocateProRata(t *testing.T) {
+	entries := []*Entry{
+		{ID: "e1", Amount: money.New(500, money.EUR), ReversedAmount: 100},
+		{ID: "e2", Amount: money.New(300, money.EUR)},
+		{ID: "e3", Amount: money.New(200, money.EUR), ReversedAmount: 200},
+		{ID: "e4", Amount: money.New(100, money.EUR)},
+	}
+	const remaining = 400 + 300 + 100 // e3 is already fully reversed
+
+	tests := []struct {
+		name    string
+		indexes []int
+		amount  int64
+		want    []int64
+		err     error
+	}{
+		{name: "all", indexes: []int{0, 1, 3}, amount: remaining, want: []int64{400, 300, 0, 100}},
+		{name: "pro rata", indexes: []int{0, 1, 3}, amount: 80, want: []int64{40, 30, 0, 10}},
+		{name: "rounded", indexes: []int{0, 1, 3}, amount: 7, want: []int64{3, 3, 0, 1}},
+		{name: "none", indexes: []int{0, 1, 3}, amount: 0, want: []int64{0, 0, 0, 0}},
+		{name: "too much", indexes: []int{0, 1, 3}, amount: remaining + 1, err: ErrReversalExceedsRemaining},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amounts := make([]int64, len(entries))
+			err := allocateProRata(entries, tt.indexes, remaining, tt.amount, amounts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if !reflect.DeepEqual(amounts, tt.want) {
+				t.Errorf("amounts %v, want %v", amounts, tt.want)
+			}
+		})
+	}
+}
+
+// fxTestLeg converts EUR 10.00 into USD 11.00 at 1.10, USD 0.10 of it FX revenue
+func fxTestLeg() *FXLeg {
+	return &FXLeg{
+		ID:                       "fx1",
+		SourceAmount:             money.New(1000, money.EUR),
+		CounterAmount:            money.New(1090, money.USD),
+		Rate:                     "1.10",
+		SourcePositionAccountID:  "eur-position",
+		CounterPositionAccountID: "usd-position",
+		RevenueAccountID:         "fx-revenue",
+		RevenueAmount:            10,
+	}
+}
+
+// fxTestBatch builds a payment of EUR 10.00 out of a EUR wallet into a USD wallet
+func fxTestBatch(t *testing.T) *Batch {
+	t.Helper()
+	batch, err := NewBatchBuilder("batch1", "tenant1", SourceTypePayment, money.EUR).
+		Debit("e1", "eur-wallet", money.New(1000, money.EUR), "").
+		FX(fxTestLeg(), "e2", "e3", "e4").
+		Credit("e5", "usd-wallet", money.New(1090, money.USD), "").
+		Build()
+	if err != nil {
+		t.Fatalf("building batch: %v", err)
+	}
+	return batch
+}
+
+func TestBatchBuilderMultiCurrency(t *testing.T) {
+	batch := fxTestBatch(t)
+
+	want := []CurrencyTotal{
+		{Currency: money.EUR, TotalDebits: 1000, TotalCredits: 1000, EntryCount: 2},
+		{Currency: money.USD, TotalDebits: 1100, TotalCredits: 1100, EntryCount: 3},
+	}
+	if !reflect.DeepEqual(batch.Totals, want) {
+		t.Errorf("totals %+v, want %+v", batch.Totals, want)
+	}
+	if batch.TotalDebits != money.New(1000, money.EUR) || batch.TotalCredits != money.New(1000, money.EUR) {
+		t.Errorf("batch totals %v and %v, want EUR 1000 each", batch.TotalDebits, batch.TotalCredits)
+	}
+	if batch.EntryCount != 5 || len(batch.Entries) != 5 {
+		t.Errorf("%d entries counted of %d, want 5", batch.EntryCount, len(batch.Entries))
+	}
+	if len(batch.FXLegs) != 1 || batch.FXLegs[0].BatchID != batch.ID {
+		t.Errorf("fx legs %+v, want the leg linked to the batch", batch.FXLegs)
+	}
+	if !batch.IsMultiCurrency() {
+		t.Error("batch is not multi-currency")
+	}
+	if err := batch.Validate(); err != nil {
+		t.Errorf("built batch does not validate: %v", err)
+	}
+
+	// The leg credits the source position and debits the counter position with
+	// the revenue included
+	legEntries := map[string]struct {
+		entryType EntryType
+		amount    money.Money
+	}{
+		"e2": {EntryTypeCredit, money.New(1000, money.EUR)},
+		"e3": {EntryTypeDebit, money.New(1100, money.USD)},
+		"e4": {EntryTypeCredit, money.New(10, money.USD)},
+	}
+	for _, entry := range batch.Entries {
+		if want, ok := legEntries[entry.ID]; ok && (entry.EntryType != want.entryType || entry.Amount != want.amount) {
+			t.Errorf("entry %s %s %v, want %s %v", entry.ID, entry.EntryType, entry.Amount, want.entryType, want.amount)
+		}
+	}
+}
+
+func TestBatchBuilderBuildErrors(t *testing.T) {
+	eur := func(amount int64) money.Money { return money.New(amount, money.EUR) }
+	usd := func(amount int64) money.Money { return money.New(amount, money.USD) }
+
+	tests := []struct {
+		name    string
+		build   func(b *BatchBuilder) *BatchBuilder
+		message string // Part of the error message
+	}{
+		{
+			name:    "no entries",
+			build:   func(b *BatchBuilder) *BatchBuilder { return b },
+			message: "at least one entry",
+		},
+		{
+			name: "unbalanced",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				return b.Debit("e1", "a", eur(1000), "").Credit("e2", "b", eur(900), "")
+			},
+			message: "balanced in EUR",
+		},
+		{
+			name: "second currency unbalanced",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				return b.Debit("e1", "a", eur(1000), "").Credit("e2", "b", eur(1000), "").
+					Debit("e3", "c", usd(500), "").Credit("e4", "d", usd(499), "")
+			},
+			message: "balanced in USD",
+		},
+		{
+			name: "missing fx leg",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				return b.Debit("e1", "eur-wallet", eur(1000), "").Credit("e2", "usd-wallet", usd(1090), "")
+			},
+			message: "must be balanced in",
+		},
+		{
+			name: "no entries in the batch currency",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				return b.Debit("e1", "a", usd(1000), "").Credit("e2", "b", usd(1000), "")
+			},
+			message: "entries in its currency EUR",
+		},
+		{
+			name: "fx leg off the rate",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				leg := fxTestLeg()
+				leg.CounterAmount = usd(1000)
+				return b.Debit("e1", "eur-wallet", eur(1000), "").FX(leg, "e2", "e3", "e4").Credit("e5", "usd-wallet", usd(1000), "")
+			},
+			message: "do not match the rate",
+		},
+		{
+			name: "fx leg in one currency",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				leg := fxTestLeg()
+				leg.CounterAmount = eur(1090)
+				return b.Debit("e1", "eur-wallet", eur(1000), "").FX(leg, "e2", "e3", "e4")
+			},
+			message: "currencies must differ",
+		},
+		{
+			name: "entry without currency",
+			build: func(b *BatchBuilder) *BatchBuilder {
+				return b.Debit("e1", "a", money.Money{AmountMinor: 1000}, "").Credit("e2", "b", eur(1000), "")
+			},
+			message: "currency is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch, err := tt.build(NewBatchBuilder("batch1", "tenant1", SourceTypePayment, money.EUR)).Build()
+			if err == nil {
+				t.Fatalf("built batch %+v, want an error", batch)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not mention %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestReversalAmountsMultiCurrency(t *testing.T) {
+	batch := fxTestBatch(t)
+	if err := batch.Post("user1"); err != nil {
+		t.Fatalf("posting batch: %v", err)
+	}
+
+	// Half of the EUR total takes half of the USD side with it
+	amounts, err := batch.ReversalAmounts(500)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := []int64{500, 500, 550, 5, 545}; !reflect.DeepEqual(amounts, want) {
+		t.Errorf("amounts %v, want %v", amounts, want)
+	}
+
+	sides := make(map[money.Currency]map[EntryType]int64)
+	for i, entry := range batch.Entries {
+		if sides[entry.Amount.Currency] == nil {
+			sides[entry.Amount.Currency] = make(map[EntryType]int64)
+		}
+		sides[entry.Amount.Currency][entry.EntryType] += amounts[i]
+	}
+	for currency, s := range sides {
+		if s[EntryTypeDebit] != s[EntryTypeCredit] {
+			t.Errorf("reversal of %s debits %d but credits %d", currency, s[EntryTypeDebit], s[EntryTypeCredit])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// FXLeg records a currency conversion within a multi-currency batch
+type FXLeg struct {
+	ID                       string      `json:"id"`
+	BatchID                  string      `json:"batch_id"`
+	SourceAmount             money.Money `json:"source_amount"`
+	CounterAmount            money.Money `json:"counter_amount"`
+	Rate                     string      `json:"rate"` // Counter units per source unit, in major units
+	SourcePositionAccountID  string      `json:"source_position_account_id"`
+	CounterPositionAccountID string      `json:"counter_position_account_id"`
+	RevenueAccountID         string      `json:"revenue_account_id,omitempty"`
+	RevenueAmount            int64       `json:"revenue_amount,omitempty"` // In the counter currency
+	CreatedAt                time.Time   `json:"created_at"`
+}
+
+// Validate checks the leg is a well-formed conversion between two currencies.
+// The counter amount plus revenue must equal the source amount at the given rate
+// to within one minor unit of the counter currency.
+func (l *FXLeg) Validate() error {
+	if l.ID == "" {
+		return errors.New("fx leg id is required")
+	}
+	if l.SourceAmount.AmountMinor <= 0 || l.CounterAmount.AmountMinor <= 0 {
+		return errors.New("fx leg amounts must be positive")
+	}
+	if l.SourceAmount.Currency == l.CounterAmount.Currency {
+		return errors.New("fx leg currencies must differ")
+	}
+	if l.SourcePositionAccountID == "" || l.CounterPositionAccountID == "" {
+		return errors.New("fx leg position accounts are required")
+	}
+	if l.RevenueAmount < 0 {
+		return errors.New("fx revenue cannot be negative")
+	}
+	if l.RevenueAmount > 0 && l.RevenueAccountID == "" {
+		return errors.New("fx revenue account is required when revenue is recorded")
+	}
+
+	rate, ok := new(big.Rat).SetString(l.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return errors.New("fx rate must be a positive decimal")
+	}
+
+	// Convert the source amount into counter minor units at the given rate
+	expected := new(big.Rat).Mul(new(big.Rat).SetInt64(l.SourceAmount.AmountMinor), rate)
+	expected.Mul(expected, minorUnitScale(l.CounterAmount.Currency))
+	expected.Quo(expected, minorUnitScale(l.SourceAmount.Currency))
+
+	actual := new(big.Rat).SetInt64(l.CounterAmount.AmountMinor + l.RevenueAmount)
+	diff := new(big.Rat).Sub(expected, actual)
+	if diff.Abs(diff).Cmp(big.NewRat(1, 1)) > 0 {
+		return errors.New("fx leg amounts do not match the rate")
+	}
+
+	return nil
+}
+
+// minorUnitScale returns 10^minor units of a currency, defaulting to 2 decimals
+func minorUnitScale(currency money.Currency) *big.Rat {
+	units := 2
+	if info, ok := money.GetCurrencyInfo(currency); ok {
+		units = info.MinorUnits
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(units)), nil))
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Limits on the metadata of an account or batch
+const (
+	MaxMetadataKeys        = 50
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 500
+)
+
+// ErrInvalidMetadata is returned for metadata beyond the limits
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// ValidateMetadata checks metadata against the limits on its keys and values
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataKeys {
+		return fmt.Errorf("%w: at most %d keys are allowed", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+	for key, value := range metadata {
+		if key == "" || len(key) > MaxMetadataKeyLength {
+			return fmt.Errorf("%w: keys must be 1 to %d bytes long", ErrInvalidMetadata, MaxMetadataKeyLength)
+		}
+		if len(value) > MaxMetadataValueLength {
+			return fmt.Errorf("%w: value of %q is longer than %d bytes", ErrInvalidMetadata, key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// MergeMetadata applies changes to metadata in place: a key changed to nil is
+// removed and any other is set
+func MergeMetadata(metadata map[string]string, changes map[string]*string) map[string]string {
+	if metadata == nil {
+		metadata = make(map[string]string, len(changes))
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(metadata, key)
+		} else {
+			metadata[key] = *value
+		}
+	}
+	return metadata
+}
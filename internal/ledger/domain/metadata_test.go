@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	manyKeys := func(n int) map[string]string {
+		m := make(map[string]string, n)
+		for i := 0; i < n; i++ {
+			m[fmt.Sprintf("key%d", i)] = "v"
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		valid    bool
+	}{
+		{name: "nil", valid: true},
+		{name: "empty", metadata: map[string]string{}, valid: true},
+		{name: "empty value", metadata: map[string]string{"k": ""}, valid: true},
+		{name: "most keys", metadata: manyKeys(MaxMetadataKeys), valid: true},
+		{name: "too many keys", metadata: manyKeys(MaxMetadataKeys + 1)},
+		{name: "empty key", metadata: map[string]string{"": "v"}},
+		{name: "longest key", metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength): "v"}, valid: true},
+		{name: "key too long", metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "v"}},
+		{name: "longest value", metadata: map[string]string{"k": strings.Repeat("v", MaxMetadataValueLength)}, valid: true},
+		{name: "value too long", metadata: map[string]string{"k": strings.Repeat("v", MaxMetadataValueLength+1)}},
+		{name: "lengths in bytes", metadata: map[string]string{"k": strings.Repeat("é", MaxMetadataValueLength/2+1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.metadata)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidMetadata) {
+				t.Errorf("error %v, want %v", err, ErrInvalidMetadata)
+			}
+		})
+	}
+}
+
+func TestMergeMetadata(t *testing.T) {
+	value := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		changes  map[string]*string
+		want     map[string]string
+	}{
+		{
+			name:     "set",
+			metadata: map[string]string{"a": "1"},
+			changes:  map[string]*string{"b": value("2")},
+			want:     map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:     "replace",
+			metadata: map[string]string{"a": "1"},
+			changes:  map[string]*string{"a": value("2")},
+			want:     map[string]string{"a": "2"},
+		},
+		{
+			name:     "nil deletes",
+			metadata: map[string]string{"a": "1", "b": "2"},
+			changes:  map[string]*string{"a": nil},
+			want:     map[string]string{"b": "2"},
+		},
+		{
+			name:     "delete missing key",
+			metadata: map[string]string{"a": "1"},
+			changes:  map[string]*string{"z": nil},
+			want:     map[string]string{"a": "1"},
+		},
+		{
+			name:     "empty string kept",
+			metadata: map[string]string{"a": "1"},
+			changes:  map[string]*string{"a": value("")},
+			want:     map[string]string{"a": ""},
+		},
+		{
+			name:     "set, replace and delete",
+			metadata: map[string]string{"a": "1", "b": "2", "c": "3"},
+			changes:  map[string]*string{"a": nil, "b": value("20"), "d": value("4")},
+			want:     map[string]string{"b": "20", "c": "3", "d": "4"},
+		},
+		{
+			name:    "into nil",
+			changes: map[string]*string{"a": value("1"), "b": nil},
+			want:    map[string]string{"a": "1"},
+		},
+		{
+			name:     "no changes",
+			metadata: map[string]string{"a": "1"},
+			want:     map[string]string{"a": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeMetadata(tt.metadata, tt.changes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged %v, want %v", got, tt.want)
+			}
+			if tt.metadata != nil && !reflect.DeepEqual(tt.metadata, tt.want) {
+				t.Errorf("metadata %v not changed in place", tt.metadata)
+			}
+		})
+	}
+}
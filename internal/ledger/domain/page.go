@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// AccountCursor is the position after an account in (code, id) order
+type AccountCursor struct {
+	Code string `json:"c"`
+	ID   string `json:"i"`
+}
+
+// AccountPage is a page of accounts in (code, id) order
+type AccountPage struct {
+	Accounts []*Account
+	Next     *AccountCursor // Where the next page starts; nil on the last page
+	Total    *int64         // Number of matching accounts, when asked for
+}
+
+// BatchCursor is the position after a batch in (created_at, id) order. A cursor
+// keeps the direction of the listing it came from.
+type BatchCursor struct {
+	CreatedAt  time.Time `json:"t"`
+	ID         string    `json:"i"`
+	Descending bool      `json:"d,omitempty"`
+}
+
+// BatchPage is a page of batches, without their entries, in (created_at, id) order
+type BatchPage struct {
+	Batches []*Batch
+	Next    *BatchCursor // Where the next page starts; nil on the last page
+	Total   *int64       // Number of matching batches, when asked for
+}
+
+// EntryCursor is the position after an entry in (created_at, id) order. A cursor
+// keeps the direction of the listing it came from.
+type EntryCursor struct {
+	CreatedAt  time.Time `json:"t"`
+	ID         string    `json:"i"`
+	Descending bool      `json:"d,omitempty"`
+}
+
+// EntryPage is a page of an account's entries in (created_at, id) order
+type EntryPage struct {
+	Entries []*Entry
+	Next    *EntryCursor // Where the next page starts; nil on the last page
+	Total   *int64       // Number of the account's entries, when asked for
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// PeriodStatus represents the state of an accounting period
+type PeriodStatus string
+
+const (
+	PeriodStatusOpen    PeriodStatus = "open"
+	PeriodStatusClosing PeriodStatus = "closing"
+	PeriodStatusClosed  PeriodStatus = "closed"
+)
+
+// Period audit actions
+const (
+	PeriodActionStartClose = "start_close"
+	PeriodActionClose      = "close"
+	PeriodActionReopen     = "reopen"
+)
+
+// RetainedEarningsCode is the system account closing entries sweep into
+const RetainedEarningsCode = "3000"
+
+// Period errors
+var (
+	ErrPeriodClosed    = errors.New("accounting period is closed")
+	ErrPeriodNotEnded  = errors.New("accounting period has not ended yet")
+	ErrPeriodNotClosed = errors.New("accounting period is not closed")
+)
+
+// Period is a monthly accounting period of a tenant
+type Period struct {
+	ID             string       `json:"id"`
+	TenantID       string       `json:"tenant_id"`
+	PeriodStart    time.Time    `json:"period_start"`
+	PeriodEnd      time.Time    `json:"period_end"`
+	Status         PeriodStatus `json:"status"`
+	ClosedAt       *time.Time   `json:"closed_at,omitempty"`
+	ClosedBy       *string      `json:"closed_by,omitempty"`
+	ClosingBatchID *string      `json:"closing_batch_id,omitempty"`
+	ReopenedAt     *time.Time   `json:"reopened_at,omitempty"`
+	ReopenedBy     *string      `json:"reopened_by,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// PeriodAuditEntry records a change of a period's status
+type PeriodAuditEntry struct {
+	ID         string       `json:"id"`
+	PeriodID   string       `json:"period_id"`
+	TenantID   string       `json:"tenant_id"`
+	Action     string       `json:"action"`
+	FromStatus PeriodStatus `json:"from_status"`
+	ToStatus   PeriodStatus `json:"to_status"`
+	UserID     *string      `json:"user_id,omitempty"`
+	Reason     string       `json:"reason,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// NewPeriod creates the open monthly period containing day
+func NewPeriod(id, tenantID string, day time.Time) *Period {
+	start, end := PeriodBounds(PeriodTypeMonthly, day)
+	now := time.Now().UTC()
+	return &Period{
+		ID:          id,
+		TenantID:    tenantID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Status:      PeriodStatusOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// EndInstant returns the last instant of the period
+func (p *Period) EndInstant() time.Time {
+	return p.PeriodEnd.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartClose moves an open period to closing so nothing more can be posted into
+// it. A period that is already closing may be closed again after a failed run.
+func (p *Period) StartClose(now time.Time) error {
+	switch p.Status {
+	case PeriodStatusClosed:
+		return ErrPeriodClosed
+	case PeriodStatusClosing:
+		return nil
+	}
+
+	if !now.After(p.EndInstant()) {
+		return ErrPeriodNotEnded
+	}
+
+	p.Status = PeriodStatusClosing
+	p.UpdatedAt = now
+	return nil
+}
+
+// Close completes the close of a closing period
+func (p *Period) Close(userID string, closingBatchID *string) error {
+	if p.Status == PeriodStatusClosed {
+		return ErrPeriodClosed
+	}
+	if p.Status != PeriodStatusClosing {
+		return errors.New("period close has not been started")
+	}
+
+	now := time.Now().UTC()
+	p.Status = PeriodStatusClosed
+	p.ClosedAt = &now
+	if userID != "" {
+		p.ClosedBy = &userID
+	}
+	if closingBatchID != nil {
+		p.ClosingBatchID = closingBatchID
+	}
+	p.UpdatedAt = now
+	return nil
+}
+
+// Reopen moves a closed period back to open
+func (p *Period) Reopen(userID string) error {
+	if p.Status != PeriodStatusClosed {
+		return ErrPeriodNotClosed
+	}
+
+	now := time.Now().UTC()
+	p.Status = PeriodStatusOpen
+	p.ReopenedAt = &now
+	if userID != "" {
+		p.ReopenedBy = &userID
+	}
+	p.UpdatedAt = now
+	return nil
+}
+
+// AllowsPosting returns ErrPeriodClosed unless batches of the source type may be
+// posted into the period. Only the closing batch may post into a closing period.
+func (p *Period) AllowsPosting(sourceType SourceType) error {
+	switch p.Status {
+	case PeriodStatusOpen:
+		return nil
+	case PeriodStatusClosing:
+		if sourceType == SourceTypePeriodClose {
+			return nil
+		}
+	}
+	return ErrPeriodClosed
+}
+
+// AccountingDate returns the date a batch is booked on for period checks
+func (batch *Batch) AccountingDate() time.Time {
+	return batch.EffectiveDate
+}
+
+// RetainedEarningsCodes returns the account codes tried, in order, for the
+// retained earnings account of a currency
+func RetainedEarningsCodes(currency money.Currency) []string {
+	return []string{RetainedEarningsCode, RetainedEarningsCode + "-" + string(currency)}
+}
+
+// ClosingEntry returns the entry type and amount that take balance (signed by the
+// account's normal side) back to zero
+func ClosingEntry(normalBalance NormalBalance, balance int64) (EntryType, int64) {
+	normal := EntryTypeDebit
+	opposite := EntryTypeCredit
+	if normalBalance == NormalBalanceCredit {
+		normal, opposite = opposite, normal
+	}
+
+	if balance > 0 {
+		return opposite, balance
+	}
+	return normal, -balance
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// periodTestPeriod returns a period of March 2026 in status
+func periodTestPeriod(status PeriodStatus) *Period {
+	p := NewPeriod("period1", "tenant1", time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC))
+	p.Status = status
+	return p
+}
+
+func TestNewPeriod(t *testing.T) {
+	p := NewPeriod("period1", "tenant1", time.Date(2026, 3, 31, 23, 30, 0, 0, time.FixedZone("EST", -5*3600)))
+
+	// 23:30 EST on March 31st is already April in UTC
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC); !p.PeriodStart.Equal(want) {
+		t.Errorf("start %s, want %s", p.PeriodStart, want)
+	}
+	if want := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC); !p.PeriodEnd.Equal(want) {
+		t.Errorf("end %s, want %s", p.PeriodEnd, want)
+	}
+	if want := time.Date(2026, 4, 30, 23, 59, 59, 999999999, time.UTC); !p.EndInstant().Equal(want) {
+		t.Errorf("end instant %s, want %s", p.EndInstant(), want)
+	}
+	if p.Status != PeriodStatusOpen {
+		t.Errorf("status %s, want %s", p.Status, PeriodStatusOpen)
+	}
+}
+
+func TestPeriodStartClose(t *testing.T) {
+	afterEnd := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		status PeriodStatus
+		now    time.Time
+		err    error
+		want   PeriodStatus
+	}{
+		{name: "open after end", status: PeriodStatusOpen, now: afterEnd, want: PeriodStatusClosing},
+		{name: "open on last instant", status: PeriodStatusOpen, now: afterEnd.Add(-time.Nanosecond), err: ErrPeriodNotEnded, want: PeriodStatusOpen},
+		{name: "open mid period", status: PeriodStatusOpen, now: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), err: ErrPeriodNotEnded, want: PeriodStatusOpen},
+		{name: "closing again", status: PeriodStatusClosing, now: afterEnd, want: PeriodStatusClosing},
+		{name: "closed", status: PeriodStatusClosed, now: afterEnd, err: ErrPeriodClosed, want: PeriodStatusClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := periodTestPeriod(tt.status)
+			if err := p.StartClose(tt.now); !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want %v", err, tt.err)
+			}
+			if p.Status != tt.want {
+				t.Errorf("status %s, want %s", p.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodClose(t *testing.T) {
+	closingBatchID := "batch1"
+
+	tests := []struct {
+		name   string
+		status PeriodStatus
+		fails  bool
+		err    error // Checked when set
+	}{
+		{name: "closing", status: PeriodStatusClosing},
+		{name: "open", status: PeriodStatusOpen, fails: true},
+		{name: "closed", status: PeriodStatusClosed, fails: true, err: ErrPeriodClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := periodTestPeriod(tt.status)
+			err := p.Close("user1", &closingBatchID)
+			if tt.fails {
+				if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+					t.Fatalf("error %v, want %v", err, tt.err)
+				}
+				if p.Status != tt.status || p.ClosedAt != nil {
+					t.Errorf("failed close changed the period to %s", p.Status)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if p.Status != PeriodStatusClosed {
+				t.Errorf("status %s, want %s", p.Status, PeriodStatusClosed)
+			}
+			if p.ClosedAt == nil || p.ClosedBy == nil || *p.ClosedBy != "user1" {
+				t.Errorf("close not recorded: at %v by %v", p.ClosedAt, p.ClosedBy)
+			}
+			if p.ClosingBatchID == nil || *p.ClosingBatchID != closingBatchID {
+				t.Errorf("closing batch %v, want %s", p.ClosingBatchID, closingBatchID)
+			}
+		})
+	}
+}
+
+func TestPeriodReopen(t *testing.T) {
+	tests := []struct {
+		name   string
+		status PeriodStatus
+		err    error
+	}{
+		{name: "closed", status: PeriodStatusClosed},
+		{name: "open", status: PeriodStatusOpen, err: ErrPeriodNotClosed},
+		{name: "closing", status: PeriodStatusClosing, err: ErrPeriodNotClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := periodTestPeriod(tt.status)
+			if err := p.Reopen("admin1"); !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				if p.Status != tt.status || p.ReopenedAt != nil {
+					t.Errorf("failed reopen changed the period to %s", p.Status)
+				}
+				return
+			}
+
+			if p.Status != PeriodStatusOpen {
+				t.Errorf("status %s, want %s", p.Status, PeriodStatusOpen)
+			}
+			if p.ReopenedAt == nil || p.ReopenedBy == nil || *p.ReopenedBy != "admin1" {
+				t.Errorf("reopen not recorded: at %v by %v", p.ReopenedAt, p.ReopenedBy)
+			}
+		})
+	}
+}
+
+func TestPeriodCloseCycle(t *testing.T) {
+	p := periodTestPeriod(PeriodStatusOpen)
+	now := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name string
+		step func() error
+		want PeriodStatus
+	}{
+		{name: "start close", step: func() error { return p.StartClose(now) }, want: PeriodStatusClosing},
+		{name: "close", step: func() error { return p.Close("user1", nil) }, want: PeriodStatusClosed},
+		{name: "reopen", step: func() error { return p.Reopen("admin1") }, want: PeriodStatusOpen},
+		{name: "start close again", step: func() error { return p.StartClose(now) }, want: PeriodStatusClosing},
+		{name: "close again", step: func() error { return p.Close("user1", nil) }, want: PeriodStatusClosed},
+	}
+
+	for _, s := range steps {
+		if err := s.step(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if p.Status != s.want {
+			t.Fatalf("%s: status %s, want %s", s.name, p.Status, s.want)
+		}
+	}
+}
+
+func TestPeriodAllowsPosting(t *testing.T) {
+	tests := []struct {
+		status     PeriodStatus
+		sourceType SourceType
+		allowed    bool
+	}{
+		{status: PeriodStatusOpen, sourceType: SourceTypeDeposit, allowed: true},
+		{status: PeriodStatusOpen, sourceType: SourceTypePeriodClose, allowed: true},
+		{status: PeriodStatusClosing, sourceType: SourceTypeDeposit},
+		{status: PeriodStatusClosing, sourceType: SourceTypePeriodClose, allowed: true},
+		{status: PeriodStatusClosed, sourceType: SourceTypeDeposit},
+		{status: PeriodStatusClosed, sourceType: SourceTypePeriodClose},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status)+"/"+string(tt.sourceType), func(t *testing.T) {
+			err := periodTestPeriod(tt.status).AllowsPosting(tt.sourceType)
+			if tt.allowed && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrPeriodClosed) {
+				t.Errorf("error %v, want %v", err, ErrPeriodClosed)
+			}
+		})
+	}
+}
+
+func TestClosingEntry(t *testing.T) {
+	tests := []struct {
+		name          string
+		normalBalance NormalBalance
+		balance       int64
+		entryType     EntryType
+		amount        int64
+	}{
+		{name: "debit normal", normalBalance: NormalBalanceDebit, balance: 500, entryType: EntryTypeCredit, amount: 500},
+		{name: "debit normal contra", normalBalance: NormalBalanceDebit, balance: -500, entryType: EntryTypeDebit, amount: 500},
+		{name: "credit normal", normalBalance: NormalBalanceCredit, balance: 500, entryType: EntryTypeDebit, amount: 500},
+		{name: "credit normal contra", normalBalance: NormalBalanceCredit, balance: -500, entryType: EntryTypeCredit, amount: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entryType, amount := ClosingEntry(tt.normalBalance, tt.balance)
+			if entryType != tt.entryType || amount != tt.amount {
+				t.Errorf("closing entry %s %d, want %s %d", entryType, amount, tt.entryType, tt.amount)
+			}
+		})
+	}
+}
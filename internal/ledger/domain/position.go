@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"time"
+)
+
+// Position period types
+const (
+	PeriodTypeDaily   = "daily"
+	PeriodTypeMonthly = "monthly"
+	PeriodTypeYearly  = "yearly"
+)
+
+// PeriodTypes lists the period types positions are kept for
+var PeriodTypes = []string{PeriodTypeDaily, PeriodTypeMonthly, PeriodTypeYearly}
+
+// IsValidPeriodType returns whether positions are kept for the period type
+func IsValidPeriodType(periodType string) bool {
+	for _, pt := range PeriodTypes {
+		if pt == periodType {
+			return true
+		}
+	}
+	return false
+}
+
+// PeriodBounds returns the first and last day (UTC) of the period containing day
+func PeriodBounds(periodType string, day time.Time) (time.Time, time.Time) {
+	day = day.UTC()
+	switch periodType {
+	case PeriodTypeMonthly:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	case PeriodTypeYearly:
+		start := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start
+	}
+}
+
+// DateRange is an inclusive range of days
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// DayTotal holds the posted activity of an account on one day
+type DayTotal struct {
+	Day        time.Time
+	Debits     int64
+	Credits    int64
+	EntryCount int
+}
+
+// Settled returns whether the position's closing balance can open the period
+// after it: the position is not stale and was computed after its period ended,
+// so no posting dated in the period can be missing from it
+func (p *Position) Settled() bool {
+	return !p.Stale && !p.UpdatedAt.Before(p.PeriodEnd.AddDate(0, 0, 1))
+}
+
+// BuildPositions computes the positions of every period type whose period overlaps
+// the days [from, to], with days counted by basis. opening is the account balance
+// at the start of the year containing from, and days holds the daily activity from
+// then until the end of the year containing to. Positions are returned without IDs.
+func BuildPositions(account *Account, basis DateBasis, opening int64, days []DayTotal, from, to time.Time) []*Position {
+	windowStart, _ := PeriodBounds(PeriodTypeYearly, from)
+	_, windowEnd := PeriodBounds(PeriodTypeYearly, to)
+	from, _ = PeriodBounds(PeriodTypeDaily, from)
+	to, _ = PeriodBounds(PeriodTypeDaily, to)
+
+	activity := make(map[time.Time]DayTotal, len(days))
+	for _, d := range days {
+		day, _ := PeriodBounds(PeriodTypeDaily, d.Day)
+		activity[day] = d
+	}
+
+	var positions []*Position
+	current := make(map[string]*Position, len(PeriodTypes))
+	balance := opening
+
+	flush := func(p *Position) {
+		if p != nil && !p.PeriodEnd.Before(from) && !p.PeriodStart.After(to) {
+			positions = append(positions, p)
+		}
+	}
+
+	for day := windowStart; !day.After(windowEnd); day = day.AddDate(0, 0, 1) {
+		for _, periodType := range PeriodTypes {
+			start, end := PeriodBounds(periodType, day)
+			if p := current[periodType]; p == nil || !p.PeriodStart.Equal(start) {
+				flush(p)
+				current[periodType] = &Position{
+					TenantID:       account.TenantID,
+					AccountID:      account.ID,
+					PeriodType:     periodType,
+					PeriodStart:    start,
+					PeriodEnd:      end,
+					OpeningBalance: balance,
+					ClosingBalance: balance,
+					Currency:       account.Currency,
+					DateBasis:      basis,
+				}
+			}
+		}
+
+		d, ok := activity[day]
+		if !ok {
+			continue
+		}
+
+		if account.NormalBalance == NormalBalanceDebit {
+			balance += d.Debits - d.Credits
+		} else {
+			balance += d.Credits - d.Debits
+		}
+
+		for _, p := range current {
+			p.DebitTotal += d.Debits
+			p.CreditTotal += d.Credits
+			p.EntryCount += d.EntryCount
+			p.ClosingBalance = balance
+		}
+	}
+
+	for _, periodType := range PeriodTypes {
+		flush(current[periodType])
+	}
+
+	return positions
+}
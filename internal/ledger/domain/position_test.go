@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPositionSettled(t *testing.T) {
+	start, end := PeriodBounds(PeriodTypeYearly, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	yearEnd := end.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name      string
+		updatedAt time.Time
+		stale     bool
+		settled   bool
+	}{
+		{name: "computed after the year", updatedAt: yearEnd.Add(time.Hour), settled: true},
+		{name: "computed as the year ended", updatedAt: yearEnd, settled: true},
+		{name: "computed on the last day", updatedAt: yearEnd.Add(-time.Second)},
+		{name: "computed mid year", updatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "stale", updatedAt: yearEnd.AddDate(0, 1, 0), stale: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Position{
+				PeriodType:  PeriodTypeYearly,
+				PeriodStart: start,
+				PeriodEnd:   end,
+				Stale:       tt.stale,
+				UpdatedAt:   tt.updatedAt,
+			}
+			if settled := p.Settled(); settled != tt.settled {
+				t.Errorf("settled: %v, want %v", settled, tt.settled)
+			}
+		})
+	}
+}
+
+func TestBuildPositionsOpening(t *testing.T) {
+	account := &Account{ID: "acc1", TenantID: "tenant1", NormalBalance: NormalBalanceDebit}
+	from := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	days := []DayTotal{
+		{Day: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Debits: 100, EntryCount: 1},
+		{Day: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Credits: 30, EntryCount: 1},
+	}
+
+	positions := BuildPositions(account, DateBasisPosting, 1000, days, from, from)
+
+	want := map[string][2]int64{ // Opening and closing balance per period type
+		PeriodTypeDaily:   {1100, 1070},
+		PeriodTypeMonthly: {1000, 1070},
+		PeriodTypeYearly:  {1000, 1070},
+	}
+	if len(positions) != len(want) {
+		t.Fatalf("got %d positions, want %d", len(positions), len(want))
+	}
+	for _, p := range positions {
+		w := want[p.PeriodType]
+		if p.OpeningBalance != w[0] || p.ClosingBalance != w[1] {
+			t.Errorf("%s position opens at %d and closes at %d, want %d and %d", p.PeriodType, p.OpeningBalance, p.ClosingBalance, w[0], w[1])
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// AccountBalance is an account with its posted balance, signed by its normal side
+type AccountBalance struct {
+	Account *Account
+	Balance int64
+}
+
+// TrialBalanceLine is one account's balance in the debit or credit column
+type TrialBalanceLine struct {
+	AccountID     string         `json:"account_id"`
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	AccountType   AccountType    `json:"account_type"`
+	NormalBalance NormalBalance  `json:"normal_balance"`
+	Currency      money.Currency `json:"currency"`
+	Debit         int64          `json:"debit"`
+	Credit        int64          `json:"credit"`
+}
+
+// TrialBalance lists the balances of all postable accounts in one currency
+type TrialBalance struct {
+	TenantID     string             `json:"tenant_id"`
+	Currency     money.Currency     `json:"currency"`
+	AsOf         time.Time          `json:"as_of"`
+	Lines        []TrialBalanceLine `json:"lines"`
+	TotalDebits  int64              `json:"total_debits"`
+	TotalCredits int64              `json:"total_credits"`
+	Balanced     bool               `json:"balanced"`
+}
+
+// NewTrialBalance builds trial balances, one per currency, from account balances.
+// Placeholder accounts only group other accounts and are left out. A balance on
+// the account's normal side goes in that column; a contra balance in the other.
+func NewTrialBalance(tenantID string, asOf time.Time, balances []*AccountBalance) []*TrialBalance {
+	var reports []*TrialBalance
+	byCurrency := make(map[money.Currency]*TrialBalance)
+
+	for _, ab := range balances {
+		account := ab.Account
+		if account.IsPlaceholder {
+			continue
+		}
+
+		tb, ok := byCurrency[account.Currency]
+		if !ok {
+			tb = &TrialBalance{
+				TenantID: tenantID,
+				Currency: account.Currency,
+				AsOf:     asOf,
+				Lines:    make([]TrialBalanceLine, 0),
+			}
+			byCurrency[account.Currency] = tb
+			reports = append(reports, tb)
+		}
+
+		line := TrialBalanceLine{
+			AccountID:     account.ID,
+			Code:          account.Code,
+			Name:          account.Name,
+			AccountType:   account.AccountType,
+			NormalBalance: account.NormalBalance,
+			Currency:      account.Currency,
+		}
+
+		debitSide := (account.NormalBalance == NormalBalanceDebit) == (ab.Balance >= 0)
+		amount := ab.Balance
+		if amount < 0 {
+			amount = -amount
+		}
+		if debitSide {
+			line.Debit = amount
+		} else {
+			line.Credit = amount
+		}
+
+		tb.Lines = append(tb.Lines, line)
+		tb.TotalDebits += line.Debit
+		tb.TotalCredits += line.Credit
+	}
+
+	for _, tb := range reports {
+		tb.Balanced = tb.TotalDebits == tb.TotalCredits
+	}
+
+	return reports
+}
+
+// ReportLine is an account in a hierarchical report. Balance is the account's own
+// balance; Total rolls up the balances of all its descendants in the section.
+type ReportLine struct {
+	AccountID     string  `json:"account_id,omitempty"`
+	ParentID      *string `json:"parent_id,omitempty"`
+	Code          string  `json:"code,omitempty"`
+	Name          string  `json:"name"`
+	Path          string  `json:"path,omitempty"`
+	Depth         int     `json:"depth"`
+	IsPlaceholder bool    `json:"is_placeholder"`
+	Balance       int64   `json:"balance"`
+	Total         int64   `json:"total"`
+}
+
+// ReportSection groups the accounts of one type in hierarchy order
+type ReportSection struct {
+	AccountType AccountType  `json:"account_type"`
+	Lines       []ReportLine `json:"lines"`
+	Total       int64        `json:"total"`
+}
+
+// BalanceSheet reports assets, liabilities and equity at a point in time.
+// Revenue and expense not yet closed into retained earnings are shown in equity
+// as current period earnings.
+type BalanceSheet struct {
+	TenantID                  string         `json:"tenant_id"`
+	Currency                  money.Currency `json:"currency"`
+	AsOf                      time.Time      `json:"as_of"`
+	Assets                    ReportSection  `json:"assets"`
+	Liabilities               ReportSection  `json:"liabilities"`
+	Equity                    ReportSection  `json:"equity"`
+	CurrentEarnings           int64          `json:"current_earnings"`
+	TotalLiabilitiesAndEquity int64          `json:"total_liabilities_and_equity"`
+	Balanced                  bool           `json:"balanced"`
+}
+
+// IncomeStatement reports revenue and expenses over a date range
+type IncomeStatement struct {
+	TenantID  string         `json:"tenant_id"`
+	Currency  money.Currency `json:"currency"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Revenue   ReportSection  `json:"revenue"`
+	Expenses  ReportSection  `json:"expenses"`
+	NetIncome int64          `json:"net_income"`
+}
+
+// CurrentEarningsName is the name of the synthetic equity line for unclosed earnings
+const CurrentEarningsName = "Current Period Earnings"
+
+// NewBalanceSheets builds balance sheets, one per currency, from account balances
+func NewBalanceSheets(tenantID string, asOf time.Time, balances []*AccountBalance) []*BalanceSheet {
+	currencies, byCurrency := groupByCurrency(balances)
+
+	sheets := make([]*BalanceSheet, 0, len(currencies))
+	for _, currency := range currencies {
+		accounts := byCurrency[currency]
+
+		sheet := &BalanceSheet{
+			TenantID:    tenantID,
+			Currency:    currency,
+			AsOf:        asOf,
+			Assets:      NewReportSection(AccountTypeAsset, accounts),
+			Liabilities: NewReportSection(AccountTypeLiability, accounts),
+			Equity:      NewReportSection(AccountTypeEquity, accounts),
+		}
+
+		revenue := NewReportSection(AccountTypeRevenue, accounts)
+		expenses := NewReportSection(AccountTypeExpense, accounts)
+		sheet.CurrentEarnings = revenue.Total - expenses.Total
+
+		sheet.Equity.Lines = append(sheet.Equity.Lines, ReportLine{
+			Name:    CurrentEarningsName,
+			Balance: sheet.CurrentEarnings,
+			Total:   sheet.CurrentEarnings,
+		})
+		sheet.Equity.Total += sheet.CurrentEarnings
+
+		sheet.TotalLiabilitiesAndEquity = sheet.Liabilities.Total + sheet.Equity.Total
+		sheet.Balanced = sheet.Assets.Total == sheet.TotalLiabilitiesAndEquity
+
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets
+}
+
+// NewIncomeStatements builds income statements, one per currency, from account
+// balances over the reporting range
+func NewIncomeStatements(tenantID string, from, to time.Time, balances []*AccountBalance) []*IncomeStatement {
+	currencies, byCurrency := groupByCurrency(balances)
+
+	statements := make([]*IncomeStatement, 0, len(currencies))
+	for _, currency := range currencies {
+		accounts := byCurrency[currency]
+
+		statement := &IncomeStatement{
+			TenantID: tenantID,
+			Currency: currency,
+			From:     from,
+			To:       to,
+			Revenue:  NewReportSection(AccountTypeRevenue, accounts),
+			Expenses: NewReportSection(AccountTypeExpense, accounts),
+		}
+		statement.NetIncome = statement.Revenue.Total - statement.Expenses.Total
+
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+// NewReportSection builds the section of one account type in path order, rolling
+// up each account's balance into every ancestor in the section
+func NewReportSection(accountType AccountType, balances []*AccountBalance) ReportSection {
+	section := ReportSection{
+		AccountType: accountType,
+		Lines:       make([]ReportLine, 0),
+	}
+
+	var accounts []*AccountBalance
+	totals := make(map[string]int64)
+	for _, ab := range balances {
+		if ab.Account.AccountType != accountType {
+			continue
+		}
+		accounts = append(accounts, ab)
+		section.Total += ab.Balance
+
+		segments := strings.Split(ab.Account.Path, "/")
+		for i := range segments {
+			totals[strings.Join(segments[:i+1], "/")] += ab.Balance
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Account.Path < accounts[j].Account.Path
+	})
+
+	for _, ab := range accounts {
+		account := ab.Account
+		section.Lines = append(section.Lines, ReportLine{
+			AccountID:     account.ID,
+			ParentID:      account.ParentID,
+			Code:          account.Code,
+			Name:          account.Name,
+			Path:          account.Path,
+			Depth:         strings.Count(account.Path, "/"),
+			IsPlaceholder: account.IsPlaceholder,
+			Balance:       ab.Balance,
+			Total:         totals[account.Path],
+		})
+	}
+
+	return section
+}
+
+// groupByCurrency splits balances by account currency, keeping first-seen order
+func groupByCurrency(balances []*AccountBalance) ([]money.Currency, map[money.Currency][]*AccountBalance) {
+	var currencies []money.Currency
+	byCurrency := make(map[money.Currency][]*AccountBalance)
+	for _, ab := range balances {
+		currency := ab.Account.Currency
+		if _, ok := byCurrency[currency]; !ok {
+			currencies = append(currencies, currency)
+		}
+		byCurrency[currency] = append(byCurrency[currency], ab)
+	}
+	return currencies, byCurrency
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// EntryFilter selects a tenant's entries in a search. Unset fields match every
+// entry; set fields must all match.
+type EntryFilter struct {
+	AccountID   string
+	AccountCode string
+	AccountType *AccountType
+	SourceType  *SourceType
+	SourceID    string
+	Reference   string            // Batch reference, matched exactly
+	Metadata    map[string]string // Pairs the batch's metadata must contain
+	Description string            // Matched case-insensitively within the entry's or batch's description
+	EntryType   *EntryType
+	Currency    money.Currency
+	MinAmount   *int64 // Minor units, inclusive
+	MaxAmount   *int64 // Minor units, inclusive
+	Basis       DateBasis
+	From        *time.Time // Entry date by Basis, inclusive
+	To          *time.Time // Entry date by Basis, inclusive
+}
+
+// BatchFilter selects a tenant's batches in a listing. Unset fields match every
+// batch; set fields must all match.
+type BatchFilter struct {
+	Status     *BatchStatus
+	SourceType *SourceType
+	SourceID   string
+	Reference  string
+	Metadata   map[string]string // Pairs the batch's metadata must contain
+}
+
+// EntryMatch is an entry found by a search, with its account's code and its
+// batch's reference, source and status
+type EntryMatch struct {
+	Entry
+	AccountCode    string      `json:"account_code"`
+	BatchReference string      `json:"batch_reference,omitempty"`
+	SourceType     SourceType  `json:"source_type"`
+	SourceID       string      `json:"source_id,omitempty"`
+	BatchStatus    BatchStatus `json:"batch_status"`
+}
+
+// EntryMatchPage is a page of entries found by a search in (created_at, id) order
+type EntryMatchPage struct {
+	Entries []*EntryMatch
+	Next    *EntryCursor // Where the next page starts; nil on the last page
+	Total   *int64       // Number of matching entries, when asked for
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// Statement is a bank-style statement of one account over a date range: the
+// opening balance, each posted entry with the running balance after it, and the
+// closing balance. Balances are signed by the account's normal side.
+type Statement struct {
+	ID             string           `json:"id"`
+	TenantID       string           `json:"tenant_id"`
+	AccountID      string           `json:"account_id"`
+	AccountCode    string           `json:"account_code"`
+	AccountName    string           `json:"account_name"`
+	NormalBalance  NormalBalance    `json:"normal_balance"`
+	Currency       money.Currency   `json:"currency"`
+	Basis          DateBasis        `json:"basis"` // Which entry date the range and order are by
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance int64            `json:"opening_balance"`
+	ClosingBalance int64            `json:"closing_balance"`
+	TotalDebits    int64            `json:"total_debits"`
+	TotalCredits   int64            `json:"total_credits"`
+	DebitCount     int              `json:"debit_count"`
+	CreditCount    int              `json:"credit_count"`
+	Lines          []*StatementLine `json:"lines"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+}
+
+// StatementLine is one entry of a statement
+type StatementLine struct {
+	EntryID     string     `json:"entry_id"`
+	BatchID     string     `json:"batch_id"`
+	Reference   string     `json:"reference,omitempty"`
+	Description string     `json:"description,omitempty"`
+	SourceType  SourceType `json:"source_type"`
+	SourceID    string     `json:"source_id,omitempty"`
+	EntryType   EntryType  `json:"entry_type"`
+	Amount      int64      `json:"amount"`
+	BookingDate time.Time  `json:"booking_date"` // When the entry was posted
+	ValueDate   time.Time  `json:"value_date"`
+	Balance     int64      `json:"balance"` // Running balance after the entry
+}
+
+// NewStatement builds the statement of an account from its opening balance and
+// the lines of its entries in order, working out the running and closing balances
+func NewStatement(id string, account *Account, basis DateBasis, from, to time.Time, opening int64, lines []*StatementLine) *Statement {
+	st := &Statement{
+		ID:             id,
+		TenantID:       account.TenantID,
+		AccountID:      account.ID,
+		AccountCode:    account.Code,
+		AccountName:    account.Name,
+		NormalBalance:  account.NormalBalance,
+		Currency:       account.Currency,
+		Basis:          basis,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		Lines:          lines,
+		GeneratedAt:    time.Now().UTC(),
+	}
+	if st.Lines == nil {
+		st.Lines = make([]*StatementLine, 0)
+	}
+
+	balance := opening
+	for _, line := range st.Lines {
+		if (line.EntryType == EntryTypeDebit) == (account.NormalBalance == NormalBalanceDebit) {
+			balance += line.Amount
+		} else {
+			balance -= line.Amount
+		}
+		line.Balance = balance
+
+		if line.EntryType == EntryTypeDebit {
+			st.TotalDebits += line.Amount
+			st.DebitCount++
+		} else {
+			st.TotalCredits += line.Amount
+			st.CreditCount++
+		}
+	}
+	st.ClosingBalance = balance
+
+	return st
+}
+
+// BalanceSide returns the side, debit or credit, a balance signed by the
+// statement account's normal side is on
+func (st *Statement) BalanceSide(balance int64) EntryType {
+	debit := st.NormalBalance == NormalBalanceDebit
+	if balance < 0 {
+		debit = !debit
+	}
+	if debit {
+		return EntryTypeDebit
+	}
+	return EntryTypeCredit
+}
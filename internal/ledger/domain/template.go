@@ -0,0 +1,281 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Posting template errors
+var (
+	ErrInvalidTemplate   = errors.New("invalid posting template")
+	ErrTemplateArguments = errors.New("posting template arguments do not match the template")
+)
+
+// templateNamePattern is what template, role and amount names look like
+var templateNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+// PostingTemplate is a tenant's named journal rule, such as wallet_funding_fps.
+// Its lines post amount expressions over named amounts (gross, fee) to named
+// roles (customer_wallet, settlement_float), so that callers supply amounts and
+// accounts by what they are rather than which side and code they go to.
+//
+// Roles maps each role to the code of the account it posts to; a role mapped to
+// "" has no fixed account and must be bound to one when the template is used.
+type PostingTemplate struct {
+	ID          string            `json:"id"`
+	TenantID    string            `json:"tenant_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	SourceType  SourceType        `json:"source_type"`
+	Roles       map[string]string `json:"roles"`
+	Lines       []TemplateLine    `json:"lines"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// TemplateLine is one entry of a posting template
+type TemplateLine struct {
+	Role        string    `json:"role"`
+	EntryType   EntryType `json:"entry_type"`
+	Amount      string    `json:"amount"` // Amount expression, e.g. "gross - fee"
+	Description string    `json:"description,omitempty"`
+}
+
+// TemplateEntry is a line of a template expanded with its arguments. The account
+// is AccountID when the role was bound by the caller, otherwise AccountCode.
+type TemplateEntry struct {
+	Role        string
+	AccountID   string
+	AccountCode string
+	EntryType   EntryType
+	Amount      int64
+	Description string
+}
+
+// Validate checks the template's name, roles and lines
+func (t *PostingTemplate) Validate() error {
+	if !templateNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("%w: name must be lower case letters, digits and underscores", ErrInvalidTemplate)
+	}
+	if t.SourceType == "" {
+		return fmt.Errorf("%w: source type is required", ErrInvalidTemplate)
+	}
+	for role := range t.Roles {
+		if !templateNamePattern.MatchString(role) {
+			return fmt.Errorf("%w: role %q must be lower case letters, digits and underscores", ErrInvalidTemplate, role)
+		}
+	}
+	if len(t.Lines) < 2 {
+		return fmt.Errorf("%w: at least two lines are required", ErrInvalidTemplate)
+	}
+
+	var debits, credits int
+	for i, line := range t.Lines {
+		if _, ok := t.Roles[line.Role]; !ok {
+			return fmt.Errorf("%w: line %d posts to undeclared role %q", ErrInvalidTemplate, i, line.Role)
+		}
+		switch line.EntryType {
+		case EntryTypeDebit:
+			debits++
+		case EntryTypeCredit:
+			credits++
+		default:
+			return fmt.Errorf("%w: line %d entry type must be debit or credit", ErrInvalidTemplate, i)
+		}
+		if _, err := parseAmountExpr(line.Amount); err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrInvalidTemplate, i, err)
+		}
+	}
+	if debits == 0 || credits == 0 {
+		return fmt.Errorf("%w: lines must include a debit and a credit", ErrInvalidTemplate)
+	}
+
+	return nil
+}
+
+// AmountNames returns the names of the amounts the template's lines use, sorted
+func (t *PostingTemplate) AmountNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, line := range t.Lines {
+		terms, err := parseAmountExpr(line.Amount)
+		if err != nil {
+			continue
+		}
+		for _, term := range terms {
+			if term.name != "" && !seen[term.name] {
+				seen[term.name] = true
+				names = append(names, term.name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand works out the template's entries from the accounts bound to its roles
+// (role to account ID) and the named amounts. A bound account takes the place of
+// the role's account code. Every role without a code must be bound and every
+// amount the lines use given; nothing else may be. Lines whose amount comes to
+// zero, such as a fee that was waived, are left out. The entries must balance.
+func (t *PostingTemplate) Expand(accounts map[string]string, amounts map[string]int64) ([]TemplateEntry, error) {
+	for role := range accounts {
+		if _, ok := t.Roles[role]; !ok {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrTemplateArguments, role)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, name := range t.AmountNames() {
+		used[name] = true
+	}
+	for name, amount := range amounts {
+		if !used[name] {
+			return nil, fmt.Errorf("%w: unknown amount %q", ErrTemplateArguments, name)
+		}
+		if amount < 0 {
+			return nil, fmt.Errorf("%w: amount %q must not be negative", ErrTemplateArguments, name)
+		}
+	}
+
+	entries := make([]TemplateEntry, 0, len(t.Lines))
+	var debits, credits int64
+	for i, line := range t.Lines {
+		amount, err := evalAmountExpr(line.Amount, amounts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrTemplateArguments, i, err)
+		}
+		if amount == 0 {
+			continue
+		}
+
+		entry := TemplateEntry{
+			Role:        line.Role,
+			EntryType:   line.EntryType,
+			Amount:      amount,
+			Description: line.Description,
+		}
+		if id := accounts[line.Role]; id != "" {
+			entry.AccountID = id
+		} else if code := t.Roles[line.Role]; code != "" {
+			entry.AccountCode = code
+		} else {
+			return nil, fmt.Errorf("%w: role %q must be bound to an account", ErrTemplateArguments, line.Role)
+		}
+		total := &credits
+		if entry.EntryType == EntryTypeDebit {
+			total = &debits
+		}
+		if *total > math.MaxInt64-amount {
+			return nil, fmt.Errorf("%w: line %d: %s total overflows", ErrTemplateArguments, i, entry.EntryType)
+		}
+		*total += amount
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: every amount comes to zero", ErrTemplateArguments)
+	}
+	if debits != credits {
+		return nil, fmt.Errorf("%w: debits of %d do not equal credits of %d", ErrTemplateArguments, debits, credits)
+	}
+
+	return entries, nil
+}
+
+// amountTerm is one term of an amount expression: a named amount, or a constant
+// in minor units when name is empty
+type amountTerm struct {
+	negative bool
+	name     string
+	value    int64
+}
+
+// parseAmountExpr parses an amount expression: named amounts and whole numbers
+// of minor units added and subtracted, such as "gross - fee" or "fee + 25"
+func parseAmountExpr(expr string) ([]amountTerm, error) {
+	var terms []amountTerm
+	negative := false
+	rest := strings.TrimSpace(expr)
+	if rest == "" {
+		return nil, errors.New("amount expression is empty")
+	}
+
+	for {
+		end := strings.IndexAny(rest, "+- \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		operand := rest[:end]
+
+		term := amountTerm{negative: negative}
+		switch {
+		case operand == "":
+			return nil, fmt.Errorf("amount expression %q is missing an operand", expr)
+		case operand[0] >= '0' && operand[0] <= '9':
+			value, err := strconv.ParseInt(operand, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("amount expression %q has invalid number %q", expr, operand)
+			}
+			term.value = value
+		case templateNamePattern.MatchString(operand):
+			term.name = operand
+		default:
+			return nil, fmt.Errorf("amount expression %q has invalid operand %q", expr, operand)
+		}
+		terms = append(terms, term)
+
+		rest = strings.TrimSpace(rest[end:])
+		if rest == "" {
+			return terms, nil
+		}
+		switch rest[0] {
+		case '+':
+			negative = false
+		case '-':
+			negative = true
+		default:
+			return nil, fmt.Errorf("amount expression %q is missing an operator before %q", expr, rest)
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+// evalAmountExpr evaluates an amount expression over the named amounts; the
+// result must not be negative
+func evalAmountExpr(expr string, amounts map[string]int64) (int64, error) {
+	terms, err := parseAmountExpr(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, term := range terms {
+		value := term.value
+		if term.name != "" {
+			v, ok := amounts[term.name]
+			if !ok {
+				return 0, fmt.Errorf("amount %q is required", term.name)
+			}
+			value = v
+		}
+		if term.negative {
+			value = -value
+		}
+		if (value > 0 && total > math.MaxInt64-value) || (value < 0 && total < math.MinInt64-value) {
+			return 0, fmt.Errorf("amount %q overflows", expr)
+		}
+		total += value
+	}
+
+	if total < 0 {
+		return 0, fmt.Errorf("amount %q comes to %d, which is negative", expr, total)
+	}
+	return total, nil
+}
@@ -0,0 +1,279 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseAmountExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		terms []amountTerm
+		ok    bool
+	}{
+		{expr: "gross", terms: []amountTerm{{name: "gross"}}, ok: true},
+		{expr: "  gross  ", terms: []amountTerm{{name: "gross"}}, ok: true},
+		{expr: "250", terms: []amountTerm{{value: 250}}, ok: true},
+		{expr: "0", terms: []amountTerm{{value: 0}}, ok: true},
+		{expr: "gross - fee", terms: []amountTerm{{name: "gross"}, {name: "fee", negative: true}}, ok: true},
+		{expr: "gross-fee", terms: []amountTerm{{name: "gross"}, {name: "fee", negative: true}}, ok: true},
+		{expr: "fee + 25", terms: []amountTerm{{name: "fee"}, {value: 25}}, ok: true},
+		{
+			expr:  "a - b + c_2\t- 1",
+			terms: []amountTerm{{name: "a"}, {name: "b", negative: true}, {name: "c_2"}, {value: 1, negative: true}},
+			ok:    true,
+		},
+		{expr: "9223372036854775807", terms: []amountTerm{{value: math.MaxInt64}}, ok: true},
+
+		{expr: ""},
+		{expr: "   "},
+		{expr: "-fee"},                 // Leading operator
+		{expr: "+fee"},                 // Leading operator
+		{expr: "gross -"},              // Trailing operator
+		{expr: "gross - - fee"},        // Operator without an operand
+		{expr: "gross fee"},            // Operand without an operator
+		{expr: "gross * 2"},            // Unsupported operator
+		{expr: "Gross"},                // Names are lower case
+		{expr: "_fee"},                 // Names start with a letter
+		{expr: "fee.total"},            // Invalid character
+		{expr: "12abc"},                // Not a number
+		{expr: "1.5"},                  // Amounts are whole minor units
+		{expr: "9223372036854775808"},  // Beyond int64
+		{expr: "99999999999999999999"}, // Beyond int64
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			terms, err := parseAmountExpr(tt.expr)
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("parsed as %+v, want an error", terms)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(terms, tt.terms) {
+				t.Errorf("terms %+v, want %+v", terms, tt.terms)
+			}
+		})
+	}
+}
+
+func TestEvalAmountExpr(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		amounts map[string]int64
+		want    int64
+		ok      bool
+	}{
+		{name: "name", expr: "gross", amounts: map[string]int64{"gross": 1000}, want: 1000, ok: true},
+		{name: "constant", expr: "25", want: 25, ok: true},
+		{name: "difference", expr: "gross - fee", amounts: map[string]int64{"gross": 1000, "fee": 30}, want: 970, ok: true},
+		{name: "sum with constant", expr: "fee + 25", amounts: map[string]int64{"fee": 30}, want: 55, ok: true},
+		{name: "zero", expr: "gross - fee", amounts: map[string]int64{"gross": 30, "fee": 30}, want: 0, ok: true},
+		{name: "name used twice", expr: "a + a - b", amounts: map[string]int64{"a": 5, "b": 3}, want: 7, ok: true},
+		{name: "negative on the way", expr: "a - b + c", amounts: map[string]int64{"a": 1, "b": 5, "c": 10}, want: 6, ok: true},
+		{name: "maximum", expr: "a + b", amounts: map[string]int64{"a": math.MaxInt64 - 1, "b": 1}, want: math.MaxInt64, ok: true},
+		{
+			name:    "below zero and back",
+			expr:    "0 - a + b",
+			amounts: map[string]int64{"a": math.MaxInt64, "b": math.MaxInt64},
+			want:    0,
+			ok:      true,
+		},
+
+		{name: "negative result", expr: "gross - fee", amounts: map[string]int64{"gross": 30, "fee": 31}},
+		{name: "negative constant result", expr: "0 - 1"},
+		{name: "missing amount", expr: "gross - fee", amounts: map[string]int64{"gross": 30}},
+		{name: "invalid expression", expr: "gross -", amounts: map[string]int64{"gross": 30}},
+		{name: "overflow", expr: "a + b", amounts: map[string]int64{"a": math.MaxInt64, "b": 1}},
+		{name: "overflow by constant", expr: "a + 1", amounts: map[string]int64{"a": math.MaxInt64}},
+		{name: "overflow by repetition", expr: "a + a", amounts: map[string]int64{"a": math.MaxInt64/2 + 1}},
+		{
+			name:    "underflow",
+			expr:    "0 - a - b - c",
+			amounts: map[string]int64{"a": math.MaxInt64, "b": 1, "c": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evalAmountExpr(tt.expr, tt.amounts)
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("evaluated to %d, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// fundingTemplate posts gross from the settlement float to a customer wallet
+// bound per posting, less a fee to fee income
+func fundingTemplate() *PostingTemplate {
+	return &PostingTemplate{
+		Name:       "wallet_funding",
+		SourceType: SourceTypeDeposit,
+		Roles: map[string]string{
+			"settlement_float": "1100",
+			"customer_wallet":  "",
+			"fee_income":       "4000",
+		},
+		Lines: []TemplateLine{
+			{Role: "settlement_float", EntryType: EntryTypeDebit, Amount: "gross"},
+			{Role: "customer_wallet", EntryType: EntryTypeCredit, Amount: "gross - fee"},
+			{Role: "fee_income", EntryType: EntryTypeCredit, Amount: "fee"},
+		},
+	}
+}
+
+func TestPostingTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(tmpl *PostingTemplate)
+		ok     bool
+	}{
+		{name: "valid", mutate: func(tmpl *PostingTemplate) {}, ok: true},
+		{name: "invalid name", mutate: func(tmpl *PostingTemplate) { tmpl.Name = "Wallet Funding" }},
+		{name: "no source type", mutate: func(tmpl *PostingTemplate) { tmpl.SourceType = "" }},
+		{name: "invalid role", mutate: func(tmpl *PostingTemplate) { tmpl.Roles["Fee"] = "" }},
+		{name: "one line", mutate: func(tmpl *PostingTemplate) { tmpl.Lines = tmpl.Lines[:1] }},
+		{name: "undeclared role", mutate: func(tmpl *PostingTemplate) { tmpl.Lines[2].Role = "fees" }},
+		{name: "invalid entry type", mutate: func(tmpl *PostingTemplate) { tmpl.Lines[0].EntryType = "both" }},
+		{name: "invalid amount", mutate: func(tmpl *PostingTemplate) { tmpl.Lines[1].Amount = "gross -" }},
+		{name: "no debit", mutate: func(tmpl *PostingTemplate) { tmpl.Lines[0].EntryType = EntryTypeCredit }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := fundingTemplate()
+			tt.mutate(tmpl)
+			err := tmpl.Validate()
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidTemplate) {
+				t.Errorf("error %v, want ErrInvalidTemplate", err)
+			}
+		})
+	}
+}
+
+func TestPostingTemplateAmountNames(t *testing.T) {
+	if names := fundingTemplate().AmountNames(); !reflect.DeepEqual(names, []string{"fee", "gross"}) {
+		t.Errorf("amount names %v, want [fee gross]", names)
+	}
+}
+
+func TestPostingTemplateExpand(t *testing.T) {
+	wallet := map[string]string{"customer_wallet": "acc_wallet"}
+
+	tests := []struct {
+		name     string
+		tmpl     func() *PostingTemplate
+		accounts map[string]string
+		amounts  map[string]int64
+		want     []TemplateEntry
+	}{
+		{
+			name:     "with fee",
+			tmpl:     fundingTemplate,
+			accounts: wallet,
+			amounts:  map[string]int64{"gross": 1000, "fee": 30},
+			want: []TemplateEntry{
+				{Role: "settlement_float", AccountCode: "1100", EntryType: EntryTypeDebit, Amount: 1000},
+				{Role: "customer_wallet", AccountID: "acc_wallet", EntryType: EntryTypeCredit, Amount: 970},
+				{Role: "fee_income", AccountCode: "4000", EntryType: EntryTypeCredit, Amount: 30},
+			},
+		},
+		{
+			name:     "fee waived",
+			tmpl:     fundingTemplate,
+			accounts: wallet,
+			amounts:  map[string]int64{"gross": 1000, "fee": 0},
+			want: []TemplateEntry{
+				{Role: "settlement_float", AccountCode: "1100", EntryType: EntryTypeDebit, Amount: 1000},
+				{Role: "customer_wallet", AccountID: "acc_wallet", EntryType: EntryTypeCredit, Amount: 1000},
+			},
+		},
+		{
+			name:     "bound account replaces code",
+			tmpl:     fundingTemplate,
+			accounts: map[string]string{"customer_wallet": "acc_wallet", "fee_income": "acc_fees"},
+			amounts:  map[string]int64{"gross": 1000, "fee": 30},
+			want: []TemplateEntry{
+				{Role: "settlement_float", AccountCode: "1100", EntryType: EntryTypeDebit, Amount: 1000},
+				{Role: "customer_wallet", AccountID: "acc_wallet", EntryType: EntryTypeCredit, Amount: 970},
+				{Role: "fee_income", AccountID: "acc_fees", EntryType: EntryTypeCredit, Amount: 30},
+			},
+		},
+
+		{name: "unknown role", tmpl: fundingTemplate, accounts: map[string]string{"customer_wallet": "a", "other": "b"}, amounts: map[string]int64{"gross": 1000, "fee": 30}},
+		{name: "unknown amount", tmpl: fundingTemplate, accounts: wallet, amounts: map[string]int64{"gross": 1000, "fee": 30, "tax": 1}},
+		{name: "negative amount", tmpl: fundingTemplate, accounts: wallet, amounts: map[string]int64{"gross": 1000, "fee": -30}},
+		{name: "missing amount", tmpl: fundingTemplate, accounts: wallet, amounts: map[string]int64{"gross": 1000}},
+		{name: "unbound role", tmpl: fundingTemplate, amounts: map[string]int64{"gross": 1000, "fee": 30}},
+		{name: "fee above gross", tmpl: fundingTemplate, accounts: wallet, amounts: map[string]int64{"gross": 10, "fee": 30}},
+		{name: "all zero", tmpl: fundingTemplate, accounts: wallet, amounts: map[string]int64{"gross": 0, "fee": 0}},
+		{
+			name: "unbalanced",
+			tmpl: func() *PostingTemplate {
+				tmpl := fundingTemplate()
+				tmpl.Lines[1].Amount = "gross"
+				return tmpl
+			},
+			accounts: wallet,
+			amounts:  map[string]int64{"gross": 1000, "fee": 30},
+		},
+		{
+			name: "totals overflow",
+			tmpl: func() *PostingTemplate {
+				return &PostingTemplate{
+					Name:       "split",
+					SourceType: SourceTypeTransfer,
+					Roles:      map[string]string{"from": "1000", "to": "2000"},
+					Lines: []TemplateLine{
+						{Role: "from", EntryType: EntryTypeDebit, Amount: "gross"},
+						{Role: "from", EntryType: EntryTypeDebit, Amount: "gross"},
+						{Role: "to", EntryType: EntryTypeCredit, Amount: "gross"},
+						{Role: "to", EntryType: EntryTypeCredit, Amount: "gross"},
+					},
+				}
+			},
+			amounts: map[string]int64{"gross": math.MaxInt64},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := tt.tmpl().Expand(tt.accounts, tt.amounts)
+			if tt.want == nil {
+				if !errors.Is(err, ErrTemplateArguments) {
+					t.Fatalf("got %+v, %v, want ErrTemplateArguments", entries, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("entries %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
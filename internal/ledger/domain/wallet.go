@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"finplatform/internal/common/money"
+)
+
+// CustomerWalletsCode is the system account per-wallet ledger accounts sit under.
+// A tenant keeping it in another currency has a copy per currency coded by
+// CurrencyAccountCode, e.g. 2000-EUR.
+const CustomerWalletsCode = "2000"
+
+// ErrNoWalletParent is returned when a tenant has no customer wallet liabilities
+// account in a wallet's currency to put the wallet's ledger account under
+var ErrNoWalletParent = errors.New("no customer wallet liabilities account in the wallet's currency")
+
+// WalletAccount is a wallet's balance in one currency, which the ledger keeps in
+// an account of its own
+type WalletAccount struct {
+	ID              string         `json:"id"`
+	TenantID        string         `json:"tenant_id"`
+	WalletID        string         `json:"wallet_id"`
+	CustomerID      string         `json:"customer_id"`
+	Currency        money.Currency `json:"currency"`
+	LedgerAccountID *string        `json:"ledger_account_id,omitempty"`
+}
+
+// WalletAccountCode returns the deterministic code of a wallet account's ledger
+// account under its parent, e.g. 2000.01HV5R1Y4N6M0W0S8Q2J3K4P5T
+func WalletAccountCode(parentCode, walletAccountID string) string {
+	return parentCode + "." + walletAccountID
+}
+
+// NewWalletLedgerAccount builds the ledger account of a wallet account under
+// parent. Its balance cannot go negative; the wallet's metadata link it back.
+func NewWalletLedgerAccount(id string, wa *WalletAccount, parent *Account) (*Account, error) {
+	account, err := NewAccount(id, wa.TenantID, WalletAccountCode(parent.Code, wa.ID),
+		"Customer wallet "+wa.WalletID+" ("+string(wa.Currency)+")", AccountTypeLiability, wa.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.SetParent(parent); err != nil {
+		return nil, err
+	}
+
+	account.Constraints.NoNegative = true
+	account.Metadata["wallet_id"] = wa.WalletID
+	account.Metadata["wallet_account_id"] = wa.ID
+	account.Metadata["customer_id"] = wa.CustomerID
+
+	return account, nil
+}
+
+// RollupBalance is the balance of an account together with all its descendants,
+// each signed by its own normal side
+type RollupBalance struct {
+	AccountID      string         `json:"account_id"`
+	Currency       money.Currency `json:"currency"`
+	Balance        int64          `json:"balance"`
+	PendingBalance int64          `json:"pending_balance"`
+	TotalDebits    int64          `json:"total_debits"`
+	TotalCredits   int64          `json:"total_credits"`
+	AccountCount   int            `json:"account_count"` // The account and its descendants
+	AsOf           time.Time      `json:"as_of"`
+}
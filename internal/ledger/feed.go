@@ -0,0 +1,67 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"finplatform/internal/ledger/domain"
+)
+
+// Change feed limits. MaxFeedWait must stay below the HTTP server's write timeout.
+const (
+	DefaultFeedLimit = 100
+	MaxFeedLimit     = 500
+	MaxFeedWait      = 10 * time.Second
+
+	feedPollInterval = 500 * time.Millisecond
+)
+
+// GetFeed returns the tenant's posted batches, with their entries, after a posting
+// sequence. When there are none it waits up to wait for new postings, so consumers
+// can long-poll; a page with no batches means nothing was posted in that time.
+func (s *Service) GetFeed(ctx context.Context, tenantID string, after int64, limit int, wait time.Duration) (*domain.FeedPage, error) {
+	if limit <= 0 {
+		limit = DefaultFeedLimit
+	}
+	if limit > MaxFeedLimit {
+		limit = MaxFeedLimit
+	}
+	if wait > MaxFeedWait {
+		wait = MaxFeedWait
+	}
+	deadline := time.Now().Add(wait)
+
+	for {
+		// One extra batch tells whether there are more
+		batches, err := s.store.ListFeed(ctx, tenantID, after, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(batches) > 0 || !time.Now().Before(deadline) {
+			return newFeedPage(batches, after, limit), nil
+		}
+
+		timer := time.NewTimer(min(feedPollInterval, time.Until(deadline)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func newFeedPage(batches []*domain.Batch, after int64, limit int) *domain.FeedPage {
+	page := &domain.FeedPage{Batches: batches, NextAfter: after}
+	if len(batches) > limit {
+		page.Batches = batches[:limit]
+		page.HasMore = true
+	}
+	if n := len(page.Batches); n > 0 {
+		page.NextAfter = *page.Batches[n-1].PostingSequence
+	}
+	if page.Batches == nil {
+		page.Batches = []*domain.Batch{}
+	}
+	return page
+}
@@ -0,0 +1,194 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// ListPeriods lists the accounting periods of a tenant
+func (s *Service) ListPeriods(ctx context.Context, tenantID string) ([]*domain.Period, error) {
+	return s.store.ListPeriods(ctx, tenantID)
+}
+
+// GetPeriod retrieves the period of a tenant for the month containing month
+func (s *Service) GetPeriod(ctx context.Context, tenantID string, month time.Time) (*domain.Period, error) {
+	start, _ := domain.PeriodBounds(domain.PeriodTypeMonthly, month)
+	return s.store.GetPeriod(ctx, tenantID, start)
+}
+
+// GetPeriodAudit lists the status changes of the period for the month containing month
+func (s *Service) GetPeriodAudit(ctx context.Context, tenantID string, month time.Time) ([]*domain.PeriodAuditEntry, error) {
+	period, err := s.GetPeriod(ctx, tenantID, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListPeriodAudit(ctx, period.ID)
+}
+
+// ClosePeriod closes the month containing month. It first stops anything else
+// being posted into the period, then computes its final positions, sweeps its
+// revenue and expense balances into retained earnings and records who closed it.
+// A close that failed part way can be run again.
+func (s *Service) ClosePeriod(ctx context.Context, tenantID string, month time.Time, userID string) (*domain.Period, error) {
+	period, err := s.store.StartPeriodClose(ctx, domain.NewPeriod(ulid.Make().String(), tenantID, month), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RecomputePositions(ctx, tenantID, period.PeriodStart, period.PeriodEnd); err != nil {
+		return nil, fmt.Errorf("computing final positions: %w", err)
+	}
+
+	closing, err := s.buildClosingBatch(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err = s.store.CompletePeriodClose(ctx, tenantID, period.PeriodStart, closing, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("period closed",
+		"tenant_id", tenantID,
+		"period_start", period.PeriodStart.Format("2006-01-02"),
+		"closing_batch_id", period.ClosingBatchID,
+		"closed_by", userID,
+	)
+
+	return period, nil
+}
+
+// ReopenPeriod reopens a closed period. This is an admin action; the user and
+// reason are recorded in the period audit log.
+func (s *Service) ReopenPeriod(ctx context.Context, tenantID string, month time.Time, userID, reason string) (*domain.Period, error) {
+	start, _ := domain.PeriodBounds(domain.PeriodTypeMonthly, month)
+
+	period, err := s.store.ReopenPeriod(ctx, tenantID, start, userID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("period reopened",
+		"tenant_id", tenantID,
+		"period_start", period.PeriodStart.Format("2006-01-02"),
+		"reopened_by", userID,
+		"reason", reason,
+	)
+
+	return period, nil
+}
+
+// buildClosingBatch builds the batch, valued on the last day of the period, that
+// brings every revenue and expense account to zero as of the end of the period and
+// books the net into retained earnings, per currency. The value-dated balances
+// include the closing batches of earlier periods, and of this period before a
+// reopen, so only what is left is swept. Returns nil if there is nothing to sweep.
+func (s *Service) buildClosingBatch(ctx context.Context, period *domain.Period) (*domain.Batch, error) {
+	end := period.EndInstant()
+	balances, err := s.store.ListAccountBalances(ctx, period.TenantID, "", domain.DateBasisValue, nil, &end)
+	if err != nil {
+		return nil, err
+	}
+
+	type sweep struct {
+		account *domain.Account
+		amount  int64
+	}
+	sweeps := make(map[money.Currency][]sweep)
+	netIncome := make(map[money.Currency]int64)
+	for _, ab := range balances {
+		account := ab.Account
+		if account.IsPlaceholder {
+			continue
+		}
+		if account.AccountType != domain.AccountTypeRevenue && account.AccountType != domain.AccountTypeExpense {
+			continue
+		}
+
+		amount := ab.Balance
+		if amount == 0 {
+			continue
+		}
+
+		sweeps[account.Currency] = append(sweeps[account.Currency], sweep{account: account, amount: amount})
+		if account.AccountType == domain.AccountTypeRevenue {
+			netIncome[account.Currency] += amount
+		} else {
+			netIncome[account.Currency] -= amount
+		}
+	}
+
+	if len(sweeps) == 0 {
+		return nil, nil
+	}
+
+	currencies := make([]money.Currency, 0, len(sweeps))
+	for currency := range sweeps {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	builder := domain.NewBatchBuilder(ulid.Make().String(), period.TenantID, domain.SourceTypePeriodClose, currencies[0]).
+		WithReference("close-" + period.PeriodStart.Format("2006-01")).
+		WithDescription(fmt.Sprintf("Close of period %s", period.PeriodStart.Format("2006-01"))).
+		WithSourceID(period.ID).
+		WithEffectiveDate(period.PeriodEnd)
+
+	for _, currency := range currencies {
+		for _, sw := range sweeps[currency] {
+			addBalancingEntry(builder, sw.account, sw.amount, "Period close")
+		}
+
+		if net := netIncome[currency]; net != 0 {
+			retained, err := s.findRetainedEarnings(ctx, period.TenantID, currency)
+			if err != nil {
+				return nil, err
+			}
+			// Net income credits retained earnings; a loss debits it
+			addBalancingEntry(builder, retained, -net, "Period close to retained earnings")
+		}
+	}
+
+	batch, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("building closing batch: %w", err)
+	}
+
+	return batch, nil
+}
+
+// addBalancingEntry adds the entry that moves the account's balance by -amount
+func addBalancingEntry(builder *domain.BatchBuilder, account *domain.Account, amount int64, description string) {
+	entryType, abs := domain.ClosingEntry(account.NormalBalance, amount)
+	if entryType == domain.EntryTypeDebit {
+		builder.Debit(ulid.Make().String(), account.ID, money.New(abs, account.Currency), description)
+	} else {
+		builder.Credit(ulid.Make().String(), account.ID, money.New(abs, account.Currency), description)
+	}
+}
+
+// findRetainedEarnings finds the retained earnings account of a tenant in a currency
+func (s *Service) findRetainedEarnings(ctx context.Context, tenantID string, currency money.Currency) (*domain.Account, error) {
+	for _, code := range domain.RetainedEarningsCodes(currency) {
+		account, err := s.store.GetAccountByCode(ctx, tenantID, code)
+		if err != nil {
+			if database.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if account.Currency == currency {
+			return account, nil
+		}
+	}
+	return nil, fmt.Errorf("no retained earnings account in %s: %w", currency, database.ErrNotFound)
+}
@@ -0,0 +1,188 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+// RecomputePositions recomputes the daily, monthly and yearly positions, by posting
+// date and by value date, of every postable account of a tenant (all tenants if
+// empty) for all periods overlapping the days [from, to]. Positions are derived
+// from posted entries, each year opening with the settled position of the year
+// before, so the run is idempotent and can be repeated for any range.
+func (s *Service) RecomputePositions(ctx context.Context, tenantID string, from, to time.Time) error {
+	from, _ = domain.PeriodBounds(domain.PeriodTypeDaily, from)
+	to, _ = domain.PeriodBounds(domain.PeriodTypeDaily, to)
+	if to.Before(from) {
+		return fmt.Errorf("positions end %s is before start %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	accounts, err := s.store.ListPostableAccounts(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, account := range accounts {
+		for _, basis := range domain.DateBases {
+			n, err := s.recomputeAccountPositions(ctx, account, basis, from, to)
+			if err != nil {
+				return fmt.Errorf("account %s: %w", account.ID, err)
+			}
+			count += n
+		}
+	}
+
+	s.logger.Info("positions recomputed",
+		"tenant_id", tenantID,
+		"from", from.Format("2006-01-02"),
+		"to", to.Format("2006-01-02"),
+		"accounts", len(accounts),
+		"positions", count,
+	)
+
+	return nil
+}
+
+// RecomputeStalePositions recomputes the value-dated positions of a tenant (all
+// tenants if empty) that backdated postings made stale, from the first stale day
+// of each account up to today
+func (s *Service) RecomputeStalePositions(ctx context.Context, tenantID string) error {
+	stale, err := s.store.ListStalePositions(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	accounts, err := s.store.ListPostableAccounts(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	today, _ := domain.PeriodBounds(domain.PeriodTypeDaily, time.Now())
+	for _, account := range accounts {
+		r, ok := stale[account.ID]
+		if !ok {
+			continue
+		}
+		to := r.To
+		if to.Before(today) {
+			to = today
+		}
+
+		if _, err := s.recomputeAccountPositions(ctx, account, domain.DateBasisValue, r.From, to); err != nil {
+			return fmt.Errorf("account %s: %w", account.ID, err)
+		}
+	}
+
+	s.logger.Info("stale positions recomputed",
+		"tenant_id", tenantID,
+		"accounts", len(stale),
+	)
+
+	return nil
+}
+
+func (s *Service) recomputeAccountPositions(ctx context.Context, account *domain.Account, basis domain.DateBasis, from, to time.Time) (int, error) {
+	windowStart, _ := domain.PeriodBounds(domain.PeriodTypeYearly, from)
+	_, windowEnd := domain.PeriodBounds(domain.PeriodTypeYearly, to)
+
+	opening, err := s.openingBalance(ctx, account, basis, windowStart)
+	if err != nil {
+		return 0, err
+	}
+
+	days, err := s.store.GetDailyTotals(ctx, account.ID, basis, windowStart, windowEnd.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	positions := domain.BuildPositions(account, basis, opening, days, from, to)
+	for _, p := range positions {
+		p.ID = ulid.Make().String()
+	}
+
+	if err := s.store.UpsertPositions(ctx, positions); err != nil {
+		return 0, err
+	}
+
+	return len(positions), nil
+}
+
+// openingBalance returns the balance of an account by basis at yearStart: the
+// closing balance of its position for the year before if that is settled,
+// otherwise the sum of all its entries dated before yearStart
+func (s *Service) openingBalance(ctx context.Context, account *domain.Account, basis domain.DateBasis, yearStart time.Time) (int64, error) {
+	previous, err := s.store.GetPosition(ctx, account.ID, domain.PeriodTypeYearly, basis, yearStart.AddDate(-1, 0, 0))
+	if err != nil && !database.IsNotFound(err) {
+		return 0, err
+	}
+	if previous != nil && previous.Settled() {
+		return previous.ClosingBalance, nil
+	}
+
+	return s.store.GetBalanceBefore(ctx, account.ID, basis, yearStart)
+}
+
+// GetAccountPositions lists the positions of an account for one period type and date basis
+func (s *Service) GetAccountPositions(ctx context.Context, tenantID, accountID, periodType string, basis domain.DateBasis, from, to *time.Time) ([]*domain.Position, error) {
+	account, err := s.store.GetAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.ListPositions(ctx, account.ID, periodType, basis, from, to)
+}
+
+// PositionJob periodically recomputes recent positions in the background
+type PositionJob struct {
+	service  *Service
+	logger   *slog.Logger
+	interval time.Duration
+	lookback int
+}
+
+// NewPositionJob creates a job that every interval recomputes the positions of
+// the last lookbackDays days up to today, and any made stale by backdated postings
+func NewPositionJob(service *Service, logger *slog.Logger, interval time.Duration, lookbackDays int) *PositionJob {
+	return &PositionJob{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+		lookback: lookbackDays,
+	}
+}
+
+// Run runs the job until the context is cancelled
+func (j *PositionJob) Run(ctx context.Context) {
+	// The job works through every tenant
+	ctx = database.WithSystemScope(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		if err := j.service.RecomputePositions(ctx, "", now.AddDate(0, 0, -j.lookback), now); err != nil && ctx.Err() == nil {
+			j.logger.Error("position job failed", "error", err)
+		}
+		if err := j.service.RecomputeStalePositions(ctx, ""); err != nil && ctx.Err() == nil {
+			j.logger.Error("stale position job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
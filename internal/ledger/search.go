@@ -0,0 +1,83 @@
+package ledger
+
+import (
+	"context"
+
+	"finplatform/internal/ledger/domain"
+)
+
+// SearchEntries lists a page of a tenant's entries matching the filter in
+// (created_at, id) order, oldest first unless descending, after the cursor. The
+// total is only counted when withTotal is set.
+func (s *Service) SearchEntries(ctx context.Context, tenantID string, filter domain.EntryFilter, after *domain.EntryCursor, descending bool, limit int, withTotal bool) (*domain.EntryMatchPage, error) {
+	if filter.Basis == "" {
+		filter.Basis = domain.DateBasisPosting
+	}
+
+	// A cursor continues in the direction it was issued for
+	if after != nil {
+		descending = after.Descending
+	}
+	limit = pageLimit(limit)
+
+	matches, err := s.store.SearchEntries(ctx, tenantID, filter, after, descending, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.EntryMatchPage{Entries: matches}
+	if page.Entries == nil {
+		page.Entries = make([]*domain.EntryMatch, 0)
+	}
+	if len(matches) > limit {
+		page.Entries = matches[:limit]
+		last := page.Entries[limit-1]
+		page.Next = &domain.EntryCursor{CreatedAt: last.CreatedAt, ID: last.ID, Descending: descending}
+	}
+
+	if withTotal {
+		total, err := s.store.CountEntries(ctx, tenantID, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// ListBatches lists a page of a tenant's batches matching the filter, without
+// their entries, in (created_at, id) order, oldest first unless descending, after
+// the cursor. The total is only counted when withTotal is set.
+func (s *Service) ListBatches(ctx context.Context, tenantID string, filter domain.BatchFilter, after *domain.BatchCursor, descending bool, limit int, withTotal bool) (*domain.BatchPage, error) {
+	// A cursor continues in the direction it was issued for
+	if after != nil {
+		descending = after.Descending
+	}
+	limit = pageLimit(limit)
+
+	batches, err := s.store.ListBatches(ctx, tenantID, filter, after, descending, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.BatchPage{Batches: batches}
+	if page.Batches == nil {
+		page.Batches = make([]*domain.Batch, 0)
+	}
+	if len(batches) > limit {
+		page.Batches = batches[:limit]
+		last := page.Batches[limit-1]
+		page.Next = &domain.BatchCursor{CreatedAt: last.CreatedAt, ID: last.ID, Descending: descending}
+	}
+
+	if withTotal {
+		total, err := s.store.CountBatches(ctx, tenantID, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
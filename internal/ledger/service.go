@@ -2,10 +2,15 @@ package ledger
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/oklog/ulid/v2"
 
 	"finplatform/internal/common/database"
@@ -20,6 +25,9 @@ type Service struct {
 	store  *store.Store
 	db     *database.DB
 	logger *slog.Logger
+
+	checkpointKey       ed25519.PrivateKey // Signs hash chain checkpoints; see SetCheckpointKey
+	checkpointPublicKey ed25519.PublicKey  // Verifies them; see SetCheckpointPublicKey
 }
 
 // NewService creates a new ledger service
@@ -33,15 +41,17 @@ func NewService(db *database.DB, logger *slog.Logger) *Service {
 
 // CreateAccountRequest is the request to create an account
 type CreateAccountRequest struct {
-	TenantID      string              `json:"tenant_id" validate:"required"`
-	Code          string              `json:"code" validate:"required,max=50"`
-	Name          string              `json:"name" validate:"required,max=255"`
-	Description   string              `json:"description"`
-	AccountType   domain.AccountType  `json:"account_type" validate:"required,oneof=asset liability equity revenue expense"`
-	Currency      money.Currency      `json:"currency" validate:"required,len=3"`
-	ParentID      *string             `json:"parent_id"`
-	IsSystem      bool                `json:"is_system"`
-	IsPlaceholder bool                `json:"is_placeholder"`
+	TenantID      string                    `json:"tenant_id" validate:"required"`
+	Code          string                    `json:"code" validate:"required,max=50"`
+	Name          string                    `json:"name" validate:"required,max=255"`
+	Description   string                    `json:"description"`
+	AccountType   domain.AccountType        `json:"account_type" validate:"required,oneof=asset liability equity revenue expense"`
+	Currency      money.Currency            `json:"currency" validate:"required,len=3"`
+	ParentID      *string                   `json:"parent_id"`
+	IsSystem      bool                      `json:"is_system"`
+	IsPlaceholder bool                      `json:"is_placeholder"`
+	Constraints   domain.BalanceConstraints `json:"constraints"`
+	Metadata      map[string]string         `json:"metadata"`
 }
 
 // CreateAccount creates a new ledger account
@@ -57,6 +67,18 @@ func (s *Service) CreateAccount(ctx context.Context, req CreateAccountRequest) (
 	account.IsSystem = req.IsSystem
 	account.IsPlaceholder = req.IsPlaceholder
 
+	if err := req.Constraints.Validate(); err != nil {
+		return nil, err
+	}
+	account.Constraints = req.Constraints
+
+	if err := domain.ValidateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	for key, value := range req.Metadata {
+		account.Metadata[key] = value
+	}
+
 	// Handle parent relationship
 	if req.ParentID != nil {
 		parent, err := s.store.GetAccount(ctx, req.TenantID, *req.ParentID)
@@ -81,6 +103,58 @@ func (s *Service) CreateAccount(ctx context.Context, req CreateAccountRequest) (
 	return account, nil
 }
 
+// UpdateAccountRequest is the request to update an account. Nil fields are left
+// unchanged; Metadata is merged into the account's (see domain.MergeMetadata).
+type UpdateAccountRequest struct {
+	TenantID    string
+	ID          string
+	Name        *string
+	Description *string
+	Constraints *domain.BalanceConstraints
+	Metadata    map[string]*string
+}
+
+// UpdateAccount updates an account's name, description, balance constraints and
+// metadata. New constraints apply to later postings; an existing balance is not
+// re-checked.
+func (s *Service) UpdateAccount(ctx context.Context, req UpdateAccountRequest) (*domain.Account, error) {
+	account, err := s.store.GetAccount(ctx, req.TenantID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.Description != nil {
+		account.Description = *req.Description
+	}
+	if req.Constraints != nil {
+		if err := req.Constraints.Validate(); err != nil {
+			return nil, err
+		}
+		account.Constraints = *req.Constraints
+	}
+	if len(req.Metadata) > 0 {
+		account.Metadata = domain.MergeMetadata(account.Metadata, req.Metadata)
+		if err := domain.ValidateMetadata(account.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	account.UpdatedAt = time.Now().UTC()
+
+	if err := s.store.UpdateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("account updated",
+		"account_id", account.ID,
+		"code", account.Code,
+	)
+
+	return account, nil
+}
+
 // GetAccount retrieves an account by ID
 func (s *Service) GetAccount(ctx context.Context, tenantID, id string) (*domain.Account, error) {
 	return s.store.GetAccount(ctx, tenantID, id)
@@ -91,26 +165,89 @@ func (s *Service) GetAccountByCode(ctx context.Context, tenantID, code string) (
 	return s.store.GetAccountByCode(ctx, tenantID, code)
 }
 
-// ListAccounts lists accounts with optional type filter
-func (s *Service) ListAccounts(ctx context.Context, tenantID string, accountType *domain.AccountType, limit, offset int) ([]*domain.Account, int64, error) {
-	if limit <= 0 {
-		limit = 50
+// Page sizes of account and entry listings
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 1000
+)
+
+// ListAccounts lists a page of accounts, optionally of one type and with metadata
+// containing the given pairs, in (code, id) order after the cursor. The total is
+// only counted when withTotal is set.
+func (s *Service) ListAccounts(ctx context.Context, tenantID string, accountType *domain.AccountType, metadata map[string]string, after *domain.AccountCursor, limit int, withTotal bool) (*domain.AccountPage, error) {
+	limit = pageLimit(limit)
+
+	// One extra account tells whether there are more
+	accounts, err := s.store.ListAccounts(ctx, tenantID, accountType, metadata, after, limit+1)
+	if err != nil {
+		return nil, err
 	}
-	if limit > 100 {
-		limit = 100
+
+	page := &domain.AccountPage{Accounts: accounts}
+	if len(accounts) > limit {
+		page.Accounts = accounts[:limit]
+		last := page.Accounts[limit-1]
+		page.Next = &domain.AccountCursor{Code: last.Code, ID: last.ID}
 	}
-	return s.store.ListAccounts(ctx, tenantID, accountType, limit, offset)
+
+	if withTotal {
+		total, err := s.store.CountAccounts(ctx, tenantID, accountType, metadata)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// pageLimit applies the default and maximum page sizes to a requested limit
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	return min(limit, MaxPageLimit)
 }
 
 // PostEntriesRequest represents a request to post ledger entries
 type PostEntriesRequest struct {
-	TenantID    string             `json:"tenant_id" validate:"required"`
-	Reference   string             `json:"reference"`
-	Description string             `json:"description"`
-	SourceType  domain.SourceType  `json:"source_type" validate:"required"`
-	SourceID    string             `json:"source_id"`
-	Currency    money.Currency     `json:"currency" validate:"required,len=3"`
-	Entries     []EntryRequest     `json:"entries" validate:"required,min=2,dive"`
+	TenantID      string            `json:"tenant_id" validate:"required"`
+	Reference     string            `json:"reference"`
+	Description   string            `json:"description"`
+	SourceType    domain.SourceType `json:"source_type" validate:"required"`
+	SourceID      string            `json:"source_id"`
+	Currency      money.Currency    `json:"currency" validate:"required,len=3"`
+	Entries       []EntryRequest    `json:"entries" validate:"required,min=2,dive"`
+	FXLegs        []FXLegRequest    `json:"fx_legs" validate:"dive"`
+	EffectiveDate *time.Time        `json:"effective_date"` // Value date; defaults to today
+	Metadata      map[string]string `json:"metadata,omitempty"`
+
+	// IdempotencyKey makes retries of the request return the batch it first
+	// created. It defaults to source_type:source_id when there is a source ID.
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+// idempotencyKey returns the key retries of the request are matched on
+func (r PostEntriesRequest) idempotencyKey() string {
+	if r.IdempotencyKey != "" {
+		return r.IdempotencyKey
+	}
+	if r.SourceID != "" {
+		return string(r.SourceType) + ":" + r.SourceID
+	}
+	return ""
+}
+
+// fingerprint hashes everything in the request but its idempotency key, so a
+// retry can be told apart from a different request reusing the key
+func (r PostEntriesRequest) fingerprint() (string, error) {
+	r.IdempotencyKey = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("fingerprinting request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 // EntryRequest represents a single entry in a post request
@@ -118,11 +255,159 @@ type EntryRequest struct {
 	AccountID   string           `json:"account_id" validate:"required"`
 	EntryType   domain.EntryType `json:"entry_type" validate:"required,oneof=debit credit"`
 	Amount      int64            `json:"amount" validate:"required,gt=0"`
+	Currency    money.Currency   `json:"currency" validate:"omitempty,len=3"` // Defaults to the batch currency
 	Description string           `json:"description"`
 }
 
-// PostEntries creates and posts a balanced set of ledger entries
+// FXLegRequest represents a currency conversion within a post request.
+// The source amount is converted into the counter amount plus revenue at rate.
+type FXLegRequest struct {
+	SourceAmount             int64          `json:"source_amount" validate:"required,gt=0"`
+	SourceCurrency           money.Currency `json:"source_currency" validate:"required,len=3"`
+	CounterAmount            int64          `json:"counter_amount" validate:"required,gt=0"`
+	CounterCurrency          money.Currency `json:"counter_currency" validate:"required,len=3"`
+	Rate                     string         `json:"rate" validate:"required"`
+	SourcePositionAccountID  string         `json:"source_position_account_id" validate:"required"`
+	CounterPositionAccountID string         `json:"counter_position_account_id" validate:"required"`
+	RevenueAccountID         string         `json:"revenue_account_id"`
+	RevenueAmount            int64          `json:"revenue_amount" validate:"gte=0"`
+}
+
+// PostEntries creates and posts a balanced set of ledger entries in one
+// transaction; a rejected posting leaves no batch behind. A request repeating
+// the idempotency key of an earlier one returns the earlier batch, or fails with
+// domain.ErrIdempotencyKeyReused if the requests differ.
 func (s *Service) PostEntries(ctx context.Context, req PostEntriesRequest) (*domain.Batch, error) {
+	key := req.idempotencyKey()
+	var fingerprint string
+	if key != "" {
+		var err error
+		fingerprint, err = req.fingerprint()
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := s.replayPostEntries(ctx, req.TenantID, key, fingerprint)
+		if err == nil || !database.IsNotFound(err) {
+			return existing, err
+		}
+	}
+
+	batch, err := s.buildBatch(req)
+	if err != nil {
+		return nil, err
+	}
+	batch.IdempotencyKey = key
+	batch.Fingerprint = fingerprint
+
+	if err := s.validateEntryAccounts(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.PostNewBatch(ctx, batch, ""); err != nil {
+		// A concurrent request with the same key created its batch first
+		if key != "" && errors.Is(err, database.ErrAlreadyExists) {
+			return s.replayPostEntries(ctx, req.TenantID, key, fingerprint)
+		}
+		return nil, err
+	}
+
+	// Fetch the posted batch
+	batch, err = s.store.GetBatchWithEntries(ctx, req.TenantID, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("batch posted",
+		"batch_id", batch.ID,
+		"entry_count", batch.EntryCount,
+		"total", batch.TotalDebits.AmountMinor,
+		"currency", batch.TotalDebits.Currency,
+	)
+
+	return batch, nil
+}
+
+// replayPostEntries returns the batch an earlier request with the idempotency key
+// created
+func (s *Service) replayPostEntries(ctx context.Context, tenantID, key, fingerprint string) (*domain.Batch, error) {
+	batch, err := s.store.GetBatchByIdempotencyKey(ctx, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if batch.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("%w: %s", domain.ErrIdempotencyKeyReused, key)
+	}
+
+	s.logger.Info("returning existing batch for idempotency key",
+		"batch_id", batch.ID,
+		"idempotency_key", key,
+	)
+
+	return s.store.GetBatchWithEntries(ctx, tenantID, batch.ID)
+}
+
+// CreatePendingBatch creates a balanced batch without posting it. Its entries only
+// count towards pending balances until the batch is posted or voided.
+func (s *Service) CreatePendingBatch(ctx context.Context, req PostEntriesRequest) (*domain.Batch, error) {
+	batch, err := s.buildBatch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateEntryAccounts(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("pending batch created",
+		"batch_id", batch.ID,
+		"entry_count", batch.EntryCount,
+		"total", batch.TotalDebits.AmountMinor,
+		"currency", batch.TotalDebits.Currency,
+	)
+
+	return s.store.GetBatchWithEntries(ctx, req.TenantID, batch.ID)
+}
+
+// PostBatch posts a pending batch
+func (s *Service) PostBatch(ctx context.Context, tenantID, batchID, userID string) (*domain.Batch, error) {
+	if err := s.store.PostBatch(ctx, tenantID, batchID, userID); err != nil {
+		return nil, err
+	}
+
+	batch, err := s.store.GetBatchWithEntries(ctx, tenantID, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("batch posted",
+		"batch_id", batch.ID,
+		"entry_count", batch.EntryCount,
+		"total", batch.TotalDebits.AmountMinor,
+		"currency", batch.TotalDebits.Currency,
+	)
+
+	return batch, nil
+}
+
+// VoidBatch discards a pending batch
+func (s *Service) VoidBatch(ctx context.Context, tenantID, batchID, userID string) (*domain.Batch, error) {
+	if err := s.store.VoidBatch(ctx, tenantID, batchID, userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("batch voided", "batch_id", batchID)
+
+	return s.store.GetBatchWithEntries(ctx, tenantID, batchID)
+}
+
+// buildBatch validates a post request and builds a pending batch from it
+func (s *Service) buildBatch(req PostEntriesRequest) (*domain.Batch, error) {
 	batchID := ulid.Make().String()
 
 	builder := domain.NewBatchBuilder(batchID, req.TenantID, req.SourceType, req.Currency).
@@ -130,9 +415,24 @@ func (s *Service) PostEntries(ctx context.Context, req PostEntriesRequest) (*dom
 		WithDescription(req.Description).
 		WithSourceID(req.SourceID)
 
+	if req.EffectiveDate != nil {
+		builder.WithEffectiveDate(*req.EffectiveDate)
+	}
+
+	if err := domain.ValidateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	for key, value := range req.Metadata {
+		builder.WithMetadata(key, value)
+	}
+
 	for _, e := range req.Entries {
 		entryID := ulid.Make().String()
-		amount := money.New(e.Amount, req.Currency)
+		currency := e.Currency
+		if currency == "" {
+			currency = req.Currency
+		}
+		amount := money.New(e.Amount, currency)
 
 		if e.EntryType == domain.EntryTypeDebit {
 			builder.Debit(entryID, e.AccountID, amount, e.Description)
@@ -141,41 +441,113 @@ func (s *Service) PostEntries(ctx context.Context, req PostEntriesRequest) (*dom
 		}
 	}
 
+	for _, l := range req.FXLegs {
+		leg := &domain.FXLeg{
+			ID:                       ulid.Make().String(),
+			SourceAmount:             money.New(l.SourceAmount, l.SourceCurrency),
+			CounterAmount:            money.New(l.CounterAmount, l.CounterCurrency),
+			Rate:                     l.Rate,
+			SourcePositionAccountID:  l.SourcePositionAccountID,
+			CounterPositionAccountID: l.CounterPositionAccountID,
+			RevenueAccountID:         l.RevenueAccountID,
+			RevenueAmount:            l.RevenueAmount,
+		}
+		builder.FX(leg, ulid.Make().String(), ulid.Make().String(), ulid.Make().String())
+	}
+
 	batch, err := builder.Build()
 	if err != nil {
 		return nil, fmt.Errorf("building batch: %w", err)
 	}
 
-	// Create and post in a single transaction
-	err = s.db.WithTx(ctx, func(tx pgx.Tx) error {
-		if err := s.store.CreateBatchTx(ctx, tx, batch); err != nil {
-			return err
-		}
-		return nil
-	})
+	return batch, nil
+}
+
+// validateEntryAccounts loads every account a batch posts to in one query and
+// returns a *domain.EntryAccountError listing the entries they cannot take
+func (s *Service) validateEntryAccounts(ctx context.Context, batch *domain.Batch) error {
+	accounts, err := s.store.GetAccountsByID(ctx, batch.TenantID, batch.AccountIDs())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Post the batch
-	if err := s.store.PostBatch(ctx, req.TenantID, batchID, ""); err != nil {
-		return nil, fmt.Errorf("posting batch: %w", err)
+	return domain.CheckEntryAccounts(batch, accounts)
+}
+
+// ReverseBatchRequest represents a request to reverse a posted batch
+type ReverseBatchRequest struct {
+	TenantID string `json:"tenant_id" validate:"required"`
+	BatchID  string `json:"batch_id" validate:"required"`
+	Amount   int64  `json:"amount" validate:"gte=0"` // 0 reverses the remaining amount
+	Reason   string `json:"reason" validate:"required"`
+	UserID   string `json:"user_id"`
+
+	// Value date of the reversal; defaults to today
+	EffectiveDate *time.Time `json:"effective_date"`
+}
+
+// ReverseBatch posts a mirror batch that reverses all or part of a posted batch
+func (s *Service) ReverseBatch(ctx context.Context, req ReverseBatchRequest) (*domain.Batch, error) {
+	build := func(original *domain.Batch) (*domain.Batch, error) {
+		amount := req.Amount
+		if amount == 0 {
+			amount = original.RemainingReversible()
+		}
+
+		amounts, err := original.ReversalAmounts(amount)
+		if err != nil {
+			return nil, err
+		}
+
+		builder := domain.NewBatchBuilder(ulid.Make().String(), req.TenantID, domain.SourceTypeReversal, original.TotalDebits.Currency).
+			WithReference(original.Reference).
+			WithDescription(fmt.Sprintf("Reversal of %s: %s", original.ID, req.Reason)).
+			WithSourceID(original.ID)
+
+		if req.EffectiveDate != nil {
+			builder.WithEffectiveDate(*req.EffectiveDate)
+		}
+
+		var reversedIDs []string
+		for i, e := range original.Entries {
+			if amounts[i] == 0 {
+				continue
+			}
+			entryID := ulid.Make().String()
+			reversed := money.New(amounts[i], e.Amount.Currency)
+
+			if e.EntryType == domain.EntryTypeDebit {
+				builder.Credit(entryID, e.AccountID, reversed, e.Description)
+			} else {
+				builder.Debit(entryID, e.AccountID, reversed, e.Description)
+			}
+			reversedIDs = append(reversedIDs, e.ID)
+		}
+
+		reversal, err := builder.Build()
+		if err != nil {
+			return nil, fmt.Errorf("building reversal batch: %w", err)
+		}
+		for i, entry := range reversal.Entries {
+			entry.ReversesEntryID = &reversedIDs[i]
+		}
+
+		return reversal, nil
 	}
 
-	// Fetch the posted batch
-	batch, err = s.store.GetBatchWithEntries(ctx, req.TenantID, batchID)
+	reversal, err := s.store.ReverseBatch(ctx, req.TenantID, req.BatchID, req.UserID, req.Reason, build)
 	if err != nil {
 		return nil, err
 	}
 
-	s.logger.Info("batch posted",
-		"batch_id", batch.ID,
-		"entry_count", batch.EntryCount,
-		"total", batch.TotalDebits.AmountMinor,
-		"currency", batch.TotalDebits.Currency,
+	s.logger.Info("batch reversed",
+		"batch_id", req.BatchID,
+		"reversal_batch_id", reversal.ID,
+		"amount", reversal.TotalDebits.AmountMinor,
+		"currency", reversal.TotalDebits.Currency,
 	)
 
-	return batch, nil
+	return s.store.GetBatchWithEntries(ctx, req.TenantID, reversal.ID)
 }
 
 // GetBatch retrieves a batch with its entries
@@ -183,20 +555,164 @@ func (s *Service) GetBatch(ctx context.Context, tenantID, id string) (*domain.Ba
 	return s.store.GetBatchWithEntries(ctx, tenantID, id)
 }
 
-// GetAccountBalance retrieves the current balance for an account
-func (s *Service) GetAccountBalance(ctx context.Context, accountID string) (int64, error) {
-	return s.store.GetAccountBalance(ctx, accountID)
+// GetAccountBalance retrieves the current posted and pending balance for an account
+func (s *Service) GetAccountBalance(ctx context.Context, tenantID, accountID string) (*domain.Balance, error) {
+	account, err := s.store.GetAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.GetAccountBalance(ctx, account.ID)
 }
 
-// GetAccountEntries retrieves entries for an account
-func (s *Service) GetAccountEntries(ctx context.Context, accountID string, limit, offset int) ([]*domain.Entry, int64, error) {
-	if limit <= 0 {
-		limit = 50
+// GetAccountBalanceAsOf retrieves the posted balance of an account at a point in time.
+// By posting time it is the net of all entries posted at or before asOf, starting
+// from the latest position checkpoint where one exists. By value date it is the
+// running balance of the last entry valued on or before the date of asOf.
+func (s *Service) GetAccountBalanceAsOf(ctx context.Context, tenantID, accountID string, asOf time.Time, basis domain.DateBasis) (*domain.HistoricalBalance, error) {
+	account, err := s.store.GetAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf = asOf.UTC()
+	result := &domain.HistoricalBalance{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		AsOf:      asOf,
+		Basis:     basis,
+	}
+
+	switch basis {
+	case domain.DateBasisPosting:
+		// A checkpoint covers its whole last day, so it must end before asOf's day
+		periodEnd, closing, ok, err := s.store.GetBalanceCheckpoint(ctx, account.ID, asOf.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+
+		var after *time.Time
+		if ok {
+			dayAfter := periodEnd.AddDate(0, 0, 1)
+			after = &dayAfter
+			result.Balance = closing
+		}
+
+		sum, err := s.store.SumPostedEntries(ctx, account.ID, after, asOf)
+		if err != nil {
+			return nil, err
+		}
+		result.Balance += sum
+
+	case domain.DateBasisValue:
+		balance, err := s.store.GetValueDatedBalance(ctx, account.ID, asOf)
+		if err != nil {
+			return nil, err
+		}
+		result.Balance = balance
+
+	default:
+		return nil, fmt.Errorf("unknown date basis %q", basis)
+	}
+
+	return result, nil
+}
+
+// GetTrialBalance builds the trial balance of a tenant, one per currency unless a
+// currency is given. A nil asOf reports the current balances; otherwise entries
+// are dated by basis.
+func (s *Service) GetTrialBalance(ctx context.Context, tenantID string, asOf *time.Time, basis domain.DateBasis, currency money.Currency) ([]*domain.TrialBalance, error) {
+	balances, err := s.store.ListAccountBalances(ctx, tenantID, currency, basis, nil, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	reportTime := time.Now().UTC()
+	if asOf != nil {
+		reportTime = asOf.UTC()
+	}
+
+	return domain.NewTrialBalance(tenantID, reportTime, balances), nil
+}
+
+// GetBalanceSheet builds the balance sheet of a tenant, one per currency unless a
+// currency is given. A nil asOf reports the current balances; otherwise entries
+// are dated by basis.
+func (s *Service) GetBalanceSheet(ctx context.Context, tenantID string, asOf *time.Time, basis domain.DateBasis, currency money.Currency) ([]*domain.BalanceSheet, error) {
+	balances, err := s.store.ListAccountBalances(ctx, tenantID, currency, basis, nil, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	reportTime := time.Now().UTC()
+	if asOf != nil {
+		reportTime = asOf.UTC()
+	}
+
+	return domain.NewBalanceSheets(tenantID, reportTime, balances), nil
+}
+
+// GetIncomeStatement builds the profit and loss of a tenant for entries dated by
+// basis in [from, to], one per currency unless a currency is given
+func (s *Service) GetIncomeStatement(ctx context.Context, tenantID string, from, to time.Time, basis domain.DateBasis, currency money.Currency) ([]*domain.IncomeStatement, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("report end %s is before start %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	balances, err := s.store.ListAccountBalances(ctx, tenantID, currency, basis, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Closing batches in the range only move profit to retained earnings; leave them out
+	closes, err := s.store.SumPeriodCloseEntries(ctx, tenantID, basis, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, ab := range balances {
+		ab.Balance -= closes[ab.Account.ID]
+	}
+
+	return domain.NewIncomeStatements(tenantID, from.UTC(), to.UTC(), balances), nil
+}
+
+// GetAccountEntries lists a page of a tenant's account's entries in (created_at, id)
+// order, oldest first unless descending, after the cursor, optionally only those
+// of batches whose metadata contains the given pairs. The total is only counted
+// when withTotal is set.
+func (s *Service) GetAccountEntries(ctx context.Context, tenantID, accountID string, metadata map[string]string, after *domain.EntryCursor, descending bool, limit int, withTotal bool) (*domain.EntryPage, error) {
+	account, err := s.store.GetAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cursor continues in the direction it was issued for
+	if after != nil {
+		descending = after.Descending
+	}
+	limit = pageLimit(limit)
+
+	entries, err := s.store.GetAccountEntries(ctx, account.ID, nil, nil, metadata, after, descending, limit+1)
+	if err != nil {
+		return nil, err
 	}
-	if limit > 100 {
-		limit = 100
+
+	page := &domain.EntryPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		last := page.Entries[limit-1]
+		page.Next = &domain.EntryCursor{CreatedAt: last.CreatedAt, ID: last.ID, Descending: descending}
 	}
-	return s.store.GetAccountEntries(ctx, accountID, nil, nil, limit, offset)
+
+	if withTotal {
+		total, err := s.store.CountAccountEntries(ctx, account.ID, nil, nil, metadata)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
 }
 
 // InitializeSystemAccounts creates the standard system accounts for a tenant
@@ -249,3 +765,21 @@ func (s *Service) CreateBatchPostedEvent(batch *domain.Batch) (*events.Event, er
 		data,
 	)
 }
+
+// CreateBatchReversedEvent creates an event for a reversal batch
+func (s *Service) CreateBatchReversedEvent(reversal *domain.Batch) (*events.Event, error) {
+	data := events.LedgerBatchReversedData{
+		BatchID:         *reversal.ReversalOfID,
+		ReversalBatchID: reversal.ID,
+		Amount:          reversal.TotalDebits.AmountMinor,
+		Currency:        string(reversal.TotalDebits.Currency),
+	}
+
+	return events.NewEvent(
+		events.EventLedgerBatchReversed,
+		reversal.TenantID,
+		"ledger_batch",
+		*reversal.ReversalOfID,
+		data,
+	)
+}
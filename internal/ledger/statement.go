@@ -0,0 +1,75 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+// GetAccountStatement builds the statement of a tenant's account for the entries
+// dated by basis in [from, to]. The opening balance is the balance of the entries
+// dated before from (see statementOpening).
+func (s *Service) GetAccountStatement(ctx context.Context, tenantID, accountID string, from, to time.Time, basis domain.DateBasis) (*domain.Statement, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("statement end %s is before start %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	account, err := s.store.GetAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := s.statementOpening(ctx, account, basis, from)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.store.ListStatementLines(ctx, account.ID, basis, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewStatement(ulid.Make().String(), account, basis, from.UTC(), to.UTC(), opening, lines), nil
+}
+
+// statementOpening returns the balance of an account by basis at from. It starts
+// from the settled position of the day before from or, failing that, of the month
+// before, and adds only the entries dated after that period. Without either
+// position it sums every entry dated before from.
+func (s *Service) statementOpening(ctx context.Context, account *domain.Account, basis domain.DateBasis, from time.Time) (int64, error) {
+	day, _ := domain.PeriodBounds(domain.PeriodTypeDaily, from)
+	month, _ := domain.PeriodBounds(domain.PeriodTypeMonthly, from)
+	previous := []struct {
+		periodType string
+		start      time.Time
+	}{
+		{domain.PeriodTypeDaily, day.AddDate(0, 0, -1)},
+		{domain.PeriodTypeMonthly, month.AddDate(0, -1, 0)},
+	}
+
+	for _, period := range previous {
+		position, err := s.store.GetPosition(ctx, account.ID, period.periodType, basis, period.start)
+		if err != nil {
+			if database.IsNotFound(err) {
+				continue
+			}
+			return 0, err
+		}
+		if !position.Settled() {
+			continue
+		}
+
+		rest, err := s.store.GetBalanceBetween(ctx, account.ID, basis, position.PeriodEnd.AddDate(0, 0, 1), from)
+		if err != nil {
+			return 0, err
+		}
+		return position.ClosingBalance + rest, nil
+	}
+
+	return s.store.GetBalanceBefore(ctx, account.ID, basis, from)
+}
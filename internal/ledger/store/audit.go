@@ -0,0 +1,385 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/events"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// signedAmountSQL is an entry's amount signed by its account's normal balance
+const signedAmountSQL = `CASE WHEN (e.entry_type = 'debit') = (a.normal_balance = 'debit') THEN e.amount ELSE -e.amount END`
+
+// ListLedgerTenants lists every tenant with ledger accounts
+func (s *Store) ListLedgerTenants(ctx context.Context) ([]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT DISTINCT tenant_id FROM ledger_accounts ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("listing ledger tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning tenant: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+
+	return tenants, rows.Err()
+}
+
+// AuditTenant runs every integrity check over a tenant's ledger and returns the
+// discrepancies found. The checks share one repeatable read snapshot, so postings
+// made while they run cannot show up as discrepancies.
+func (s *Store) AuditTenant(ctx context.Context, tenantID string) ([]*domain.AuditFinding, error) {
+	opts := database.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+	var findings []*domain.AuditFinding
+	err := s.db.WithTxOptions(ctx, opts, func(tx pgx.Tx) error {
+		checks := []func(context.Context, pgx.Tx, string) ([]*domain.AuditFinding, error){
+			s.auditBatchTotalsTx,
+			s.auditCurrencyTotalsTx,
+			s.auditEntryStatusTx,
+			s.auditRunningBalancesTx,
+			s.auditAccountBalancesTx,
+			s.auditEquationTx,
+		}
+		for _, check := range checks {
+			found, err := check(ctx, tx, tenantID)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+// auditBatchTotalsTx checks each batch's base currency totals and entry count
+// against its entries
+func (s *Store) auditBatchTotalsTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]*domain.AuditFinding, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT b.id, b.total_debits, b.total_credits, b.entry_count,
+			   COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'debit' AND e.currency = b.currency), 0),
+			   COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'credit' AND e.currency = b.currency), 0),
+			   COUNT(e.id)
+		FROM ledger_batches b
+		LEFT JOIN ledger_entries e ON e.batch_id = b.id
+		WHERE b.tenant_id = $1
+		GROUP BY b.id
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing batch totals: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*domain.AuditFinding
+	for rows.Next() {
+		var id string
+		var totalDebits, totalCredits, debits, credits, count int64
+		var entryCount int
+		if err := rows.Scan(&id, &totalDebits, &totalCredits, &entryCount, &debits, &credits, &count); err != nil {
+			return nil, fmt.Errorf("scanning batch totals: %w", err)
+		}
+
+		if debits != totalDebits {
+			findings = append(findings, domain.NewAuditFinding(domain.AuditCheckBatchTotals, "batch", id,
+				totalDebits, debits, "debit entries do not sum to total_debits"))
+		}
+		if credits != totalCredits {
+			findings = append(findings, domain.NewAuditFinding(domain.AuditCheckBatchTotals, "batch", id,
+				totalCredits, credits, "credit entries do not sum to total_credits"))
+		}
+		if count != int64(entryCount) {
+			findings = append(findings, domain.NewAuditFinding(domain.AuditCheckEntryCount, "batch", id,
+				int64(entryCount), count, "entry_count does not match the batch's entries"))
+		}
+	}
+
+	return findings, rows.Err()
+}
+
+// auditCurrencyTotalsTx checks the per-currency totals of each batch against its
+// entries, and that each batch balances in every currency
+func (s *Store) auditCurrencyTotalsTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]*domain.AuditFinding, error) {
+	rows, err := tx.Query(ctx, `
+		WITH sums AS (
+			SELECT e.batch_id, e.currency,
+				   SUM(CASE WHEN e.entry_type = 'debit' THEN e.amount ELSE 0 END) AS debits,
+				   SUM(CASE WHEN e.entry_type = 'credit' THEN e.amount ELSE 0 END) AS credits
+			FROM ledger_entries e
+			JOIN ledger_batches b ON b.id = e.batch_id
+			WHERE b.tenant_id = $1
+			GROUP BY e.batch_id, e.currency
+		), totals AS (
+			SELECT t.batch_id, t.currency, t.total_debits, t.total_credits
+			FROM ledger_batch_totals t
+			JOIN ledger_batches b ON b.id = t.batch_id
+			WHERE b.tenant_id = $1
+		)
+		SELECT COALESCE(s.batch_id, t.batch_id), COALESCE(s.currency, t.currency),
+			   COALESCE(t.total_debits, 0), COALESCE(t.total_credits, 0),
+			   COALESCE(s.debits, 0), COALESCE(s.credits, 0)
+		FROM sums s
+		FULL OUTER JOIN totals t ON t.batch_id = s.batch_id AND t.currency = s.currency
+		WHERE s.batch_id IS NULL OR t.batch_id IS NULL
+		   OR s.debits <> t.total_debits OR s.credits <> t.total_credits OR s.debits <> s.credits
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing currency totals: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*domain.AuditFinding
+	for rows.Next() {
+		var id, currency string
+		var totalDebits, totalCredits, debits, credits int64
+		if err := rows.Scan(&id, &currency, &totalDebits, &totalCredits, &debits, &credits); err != nil {
+			return nil, fmt.Errorf("scanning currency totals: %w", err)
+		}
+
+		switch {
+		case debits != totalDebits:
+			findings = append(findings, domain.NewAuditFinding(domain.AuditCheckBatchTotals, "batch", id,
+				totalDebits, debits, fmt.Sprintf("%s debit entries do not sum to the batch's %s total", currency, currency)))
+		case credits != totalCredits:
+			findings = append(findings, domain.NewAuditFinding(domain.AuditCheckBatchTotals, "batch", id,
+				totalCredits, credits, fmt.Sprintf("%s credit entries do not sum to the batch's %s total", currency, currency)))
+		default:
+			findings = append(findings, domain.NewAuditFinding(domain.AuditCheckBatchTotals, "batch", id,
+				debits, credits, fmt.Sprintf("%s entries do not balance", currency)))
+		}
+	}
+
+	return findings, rows.Err()
+}
+
+// auditEntryStatusTx checks that entries count as posted exactly when their batch is
+func (s *Store) auditEntryStatusTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]*domain.AuditFinding, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT e.id, e.batch_id, b.status, e.posted_at IS NOT NULL
+		FROM ledger_entries e
+		JOIN ledger_batches b ON b.id = e.batch_id
+		WHERE b.tenant_id = $1
+		  AND (e.posted_at IS NOT NULL) <> (b.status IN ('posted', 'reversed'))
+		ORDER BY e.batch_id, e.sequence
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing entry status: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*domain.AuditFinding
+	for rows.Next() {
+		var id, batchID string
+		var status domain.BatchStatus
+		var posted bool
+		if err := rows.Scan(&id, &batchID, &status, &posted); err != nil {
+			return nil, fmt.Errorf("scanning entry status: %w", err)
+		}
+
+		f := &domain.AuditFinding{
+			Check:       domain.AuditCheckEntryStatus,
+			SubjectType: "entry",
+			SubjectID:   id,
+			Expected:    "unposted",
+			Actual:      "posted",
+			Message:     fmt.Sprintf("entry is posted but batch %s is %s", batchID, status),
+		}
+		if !posted {
+			f.Expected, f.Actual = "posted", "unposted"
+			f.Message = fmt.Sprintf("entry is not posted but batch %s is %s", batchID, status)
+		}
+		findings = append(findings, f)
+	}
+
+	return findings, rows.Err()
+}
+
+// auditRunningBalancesTx replays each account's posted entries in balance order
+// and reports the first entry whose balance_after differs, per account. Every
+// later entry of the account would differ too.
+func (s *Store) auditRunningBalancesTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]*domain.AuditFinding, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (account_id) account_id, id, expected, balance_after
+		FROM (
+			SELECT e.account_id, e.id, e.balance_after, e.effective_date, e.posted_at, e.batch_id, e.sequence,
+				   SUM(`+signedAmountSQL+`) OVER (
+					   PARTITION BY e.account_id
+					   ORDER BY e.effective_date, e.posted_at, e.batch_id, e.sequence
+					   ROWS UNBOUNDED PRECEDING
+				   ) AS expected
+			FROM ledger_entries e
+			JOIN ledger_accounts a ON a.id = e.account_id
+			WHERE a.tenant_id = $1 AND e.posted_at IS NOT NULL
+		) replay
+		WHERE balance_after IS DISTINCT FROM expected
+		ORDER BY account_id, effective_date, posted_at, batch_id, sequence
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing running balances: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*domain.AuditFinding
+	for rows.Next() {
+		var accountID, entryID string
+		var expected int64
+		var actual *int64
+		if err := rows.Scan(&accountID, &entryID, &expected, &actual); err != nil {
+			return nil, fmt.Errorf("scanning running balance: %w", err)
+		}
+
+		f := domain.NewAuditFinding(domain.AuditCheckRunningBalance, "entry", entryID, expected, 0,
+			fmt.Sprintf("balance_after of the first wrong entry of account %s", accountID))
+		if actual != nil {
+			f.Actual = fmt.Sprint(*actual)
+		} else {
+			f.Actual = "null"
+		}
+		findings = append(findings, f)
+	}
+
+	return findings, rows.Err()
+}
+
+// auditAccountBalancesTx checks each account's stored balance row against its entries
+func (s *Store) auditAccountBalancesTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]*domain.AuditFinding, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT a.id,
+			   COALESCE(bal.posted_balance, 0), COALESCE(bal.pending_balance, 0),
+			   COALESCE(bal.total_debits, 0), COALESCE(bal.total_credits, 0),
+			   COALESCE(SUM(`+signedAmountSQL+`) FILTER (WHERE e.posted_at IS NOT NULL), 0),
+			   COALESCE(SUM(`+signedAmountSQL+`) FILTER (WHERE b.status <> 'voided'), 0),
+			   COALESCE(SUM(e.amount) FILTER (WHERE e.posted_at IS NOT NULL AND e.entry_type = 'debit'), 0),
+			   COALESCE(SUM(e.amount) FILTER (WHERE e.posted_at IS NOT NULL AND e.entry_type = 'credit'), 0)
+		FROM ledger_accounts a
+		LEFT JOIN ledger_account_balances bal ON bal.account_id = a.id
+		LEFT JOIN ledger_entries e ON e.account_id = a.id
+		LEFT JOIN ledger_batches b ON b.id = e.batch_id
+		WHERE a.tenant_id = $1
+		GROUP BY a.id, bal.posted_balance, bal.pending_balance, bal.total_debits, bal.total_credits
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing account balances: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*domain.AuditFinding
+	for rows.Next() {
+		var id string
+		var stored, replayed [4]int64
+		err := rows.Scan(&id, &stored[0], &stored[1], &stored[2], &stored[3],
+			&replayed[0], &replayed[1], &replayed[2], &replayed[3])
+		if err != nil {
+			return nil, fmt.Errorf("scanning account balance: %w", err)
+		}
+
+		for i, figure := range []string{"posted_balance", "pending_balance", "total_debits", "total_credits"} {
+			if stored[i] != replayed[i] {
+				findings = append(findings, domain.NewAuditFinding(domain.AuditCheckAccountBalance, "account", id,
+					replayed[i], stored[i], figure+" does not match the account's entries"))
+			}
+		}
+	}
+
+	return findings, rows.Err()
+}
+
+// auditEquationTx checks the accounting equation over the stored posted balances
+func (s *Store) auditEquationTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]*domain.AuditFinding, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT bal.currency, a.account_type,
+			   SUM(CASE WHEN a.normal_balance = 'debit' THEN bal.posted_balance ELSE -bal.posted_balance END)
+		FROM ledger_account_balances bal
+		JOIN ledger_accounts a ON a.id = bal.account_id
+		WHERE a.tenant_id = $1
+		GROUP BY bal.currency, a.account_type
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("auditing accounting equation: %w", err)
+	}
+	defer rows.Close()
+
+	net := make(map[money.Currency]map[domain.AccountType]int64)
+	for rows.Next() {
+		var currency string
+		var accountType domain.AccountType
+		var amount int64
+		if err := rows.Scan(&currency, &accountType, &amount); err != nil {
+			return nil, fmt.Errorf("scanning balances by type: %w", err)
+		}
+		c := money.Currency(currency)
+		if net[c] == nil {
+			net[c] = make(map[domain.AccountType]int64)
+		}
+		net[c][accountType] = amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.CheckAccountingEquation(net), nil
+}
+
+// SaveAuditRun stores an audit run with its findings and, in the same
+// transaction, queues event in the outbox if there is one
+func (s *Store) SaveAuditRun(ctx context.Context, run *domain.AuditRun, event *events.Event) error {
+	return s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ledger_audit_runs (id, tenant_id, status, finding_count, started_at, finished_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, run.ID, run.TenantID, run.Status, run.FindingCount, run.StartedAt, run.FinishedAt)
+		if err != nil {
+			return fmt.Errorf("inserting audit run: %w", err)
+		}
+
+		for _, f := range run.Findings {
+			if f.ID == "" {
+				f.ID = ulid.Make().String()
+			}
+			_, err := tx.Exec(ctx, `
+				INSERT INTO ledger_audit_findings (
+					id, run_id, tenant_id, check_name, subject_type, subject_id,
+					expected, actual, message, created_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`, f.ID, f.RunID, f.TenantID, f.Check, f.SubjectType, f.SubjectID,
+				f.Expected, f.Actual, f.Message, f.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("inserting audit finding: %w", err)
+			}
+		}
+
+		if event == nil {
+			return nil
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("encoding event: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO outbox_events (id, tenant_id, event_id, event_type, aggregate_type, aggregate_id, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, ulid.Make().String(), event.TenantID, event.ID, event.Type, event.AggregateType, event.AggregateID, payload)
+		if err != nil {
+			return fmt.Errorf("queueing audit event: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,621 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+// bulkAccount is an account's balance and running balances while a bulk posting
+// is planned in memory. Value dates are kept as YYYY-MM-DD keys.
+type bulkAccount struct {
+	balance  *domain.Balance
+	existing map[string]int64 // Net of the posted entries valued after the earliest batch, by value date
+	planned  map[string]int64 // Net of the planned entries, by value date
+	entries  map[string][]*domain.Entry
+}
+
+// later returns the net of the posted and planned entries valued after day
+func (a *bulkAccount) later(day string) int64 {
+	var sum int64
+	for d, net := range a.existing {
+		if d > day {
+			sum += net
+		}
+	}
+	for d, net := range a.planned {
+		if d > day {
+			sum += net
+		}
+	}
+	return sum
+}
+
+// postedAfter returns whether entries already posted are valued after day
+func (a *bulkAccount) postedAfter(day string) bool {
+	for d := range a.existing {
+		if d > day {
+			return true
+		}
+	}
+	return false
+}
+
+// plan adds an entry to the planned entries, moving the running balance of the
+// planned entries valued after it
+func (a *bulkAccount) plan(day string, entry *domain.Entry, signed int64) {
+	for d, entries := range a.entries {
+		if d > day {
+			for _, e := range entries {
+				*e.BalanceAfter += signed
+			}
+		}
+	}
+	a.entries[day] = append(a.entries[day], entry)
+	a.planned[day] += signed
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// PostBulk creates and posts the pending items' batches in one transaction. The
+// batches are checked and their running balances worked out in memory, then
+// written with COPY and one balance update per account, which makes it suitable
+// for tens of thousands of batches. Batches are posted in item order, each a
+// microsecond after the one before so that running balances keep a strict order.
+//
+// An item whose idempotency key an earlier request used gets that batch and status
+// existing. A batch the ledger's rules reject is marked failed; if atomic, nothing
+// is posted and domain.ErrBulkRejected is returned with the other pending items
+// marked not posted.
+func (s *Store) PostBulk(ctx context.Context, tenantID string, items []*domain.BulkItem, atomic bool) error {
+	var posted []*domain.BulkItem
+	err := s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
+		if err := s.matchBulkIdempotencyKeysTx(ctx, tx, tenantID, items); err != nil {
+			return err
+		}
+
+		if err := s.checkBulkRulesTx(ctx, tx, tenantID, items); err != nil {
+			return err
+		}
+		if atomic && domain.BulkFailed(items) {
+			return domain.ErrBulkRejected
+		}
+
+		var err error
+		posted, err = s.writeBulkTx(ctx, tx, tenantID, items, atomic)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrBulkRejected) {
+			domain.RejectBulk(items)
+		}
+		return err
+	}
+
+	for _, item := range posted {
+		item.Status = domain.BulkItemPosted
+	}
+	return nil
+}
+
+// matchBulkIdempotencyKeysTx finds the batches earlier requests created with the
+// pending items' idempotency keys
+func (s *Store) matchBulkIdempotencyKeysTx(ctx context.Context, tx pgx.Tx, tenantID string, items []*domain.BulkItem) error {
+	byKey := make(map[string]*domain.BulkItem)
+	keys := make([]string, 0)
+	for _, item := range items {
+		if item.Pending() && item.Batch.IdempotencyKey != "" {
+			byKey[item.Batch.IdempotencyKey] = item
+			keys = append(keys, item.Batch.IdempotencyKey)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, reference, description, source_type, source_id,
+			   total_debits, total_credits, entry_count, currency, status,
+			   effective_date, posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   reversed_amount, reversal_of_batch_id, voided_at, voided_by,
+			   idempotency_key, request_fingerprint, posting_sequence, content_hash, chain_hash,
+			   metadata, created_at
+		FROM ledger_batches
+		WHERE tenant_id = $1 AND idempotency_key = ANY($2)
+	`, tenantID, keys)
+	if err != nil {
+		return fmt.Errorf("matching idempotency keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		existing, err := scanBatch(rows)
+		if err != nil {
+			return err
+		}
+
+		item := byKey[existing.IdempotencyKey]
+		if existing.Fingerprint != item.Batch.Fingerprint {
+			item.Fail(fmt.Errorf("%w: %s", domain.ErrIdempotencyKeyReused, existing.IdempotencyKey))
+			continue
+		}
+		item.Batch = existing
+		item.Status = domain.BulkItemExisting
+	}
+
+	return rows.Err()
+}
+
+// checkBulkRulesTx checks the pending items' batches against their accounts and
+// accounting periods, share-locking both as a single posting does
+func (s *Store) checkBulkRulesTx(ctx context.Context, tx pgx.Tx, tenantID string, items []*domain.BulkItem) error {
+	accountIDs := bulkAccountIDs(items)
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	accounts, err := s.getAccounts(ctx, tx, tenantID, accountIDs, true)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Pending() {
+			if err := domain.CheckEntryAccounts(item.Batch, accounts); err != nil {
+				item.Fail(err)
+			}
+		}
+	}
+
+	// Batches valued on the same day by the same source share a period check
+	type periodKey struct {
+		day        string
+		sourceType domain.SourceType
+	}
+	checked := make(map[periodKey]error)
+	for _, item := range items {
+		if !item.Pending() {
+			continue
+		}
+
+		key := periodKey{day: dayKey(item.Batch.AccountingDate()), sourceType: item.Batch.SourceType}
+		periodErr, ok := checked[key]
+		if !ok {
+			periodErr = s.checkPeriodOpenTx(ctx, tx, item.Batch)
+			if periodErr != nil && !errors.Is(periodErr, domain.ErrPeriodClosed) {
+				return periodErr
+			}
+			checked[key] = periodErr
+		}
+		if periodErr != nil {
+			item.Fail(periodErr)
+		}
+	}
+
+	return nil
+}
+
+// writeBulkTx posts the pending items' batches and returns the items posted. The
+// balances of every account involved are locked once; each batch's effect on them
+// is checked against the accounts' constraints in turn, as if it were posted on
+// its own.
+func (s *Store) writeBulkTx(ctx context.Context, tx pgx.Tx, tenantID string, items []*domain.BulkItem, atomic bool) ([]*domain.BulkItem, error) {
+	accountIDs := bulkAccountIDs(items)
+	if len(accountIDs) == 0 {
+		return nil, nil
+	}
+
+	balances, err := s.lockBalancesTx(ctx, tx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	earliest := time.Time{}
+	for _, item := range items {
+		if item.Pending() && (earliest.IsZero() || item.Batch.EffectiveDate.Before(earliest)) {
+			earliest = item.Batch.EffectiveDate
+		}
+	}
+	existing, err := s.laterEntryTotalsTx(ctx, tx, accountIDs, earliest)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[string]*bulkAccount, len(balances))
+	for id, balance := range balances {
+		accounts[id] = &bulkAccount{
+			balance:  balance,
+			existing: existing[id],
+			planned:  make(map[string]int64),
+			entries:  make(map[string][]*domain.Entry),
+		}
+	}
+
+	postedAt := time.Now().UTC().Truncate(time.Microsecond)
+	var posted []*domain.BulkItem
+	shifts := make(map[string]map[string]int64) // Net to add to posted entries valued after each day
+	stale := make(map[string]time.Time)         // Earliest backdated value date of each account
+
+	for _, item := range items {
+		if !item.Pending() {
+			continue
+		}
+
+		batch := item.Batch
+		at := postedAt.Add(time.Duration(len(posted)) * time.Microsecond)
+		batch.Status = domain.BatchStatusPosted
+		batch.PostedAt = &at
+		day := dayKey(batch.EffectiveDate)
+
+		work := make(map[string]domain.Balance)
+		for _, entry := range batch.Entries {
+			account := accounts[entry.AccountID]
+			balance, ok := work[entry.AccountID]
+			if !ok {
+				balance = *account.balance
+			}
+			balanceAfter := balance.Post(entry, false) - account.later(day)
+			work[entry.AccountID] = balance
+
+			entry.BalanceAfter = &balanceAfter
+			entry.PostedAt = batch.PostedAt
+		}
+
+		if err := checkBulkConstraints(accounts, work); err != nil {
+			item.Fail(err)
+			if atomic {
+				return nil, domain.ErrBulkRejected
+			}
+			continue
+		}
+
+		for id, balance := range work {
+			*accounts[id].balance = balance
+		}
+		for _, entry := range batch.Entries {
+			account := accounts[entry.AccountID]
+			signed := entry.SignedAmount(account.balance.NormalBalance)
+			account.plan(day, entry, signed)
+
+			if account.postedAfter(day) {
+				if shifts[entry.AccountID] == nil {
+					shifts[entry.AccountID] = make(map[string]int64)
+				}
+				shifts[entry.AccountID][day] += signed
+			}
+		}
+		if batch.IsBackdated() {
+			for _, id := range batch.AccountIDs() {
+				if d, ok := stale[id]; !ok || batch.EffectiveDate.Before(d) {
+					stale[id] = batch.EffectiveDate
+				}
+			}
+		}
+
+		posted = append(posted, item)
+	}
+
+	if len(posted) == 0 {
+		return nil, nil
+	}
+
+	// Chain the batches in posting order before their rows are written
+	last, previous, err := s.lockPostingSequenceTx(ctx, tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range posted {
+		batch := item.Batch
+		last++
+		seq := last
+		batch.PostingSequence = &seq
+		batch.ContentHash = batch.ComputeContentHash()
+		batch.ChainHash = domain.ChainHash(previous, batch.ContentHash)
+		previous = batch.ChainHash
+	}
+
+	// Existing running balances move before the new entries are written
+	if err := s.shiftBulkBalancesTx(ctx, tx, shifts); err != nil {
+		return nil, err
+	}
+	if err := s.copyBulkBatchesTx(ctx, tx, posted); err != nil {
+		return nil, err
+	}
+	if err := s.updateBulkBalancesTx(ctx, tx, accounts); err != nil {
+		return nil, err
+	}
+	if err := s.markBulkPositionsStaleTx(ctx, tx, stale); err != nil {
+		return nil, err
+	}
+	if err := s.setPostingSequenceTx(ctx, tx, tenantID, last, previous); err != nil {
+		return nil, err
+	}
+
+	return posted, nil
+}
+
+// checkBulkConstraints checks the balances a batch would leave against the
+// constraints of its accounts
+func checkBulkConstraints(accounts map[string]*bulkAccount, work map[string]domain.Balance) error {
+	ids := make([]string, 0, len(work))
+	for id := range work {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		after := work[id]
+		if err := after.Constraints.Check(id, accounts[id].balance.Balance, after.Balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// laterEntryTotalsTx sums the signed posted entries of the accounts valued after
+// the given date, by account and value date
+func (s *Store) laterEntryTotalsTx(ctx context.Context, tx pgx.Tx, accountIDs []string, after time.Time) (map[string]map[string]int64, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT e.account_id, e.effective_date,
+			   SUM(CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE e.account_id = ANY($1) AND e.posted_at IS NOT NULL
+		  AND e.effective_date > ($2::timestamptz AT TIME ZONE 'UTC')::date
+		GROUP BY e.account_id, e.effective_date
+	`, accountIDs, after)
+	if err != nil {
+		return nil, fmt.Errorf("summing later entries: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]map[string]int64)
+	for rows.Next() {
+		var accountID string
+		var date time.Time
+		var net int64
+		if err := rows.Scan(&accountID, &date, &net); err != nil {
+			return nil, fmt.Errorf("scanning later entries: %w", err)
+		}
+		if totals[accountID] == nil {
+			totals[accountID] = make(map[string]int64)
+		}
+		totals[accountID][dayKey(date)] = net
+	}
+
+	return totals, rows.Err()
+}
+
+// shiftBulkBalancesTx adds the bulk entries to the running balance of the posted
+// entries valued after them
+func (s *Store) shiftBulkBalancesTx(ctx context.Context, tx pgx.Tx, shifts map[string]map[string]int64) error {
+	var accountIDs, days []string
+	var amounts []int64
+	for id, byDay := range shifts {
+		for day, amount := range byDay {
+			accountIDs = append(accountIDs, id)
+			days = append(days, day)
+			amounts = append(amounts, amount)
+		}
+	}
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE ledger_entries e
+		SET balance_after = e.balance_after + s.shift
+		FROM (
+			SELECT x.id, SUM(u.amount) AS shift
+			FROM ledger_entries x
+			JOIN unnest($1::text[], $2::text[], $3::bigint[]) AS u(account_id, day, amount)
+			  ON u.account_id = x.account_id AND x.effective_date > u.day::date
+			WHERE x.posted_at IS NOT NULL
+			GROUP BY x.id
+		) s
+		WHERE e.id = s.id
+	`, accountIDs, days, amounts)
+	if err != nil {
+		return fmt.Errorf("updating later entry balances: %w", err)
+	}
+	return nil
+}
+
+// copyBulkBatchesTx writes the posted batches, their entries, totals and FX legs
+func (s *Store) copyBulkBatchesTx(ctx context.Context, tx pgx.Tx, items []*domain.BulkItem) error {
+	var batchRows, entryRows, totalRows [][]interface{}
+	fxLegs := &pgx.Batch{}
+	for _, item := range items {
+		batch := item.Batch
+		batchRows = append(batchRows, []interface{}{
+			batch.ID,
+			batch.TenantID,
+			batch.Reference,
+			batch.Description,
+			string(batch.SourceType),
+			batch.SourceID,
+			batch.TotalDebits.AmountMinor,
+			batch.TotalCredits.AmountMinor,
+			batch.EntryCount,
+			string(batch.TotalDebits.Currency),
+			string(batch.Status),
+			batch.EffectiveDate,
+			batch.PostedAt,
+			batch.PostedBy,
+			batch.ReversalOfID,
+			batch.Metadata,
+			batch.CreatedAt,
+			nullableString(batch.IdempotencyKey),
+			nullableString(batch.Fingerprint),
+			batch.PostingSequence,
+			batch.ContentHash,
+			batch.ChainHash,
+		})
+
+		for _, entry := range batch.Entries {
+			entryRows = append(entryRows, []interface{}{
+				entry.ID,
+				batch.TenantID,
+				entry.BatchID,
+				entry.AccountID,
+				string(entry.EntryType),
+				entry.Amount.AmountMinor,
+				string(entry.Amount.Currency),
+				entry.BalanceAfter,
+				entry.Description,
+				entry.Sequence,
+				entry.ReversesEntryID,
+				entry.EffectiveDate,
+				entry.PostedAt,
+				entry.CreatedAt,
+			})
+		}
+
+		for _, total := range batch.CurrencyTotals() {
+			totalRows = append(totalRows, []interface{}{
+				batch.ID,
+				string(total.Currency),
+				total.TotalDebits,
+				total.TotalCredits,
+				total.EntryCount,
+			})
+		}
+
+		// FX legs are rare and carry a decimal rate, so they are inserted as usual
+		for _, leg := range batch.FXLegs {
+			fxLegs.Queue(`
+				INSERT INTO ledger_fx_legs (
+					id, batch_id, source_amount, source_currency, counter_amount,
+					counter_currency, rate, source_position_account_id,
+					counter_position_account_id, revenue_account_id, revenue_amount, created_at
+				) VALUES (
+					$1, $2, $3, $4, $5, $6, $7::numeric, $8, $9, $10, $11, $12
+				)
+			`, leg.ID, batch.ID, leg.SourceAmount.AmountMinor, leg.SourceAmount.Currency,
+				leg.CounterAmount.AmountMinor, leg.CounterAmount.Currency, leg.Rate,
+				leg.SourcePositionAccountID, leg.CounterPositionAccountID,
+				nullableString(leg.RevenueAccountID), leg.RevenueAmount, batch.CreatedAt)
+		}
+	}
+
+	_, err := database.BulkInsertTx(ctx, tx, "ledger_batches", []string{
+		"id", "tenant_id", "reference", "description", "source_type", "source_id",
+		"total_debits", "total_credits", "entry_count", "currency", "status",
+		"effective_date", "posted_at", "posted_by", "reversal_of_batch_id", "metadata", "created_at",
+		"idempotency_key", "request_fingerprint", "posting_sequence", "content_hash", "chain_hash",
+	}, batchRows)
+	if err != nil {
+		// A concurrent request created a batch with one of the idempotency keys
+		if database.IsUniqueViolation(err) {
+			return fmt.Errorf("bulk batches: %w", database.ErrAlreadyExists)
+		}
+		return err
+	}
+
+	_, err = database.BulkInsertTx(ctx, tx, "ledger_entries", []string{
+		"id", "tenant_id", "batch_id", "account_id", "entry_type", "amount", "currency",
+		"balance_after", "description", "sequence", "reverses_entry_id", "effective_date",
+		"posted_at", "created_at",
+	}, entryRows)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.BulkInsertTx(ctx, tx, "ledger_batch_totals", []string{
+		"batch_id", "currency", "total_debits", "total_credits", "entry_count",
+	}, totalRows)
+	if err != nil {
+		return err
+	}
+
+	if fxLegs.Len() > 0 {
+		if err := tx.SendBatch(ctx, fxLegs).Close(); err != nil {
+			return fmt.Errorf("inserting fx legs: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateBulkBalancesTx stores the new balances of the accounts posted to
+func (s *Store) updateBulkBalancesTx(ctx context.Context, tx pgx.Tx, accounts map[string]*bulkAccount) error {
+	var ids []string
+	var posted, pending, debits, credits []int64
+	for id, account := range accounts {
+		if len(account.entries) == 0 {
+			continue
+		}
+		ids = append(ids, id)
+		posted = append(posted, account.balance.Balance)
+		pending = append(pending, account.balance.PendingBalance)
+		debits = append(debits, account.balance.TotalDebits)
+		credits = append(credits, account.balance.TotalCredits)
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE ledger_account_balances b
+		SET posted_balance = u.posted_balance, pending_balance = u.pending_balance,
+			total_debits = u.total_debits, total_credits = u.total_credits, version = b.version + 1
+		FROM unnest($1::text[], $2::bigint[], $3::bigint[], $4::bigint[], $5::bigint[])
+			AS u(account_id, posted_balance, pending_balance, total_debits, total_credits)
+		WHERE b.account_id = u.account_id
+	`, ids, posted, pending, debits, credits)
+	if err != nil {
+		return fmt.Errorf("updating account balances: %w", err)
+	}
+	return nil
+}
+
+// markBulkPositionsStaleTx marks the value dated positions that backdated batches
+// changed as stale
+func (s *Store) markBulkPositionsStaleTx(ctx context.Context, tx pgx.Tx, stale map[string]time.Time) error {
+	if len(stale) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(stale))
+	days := make([]string, 0, len(stale))
+	for id, date := range stale {
+		ids = append(ids, id)
+		days = append(days, dayKey(date))
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE ledger_positions p
+		SET is_stale = true
+		FROM unnest($1::text[], $2::text[]) AS u(account_id, day)
+		WHERE p.account_id = u.account_id AND p.date_basis = $3
+		  AND p.period_end >= u.day::date AND NOT p.is_stale
+	`, ids, days, domain.DateBasisValue)
+	if err != nil {
+		return fmt.Errorf("marking positions stale: %w", err)
+	}
+	return nil
+}
+
+// bulkAccountIDs returns the distinct accounts the pending items post to, sorted
+func bulkAccountIDs(items []*domain.BulkItem) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, item := range items {
+		if !item.Pending() {
+			continue
+		}
+		for _, id := range item.Batch.AccountIDs() {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"finplatform/internal/ledger/domain"
+)
+
+const checkpointColumns = `id, tenant_id, sequence, chain_hash, public_key, signature, created_at`
+
+// GetChainHead returns the last link of a tenant's hash chain. A tenant that has
+// never posted has an empty chain starting at sequence 1.
+func (s *Store) GetChainHead(ctx context.Context, tenantID string) (*domain.ChainHead, error) {
+	head := &domain.ChainHead{TenantID: tenantID, ChainStart: 1}
+	err := s.db.QueryRow(ctx, `
+		SELECT chain_start, last_sequence, last_hash
+		FROM ledger_sequences
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&head.ChainStart, &head.Sequence, &head.Hash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("getting chain head: %w", err)
+	}
+	return head, nil
+}
+
+// ListUncheckpointedHeads lists the chain heads of every tenant that has posted
+// since its last checkpoint
+func (s *Store) ListUncheckpointedHeads(ctx context.Context) ([]*domain.ChainHead, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT s.tenant_id, s.chain_start, s.last_sequence, s.last_hash
+		FROM ledger_sequences s
+		WHERE s.last_sequence >= s.chain_start
+		  AND s.last_sequence > COALESCE(
+			  (SELECT MAX(c.sequence) FROM ledger_chain_checkpoints c WHERE c.tenant_id = s.tenant_id), 0)
+		ORDER BY s.tenant_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing chain heads: %w", err)
+	}
+	defer rows.Close()
+
+	var heads []*domain.ChainHead
+	for rows.Next() {
+		var h domain.ChainHead
+		if err := rows.Scan(&h.TenantID, &h.ChainStart, &h.Sequence, &h.Hash); err != nil {
+			return nil, fmt.Errorf("scanning chain head: %w", err)
+		}
+		heads = append(heads, &h)
+	}
+
+	return heads, rows.Err()
+}
+
+// CreateChainCheckpoint stores a signed checkpoint
+func (s *Store) CreateChainCheckpoint(ctx context.Context, c *domain.ChainCheckpoint) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO ledger_chain_checkpoints (`+checkpointColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, c.ID, c.TenantID, c.Sequence, c.ChainHash, c.PublicKey, c.Signature, c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating chain checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ListChainCheckpoints lists the checkpoints of a tenant, oldest first
+func (s *Store) ListChainCheckpoints(ctx context.Context, tenantID string) ([]*domain.ChainCheckpoint, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+checkpointColumns+`
+		FROM ledger_chain_checkpoints
+		WHERE tenant_id = $1
+		ORDER BY sequence
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listing chain checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []*domain.ChainCheckpoint
+	for rows.Next() {
+		var c domain.ChainCheckpoint
+		err := rows.Scan(&c.ID, &c.TenantID, &c.Sequence, &c.ChainHash, &c.PublicKey, &c.Signature, &c.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning chain checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, &c)
+	}
+
+	return checkpoints, rows.Err()
+}
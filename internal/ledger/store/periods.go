@@ -0,0 +1,289 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+const periodColumns = `
+	id, tenant_id, period_start, period_end, status, closed_at, closed_by,
+	closing_batch_id, reopened_at, reopened_by, created_at, updated_at
+`
+
+// GetPeriod retrieves the period of a tenant starting on start
+func (s *Store) GetPeriod(ctx context.Context, tenantID string, start time.Time) (*domain.Period, error) {
+	query := `SELECT ` + periodColumns + ` FROM ledger_periods WHERE tenant_id = $1 AND period_start = $2`
+
+	row := s.db.QueryRow(ctx, query, tenantID, start)
+	return scanPeriod(row)
+}
+
+// ListPeriods lists the periods of a tenant, most recent first
+func (s *Store) ListPeriods(ctx context.Context, tenantID string) ([]*domain.Period, error) {
+	query := `SELECT ` + periodColumns + ` FROM ledger_periods WHERE tenant_id = $1 ORDER BY period_start DESC`
+
+	rows, err := s.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listing periods: %w", err)
+	}
+	defer rows.Close()
+
+	var periods []*domain.Period
+	for rows.Next() {
+		period, err := scanPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, rows.Err()
+}
+
+// StartPeriodClose moves the period to closing, creating it if it does not exist
+// yet. Once this commits no other batch can be posted into the period.
+func (s *Store) StartPeriodClose(ctx context.Context, period *domain.Period, userID string) (*domain.Period, error) {
+	var result *domain.Period
+	err := s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ledger_periods (id, tenant_id, period_start, period_end, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (tenant_id, period_start) DO NOTHING
+		`, period.ID, period.TenantID, period.PeriodStart, period.PeriodEnd, period.Status,
+			period.CreatedAt, period.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("creating period: %w", err)
+		}
+
+		result, err = s.getPeriodForUpdate(ctx, tx, period.TenantID, period.PeriodStart)
+		if err != nil {
+			return err
+		}
+
+		from := result.Status
+		if err := result.StartClose(time.Now().UTC()); err != nil {
+			return err
+		}
+		if from == result.Status {
+			return nil
+		}
+
+		return s.updatePeriodTx(ctx, tx, result, domain.PeriodActionStartClose, from, userID, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CompletePeriodClose posts the closing batch, if any, and marks the closing
+// period closed, all within one serializable transaction
+func (s *Store) CompletePeriodClose(ctx context.Context, tenantID string, start time.Time, closing *domain.Batch, userID string) (*domain.Period, error) {
+	var result *domain.Period
+	err := s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
+		var err error
+		result, err = s.getPeriodForUpdate(ctx, tx, tenantID, start)
+		if err != nil {
+			return err
+		}
+
+		var closingBatchID *string
+		if closing != nil {
+			if err := closing.Post(userID); err != nil {
+				return err
+			}
+			if err := s.CreateBatchTx(ctx, tx, closing); err != nil {
+				return err
+			}
+			if err := s.applyBalanceChangeTx(ctx, tx, closing, balancePostNew); err != nil {
+				return err
+			}
+			closingBatchID = &closing.ID
+		}
+
+		from := result.Status
+		if err := result.Close(userID, closingBatchID); err != nil {
+			return err
+		}
+
+		if err := s.updatePeriodTx(ctx, tx, result, domain.PeriodActionClose, from, userID, ""); err != nil {
+			return err
+		}
+
+		if closing != nil {
+			return s.assignPostingSequenceTx(ctx, tx, closing)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReopenPeriod moves a closed period back to open and records who did it and why
+func (s *Store) ReopenPeriod(ctx context.Context, tenantID string, start time.Time, userID, reason string) (*domain.Period, error) {
+	var result *domain.Period
+	err := s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
+		var err error
+		result, err = s.getPeriodForUpdate(ctx, tx, tenantID, start)
+		if err != nil {
+			return err
+		}
+
+		from := result.Status
+		if err := result.Reopen(userID); err != nil {
+			return err
+		}
+
+		return s.updatePeriodTx(ctx, tx, result, domain.PeriodActionReopen, from, userID, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListPeriodAudit lists the status changes of a period, oldest first
+func (s *Store) ListPeriodAudit(ctx context.Context, periodID string) ([]*domain.PeriodAuditEntry, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, period_id, tenant_id, action, from_status, to_status, user_id, reason, created_at
+		FROM ledger_period_audit
+		WHERE period_id = $1
+		ORDER BY created_at, id
+	`, periodID)
+	if err != nil {
+		return nil, fmt.Errorf("listing period audit: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.PeriodAuditEntry
+	for rows.Next() {
+		var e domain.PeriodAuditEntry
+		var reason *string
+		err := rows.Scan(&e.ID, &e.PeriodID, &e.TenantID, &e.Action, &e.FromStatus, &e.ToStatus,
+			&e.UserID, &reason, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning period audit: %w", err)
+		}
+		e.Reason = derefString(reason)
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// SumPeriodCloseEntries returns, per account, the net effect of the posted closing
+// batches of a tenant dated by basis in [from, to]
+func (s *Store) SumPeriodCloseEntries(ctx context.Context, tenantID string, basis domain.DateBasis, from, to time.Time) (map[string]int64, error) {
+	query := `
+		SELECT e.account_id, SUM(CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END)
+		FROM ledger_entries e
+		JOIN ledger_batches b ON b.id = e.batch_id
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE b.tenant_id = $1 AND b.source_type = $2 AND e.posted_at IS NOT NULL
+	`
+	query += ` AND ` + entryDateCond(basis, ">=", 3) + ` AND ` + entryDateCond(basis, "<=", 4) + `
+		GROUP BY e.account_id`
+
+	rows, err := s.db.Query(ctx, query, tenantID, domain.SourceTypePeriodClose, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("summing closing entries: %w", err)
+	}
+	defer rows.Close()
+
+	sums := make(map[string]int64)
+	for rows.Next() {
+		var accountID string
+		var sum int64
+		if err := rows.Scan(&accountID, &sum); err != nil {
+			return nil, fmt.Errorf("scanning closing entries: %w", err)
+		}
+		sums[accountID] = sum
+	}
+
+	return sums, rows.Err()
+}
+
+// checkPeriodOpenTx returns domain.ErrPeriodClosed if the batch is booked into a
+// period that does not allow it. The period row is share-locked so that it cannot
+// start closing until the posting transaction is done.
+func (s *Store) checkPeriodOpenTx(ctx context.Context, tx pgx.Tx, batch *domain.Batch) error {
+	query := `SELECT ` + periodColumns + `
+		FROM ledger_periods
+		WHERE tenant_id = $1
+		  AND period_start <= ($2::timestamptz AT TIME ZONE 'UTC')::date
+		  AND period_end >= ($2::timestamptz AT TIME ZONE 'UTC')::date
+		FOR SHARE
+	`
+
+	period, err := scanPeriod(tx.QueryRow(ctx, query, batch.TenantID, batch.AccountingDate()))
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return period.AllowsPosting(batch.SourceType)
+}
+
+func (s *Store) getPeriodForUpdate(ctx context.Context, tx pgx.Tx, tenantID string, start time.Time) (*domain.Period, error) {
+	query := `SELECT ` + periodColumns + ` FROM ledger_periods WHERE tenant_id = $1 AND period_start = $2 FOR UPDATE`
+
+	return scanPeriod(tx.QueryRow(ctx, query, tenantID, start))
+}
+
+// updatePeriodTx stores a period's new state and records the change in the audit log
+func (s *Store) updatePeriodTx(ctx context.Context, tx pgx.Tx, period *domain.Period, action string, from domain.PeriodStatus, userID, reason string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE ledger_periods
+		SET status = $1, closed_at = $2, closed_by = $3, closing_batch_id = $4,
+			reopened_at = $5, reopened_by = $6
+		WHERE id = $7
+	`, period.Status, period.ClosedAt, period.ClosedBy, period.ClosingBatchID,
+		period.ReopenedAt, period.ReopenedBy, period.ID)
+	if err != nil {
+		return fmt.Errorf("updating period: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ledger_period_audit (
+			id, tenant_id, period_id, action, from_status, to_status, user_id, reason, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+	`, ulid.Make().String(), period.TenantID, period.ID, action, from, period.Status,
+		nullableString(userID), nullableString(reason), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("recording period audit: %w", err)
+	}
+
+	return nil
+}
+
+func scanPeriod(row pgx.Row) (*domain.Period, error) {
+	var p domain.Period
+	err := row.Scan(
+		&p.ID, &p.TenantID, &p.PeriodStart, &p.PeriodEnd, &p.Status, &p.ClosedAt, &p.ClosedBy,
+		&p.ClosingBatchID, &p.ReopenedAt, &p.ReopenedBy, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning period: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// entrySearchFrom joins the entries to their accounts and batches for a search,
+// aliased e, a and b
+const entrySearchFrom = `
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		JOIN ledger_batches b ON b.id = e.batch_id
+`
+
+// SearchEntries lists up to limit of a tenant's entries matching the filter, in
+// (created_at, id) order, starting after the cursor if one is given
+func (s *Store) SearchEntries(ctx context.Context, tenantID string, filter domain.EntryFilter, after *domain.EntryCursor, descending bool, limit int) ([]*domain.EntryMatch, error) {
+	where, args := entryFilterConds(tenantID, filter)
+
+	op, order := ">", "ASC"
+	if descending {
+		op, order = "<", "DESC"
+	}
+
+	if after != nil {
+		where += fmt.Sprintf(` AND (e.created_at, e.id) %s ($%d, $%d)`, op, len(args)+1, len(args)+2)
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query := `
+		SELECT e.id, e.batch_id, e.account_id, e.entry_type, e.amount, e.currency,
+			   e.balance_after, e.description, e.sequence, e.reverses_entry_id, e.effective_date,
+			   e.posted_at, e.created_at, a.code, b.reference, b.source_type, b.source_id, b.status
+	` + entrySearchFrom + where +
+		fmt.Sprintf(` ORDER BY e.created_at %s, e.id %s LIMIT %d`, order, order, limit)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching entries: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*domain.EntryMatch
+	for rows.Next() {
+		var m domain.EntryMatch
+		var amount int64
+		var currency string
+		var description, reference, sourceID *string
+		err := rows.Scan(
+			&m.ID, &m.BatchID, &m.AccountID, &m.EntryType, &amount, &currency,
+			&m.BalanceAfter, &description, &m.Sequence, &m.ReversesEntryID, &m.EffectiveDate,
+			&m.PostedAt, &m.CreatedAt, &m.AccountCode, &reference, &m.SourceType, &sourceID, &m.BatchStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning entry: %w", err)
+		}
+		m.Amount = money.New(amount, money.Currency(currency))
+		m.Description = derefString(description)
+		m.BatchReference = derefString(reference)
+		m.SourceID = derefString(sourceID)
+		matches = append(matches, &m)
+	}
+
+	return matches, rows.Err()
+}
+
+// CountEntries counts a tenant's entries matching the filter
+func (s *Store) CountEntries(ctx context.Context, tenantID string, filter domain.EntryFilter) (int64, error) {
+	where, args := entryFilterConds(tenantID, filter)
+
+	var total int64
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) `+entrySearchFrom+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting entries: %w", err)
+	}
+	return total, nil
+}
+
+// entryFilterConds builds the WHERE clause selecting a tenant's entries that
+// match the filter, with its arguments
+func entryFilterConds(tenantID string, filter domain.EntryFilter) (string, []interface{}) {
+	where := ` WHERE e.tenant_id = $1`
+	args := []interface{}{tenantID}
+	argIdx := 2
+
+	add := func(cond string, arg interface{}) {
+		where += ` AND ` + fmt.Sprintf(cond, argIdx)
+		args = append(args, arg)
+		argIdx++
+	}
+
+	if filter.AccountID != "" {
+		add(`e.account_id = $%d`, filter.AccountID)
+	}
+	if filter.AccountCode != "" {
+		add(`a.code = $%d`, filter.AccountCode)
+	}
+	if filter.AccountType != nil {
+		add(`a.account_type = $%d`, string(*filter.AccountType))
+	}
+	if filter.SourceType != nil {
+		add(`b.source_type = $%d`, string(*filter.SourceType))
+	}
+	if filter.SourceID != "" {
+		add(`b.source_id = $%d`, filter.SourceID)
+	}
+	if filter.Reference != "" {
+		add(`b.reference = $%d`, filter.Reference)
+	}
+	if len(filter.Metadata) > 0 {
+		add(`b.metadata @> $%d`, filter.Metadata)
+	}
+	if filter.Description != "" {
+		add(`(e.description ILIKE $%[1]d OR b.description ILIKE $%[1]d)`, "%"+escapeLike(filter.Description)+"%")
+	}
+	if filter.EntryType != nil {
+		add(`e.entry_type = $%d`, string(*filter.EntryType))
+	}
+	if filter.Currency != "" {
+		add(`e.currency = $%d`, string(filter.Currency))
+	}
+	if filter.MinAmount != nil {
+		add(`e.amount >= $%d`, *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		add(`e.amount <= $%d`, *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		where += ` AND ` + entryDateCond(filter.Basis, ">=", argIdx)
+		args = append(args, *filter.From)
+		argIdx++
+	}
+	if filter.To != nil {
+		where += ` AND ` + entryDateCond(filter.Basis, "<=", argIdx)
+		args = append(args, *filter.To)
+	}
+
+	return where, args
+}
+
+// ListBatches lists up to limit of a tenant's batches matching the filter,
+// without their entries, in (created_at, id) order, starting after the cursor if
+// one is given
+func (s *Store) ListBatches(ctx context.Context, tenantID string, filter domain.BatchFilter, after *domain.BatchCursor, descending bool, limit int) ([]*domain.Batch, error) {
+	where, args := batchFilterConds(tenantID, filter)
+
+	op, order := ">", "ASC"
+	if descending {
+		op, order = "<", "DESC"
+	}
+
+	if after != nil {
+		where += fmt.Sprintf(` AND (created_at, id) %s ($%d, $%d)`, op, len(args)+1, len(args)+2)
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query := `
+		SELECT id, tenant_id, reference, description, source_type, source_id,
+			   total_debits, total_credits, entry_count, currency, status,
+			   effective_date, posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   reversed_amount, reversal_of_batch_id, voided_at, voided_by,
+			   idempotency_key, request_fingerprint, posting_sequence, content_hash, chain_hash,
+			   metadata, created_at
+		FROM ledger_batches
+	` + where + fmt.Sprintf(` ORDER BY created_at %s, id %s LIMIT %d`, order, order, limit)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*domain.Batch
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
+// CountBatches counts a tenant's batches matching the filter
+func (s *Store) CountBatches(ctx context.Context, tenantID string, filter domain.BatchFilter) (int64, error) {
+	where, args := batchFilterConds(tenantID, filter)
+
+	var total int64
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM ledger_batches`+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting batches: %w", err)
+	}
+	return total, nil
+}
+
+// batchFilterConds builds the WHERE clause selecting a tenant's batches that
+// match the filter, with its arguments
+func batchFilterConds(tenantID string, filter domain.BatchFilter) (string, []interface{}) {
+	where := ` WHERE tenant_id = $1`
+	args := []interface{}{tenantID}
+	argIdx := 2
+
+	add := func(cond string, arg interface{}) {
+		where += ` AND ` + fmt.Sprintf(cond, argIdx)
+		args = append(args, arg)
+		argIdx++
+	}
+
+	if filter.Status != nil {
+		add(`status = $%d`, string(*filter.Status))
+	}
+	if filter.SourceType != nil {
+		add(`source_type = $%d`, string(*filter.SourceType))
+	}
+	if filter.SourceID != "" {
+		add(`source_id = $%d`, filter.SourceID)
+	}
+	if filter.Reference != "" {
+		add(`reference = $%d`, filter.Reference)
+	}
+	if len(filter.Metadata) > 0 {
+		add(`metadata @> $%d`, filter.Metadata)
+	}
+
+	return where, args
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"finplatform/internal/ledger/domain"
+)
+
+// ListStatementLines lists the posted entries of an account dated by basis in
+// [from, to] with their batches' references, in the order running balances
+// run by that basis
+func (s *Store) ListStatementLines(ctx context.Context, accountID string, basis domain.DateBasis, from, to time.Time) ([]*domain.StatementLine, error) {
+	order := `e.posted_at, e.batch_id, e.sequence`
+	if basis == domain.DateBasisValue {
+		order = `e.effective_date, e.posted_at, e.batch_id, e.sequence`
+	}
+
+	query := `
+		SELECT e.id, e.batch_id, b.reference, COALESCE(NULLIF(e.description, ''), b.description),
+			   b.source_type, b.source_id, e.entry_type, e.amount, e.posted_at, e.effective_date
+		FROM ledger_entries e
+		JOIN ledger_batches b ON b.id = e.batch_id
+		WHERE e.account_id = $1 AND e.posted_at IS NOT NULL
+		  AND ` + entryDateCond(basis, ">=", 2) + `
+		  AND ` + entryDateCond(basis, "<=", 3) + `
+		ORDER BY ` + order
+
+	rows, err := s.db.Query(ctx, query, accountID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("listing statement entries: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []*domain.StatementLine
+	for rows.Next() {
+		var line domain.StatementLine
+		var reference, description, sourceID *string
+		err := rows.Scan(&line.EntryID, &line.BatchID, &reference, &description,
+			&line.SourceType, &sourceID, &line.EntryType, &line.Amount, &line.BookingDate, &line.ValueDate)
+		if err != nil {
+			return nil, fmt.Errorf("scanning statement entry: %w", err)
+		}
+		line.Reference = derefString(reference)
+		line.Description = derefString(description)
+		line.SourceID = derefString(sourceID)
+		lines = append(lines, &line)
+	}
+
+	return lines, rows.Err()
+}
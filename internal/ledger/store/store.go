@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"finplatform/internal/common/database"
 	"finplatform/internal/common/money"
@@ -24,19 +25,63 @@ func New(db *database.DB) *Store {
 	return &Store{db: db}
 }
 
+const insertAccountQuery = `
+	INSERT INTO ledger_accounts (
+		id, tenant_id, code, name, description, account_type, normal_balance,
+		currency, parent_id, path, is_system, is_placeholder, status, metadata,
+		no_negative_balance, min_balance, max_balance, overdraft_limit,
+		created_at, updated_at
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+	)
+`
+
 // CreateAccount creates a new ledger account
 func (s *Store) CreateAccount(ctx context.Context, account *domain.Account) error {
-	query := `
-		INSERT INTO ledger_accounts (
-			id, tenant_id, code, name, description, account_type, normal_balance,
-			currency, parent_id, path, is_system, is_placeholder, status, metadata,
-			created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
-		)
-	`
+	_, err := s.db.Exec(ctx, insertAccountQuery, insertAccountArgs(account)...)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return fmt.Errorf("account with code %s already exists: %w", account.Code, database.ErrAlreadyExists)
+		}
+		return fmt.Errorf("creating account: %w", err)
+	}
+
+	return nil
+}
+
+// ImportAccounts creates accounts, parents before their children, and saves the
+// names, descriptions and metadata of others in one transaction
+func (s *Store) ImportAccounts(ctx context.Context, create, update []*domain.Account) error {
+	return s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		for _, account := range create {
+			if _, err := tx.Exec(ctx, insertAccountQuery, insertAccountArgs(account)...); err != nil {
+				if database.IsUniqueViolation(err) {
+					return fmt.Errorf("account with code %s already exists: %w", account.Code, database.ErrAlreadyExists)
+				}
+				return fmt.Errorf("creating account %s: %w", account.Code, err)
+			}
+		}
+
+		for _, account := range update {
+			result, err := tx.Exec(ctx, `
+				UPDATE ledger_accounts
+				SET name = $3, description = $4, metadata = $5, updated_at = $6
+				WHERE tenant_id = $1 AND id = $2
+			`, account.TenantID, account.ID, account.Name, account.Description, account.Metadata, account.UpdatedAt)
+			if err != nil {
+				return fmt.Errorf("updating account %s: %w", account.Code, err)
+			}
+			if result.RowsAffected() == 0 {
+				return database.ErrNotFound
+			}
+		}
+
+		return nil
+	})
+}
 
-	_, err := s.db.Exec(ctx, query,
+func insertAccountArgs(account *domain.Account) []interface{} {
+	return []interface{}{
 		account.ID,
 		account.TenantID,
 		account.Code,
@@ -51,15 +96,42 @@ func (s *Store) CreateAccount(ctx context.Context, account *domain.Account) erro
 		account.IsPlaceholder,
 		account.Status,
 		account.Metadata,
+		account.Constraints.NoNegative,
+		account.Constraints.MinBalance,
+		account.Constraints.MaxBalance,
+		account.Constraints.OverdraftLimit,
 		account.CreatedAt,
 		account.UpdatedAt,
-	)
+	}
+}
 
+// UpdateAccount saves an account's name, description, balance constraints and
+// metadata. Postings hold a share lock on their accounts, so this waits for any in flight.
+func (s *Store) UpdateAccount(ctx context.Context, account *domain.Account) error {
+	query := `
+		UPDATE ledger_accounts
+		SET name = $3, description = $4, no_negative_balance = $5, min_balance = $6,
+			max_balance = $7, overdraft_limit = $8, metadata = $9, updated_at = $10
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	result, err := s.db.Exec(ctx, query,
+		account.TenantID,
+		account.ID,
+		account.Name,
+		account.Description,
+		account.Constraints.NoNegative,
+		account.Constraints.MinBalance,
+		account.Constraints.MaxBalance,
+		account.Constraints.OverdraftLimit,
+		account.Metadata,
+		account.UpdatedAt,
+	)
 	if err != nil {
-		if database.IsUniqueViolation(err) {
-			return fmt.Errorf("account with code %s already exists: %w", account.Code, database.ErrAlreadyExists)
-		}
-		return fmt.Errorf("creating account: %w", err)
+		return fmt.Errorf("updating account: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return database.ErrNotFound
 	}
 
 	return nil
@@ -70,6 +142,7 @@ func (s *Store) GetAccount(ctx context.Context, tenantID, id string) (*domain.Ac
 	query := `
 		SELECT id, tenant_id, code, name, description, account_type, normal_balance,
 			   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+			   no_negative_balance, min_balance, max_balance, overdraft_limit,
 			   created_at, updated_at
 		FROM ledger_accounts
 		WHERE tenant_id = $1 AND id = $2
@@ -84,6 +157,7 @@ func (s *Store) GetAccountByCode(ctx context.Context, tenantID, code string) (*d
 	query := `
 		SELECT id, tenant_id, code, name, description, account_type, normal_balance,
 			   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+			   no_negative_balance, min_balance, max_balance, overdraft_limit,
 			   created_at, updated_at
 		FROM ledger_accounts
 		WHERE tenant_id = $1 AND code = $2
@@ -93,36 +167,74 @@ func (s *Store) GetAccountByCode(ctx context.Context, tenantID, code string) (*d
 	return scanAccount(row)
 }
 
-// ListAccounts lists accounts with optional filters
-func (s *Store) ListAccounts(ctx context.Context, tenantID string, accountType *domain.AccountType, limit, offset int) ([]*domain.Account, int64, error) {
-	countQuery := `SELECT COUNT(*) FROM ledger_accounts WHERE tenant_id = $1`
+// ListAccounts lists up to limit accounts in (code, id) order, starting after the
+// cursor if one is given
+func (s *Store) ListAccounts(ctx context.Context, tenantID string, accountType *domain.AccountType, metadata map[string]string, after *domain.AccountCursor, limit int) ([]*domain.Account, error) {
 	query := `
 		SELECT id, tenant_id, code, name, description, account_type, normal_balance,
 			   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+			   no_negative_balance, min_balance, max_balance, overdraft_limit,
 			   created_at, updated_at
 		FROM ledger_accounts
 		WHERE tenant_id = $1
 	`
 
 	args := []interface{}{tenantID}
+	argIdx := 2
 
 	if accountType != nil {
-		countQuery += ` AND account_type = $2`
-		query += ` AND account_type = $2`
+		query += fmt.Sprintf(` AND account_type = $%d`, argIdx)
 		args = append(args, *accountType)
+		argIdx++
 	}
 
-	var total int64
-	err := s.db.QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("counting accounts: %w", err)
+	if len(metadata) > 0 {
+		query += fmt.Sprintf(` AND metadata @> $%d`, argIdx)
+		args = append(args, metadata)
+		argIdx++
+	}
+
+	if after != nil {
+		query += fmt.Sprintf(` AND (code, id) > ($%d, $%d)`, argIdx, argIdx+1)
+		args = append(args, after.Code, after.ID)
 	}
 
-	query += fmt.Sprintf(` ORDER BY code LIMIT %d OFFSET %d`, limit, offset)
+	query += fmt.Sprintf(` ORDER BY code, id LIMIT %d`, limit)
 
 	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("listing accounts: %w", err)
+		return nil, fmt.Errorf("listing accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*domain.Account
+	for rows.Next() {
+		account, err := scanAccountRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// ListAllAccounts lists every account of a tenant in path order, so parents come
+// before their children
+func (s *Store) ListAllAccounts(ctx context.Context, tenantID string) ([]*domain.Account, error) {
+	query := `
+		SELECT id, tenant_id, code, name, description, account_type, normal_balance,
+			   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+			   no_negative_balance, min_balance, max_balance, overdraft_limit,
+			   created_at, updated_at
+		FROM ledger_accounts
+		WHERE tenant_id = $1
+		ORDER BY path, id
+	`
+
+	rows, err := s.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listing accounts: %w", err)
 	}
 	defer rows.Close()
 
@@ -130,12 +242,161 @@ func (s *Store) ListAccounts(ctx context.Context, tenantID string, accountType *
 	for rows.Next() {
 		account, err := scanAccountRows(rows)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		accounts = append(accounts, account)
 	}
 
-	return accounts, total, nil
+	return accounts, rows.Err()
+}
+
+// CountAccounts counts a tenant's accounts, optionally of one type and with
+// metadata containing the given pairs
+func (s *Store) CountAccounts(ctx context.Context, tenantID string, accountType *domain.AccountType, metadata map[string]string) (int64, error) {
+	query := `SELECT COUNT(*) FROM ledger_accounts WHERE tenant_id = $1`
+	args := []interface{}{tenantID}
+
+	if accountType != nil {
+		args = append(args, *accountType)
+		query += fmt.Sprintf(` AND account_type = $%d`, len(args))
+	}
+
+	if len(metadata) > 0 {
+		args = append(args, metadata)
+		query += fmt.Sprintf(` AND metadata @> $%d`, len(args))
+	}
+
+	var total int64
+	if err := s.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting accounts: %w", err)
+	}
+	return total, nil
+}
+
+// ListAccountBalances lists all accounts of a tenant with their posted balance,
+// optionally in one currency. Without from and to the materialised balances are
+// used; otherwise it is the balance of entries dated by basis in [from, to]. By
+// posting date that is derived from the latest final daily position before each
+// bound plus the entries posted since; by value date it is the running balance
+// of the last entry valued before each bound.
+func (s *Store) ListAccountBalances(ctx context.Context, tenantID string, currency money.Currency, basis domain.DateBasis, from, to *time.Time) ([]*domain.AccountBalance, error) {
+	balanceExpr := `COALESCE(b.posted_balance, 0)`
+	joins := `LEFT JOIN ledger_account_balances b ON b.account_id = a.id`
+	args := []interface{}{tenantID}
+
+	if to != nil {
+		args = append(args, *to)
+		if basis == domain.DateBasisValue {
+			joins += valueBalanceJoin("vb_to", len(args), "<=")
+			balanceExpr = `COALESCE(vb_to.balance_after, 0)`
+		} else {
+			joins += checkpointJoin("cp_to", len(args))
+			balanceExpr = balanceAtExpr("cp_to", len(args), "<=")
+		}
+	}
+	if from != nil {
+		args = append(args, *from)
+		if basis == domain.DateBasisValue {
+			joins += valueBalanceJoin("vb_from", len(args), "<")
+			balanceExpr += ` - COALESCE(vb_from.balance_after, 0)`
+		} else {
+			joins += checkpointJoin("cp_from", len(args))
+			balanceExpr += " - " + balanceAtExpr("cp_from", len(args), "<")
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.tenant_id, a.code, a.name, a.description, a.account_type, a.normal_balance,
+			   a.currency, a.parent_id, a.path, a.is_system, a.is_placeholder, a.status, a.metadata,
+			   a.no_negative_balance, a.min_balance, a.max_balance, a.overdraft_limit,
+			   a.created_at, a.updated_at, %s
+		FROM ledger_accounts a
+		%s
+		WHERE a.tenant_id = $1
+	`, balanceExpr, joins)
+
+	if currency != "" {
+		query += fmt.Sprintf(` AND a.currency = $%d`, len(args)+1)
+		args = append(args, currency)
+	}
+
+	query += ` ORDER BY a.currency, a.code`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing account balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*domain.AccountBalance
+	for rows.Next() {
+		var a domain.Account
+		var ab domain.AccountBalance
+		err := rows.Scan(
+			&a.ID, &a.TenantID, &a.Code, &a.Name, &a.Description,
+			&a.AccountType, &a.NormalBalance, &a.Currency, &a.ParentID,
+			&a.Path, &a.IsSystem, &a.IsPlaceholder, &a.Status, &a.Metadata,
+			&a.Constraints.NoNegative, &a.Constraints.MinBalance, &a.Constraints.MaxBalance, &a.Constraints.OverdraftLimit,
+			&a.CreatedAt, &a.UpdatedAt, &ab.Balance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning account balance: %w", err)
+		}
+		ab.Account = &a
+		balances = append(balances, &ab)
+	}
+
+	return balances, rows.Err()
+}
+
+// checkpointJoin joins the latest final daily posting-date position of account a
+// that ended before the day of the timestamp parameter param
+func checkpointJoin(alias string, param int) string {
+	return fmt.Sprintf(`
+		LEFT JOIN LATERAL (
+			SELECT p.period_end, p.closing_balance
+			FROM ledger_positions p
+			WHERE p.account_id = a.id AND p.period_type = 'daily' AND p.date_basis = 'posting'
+			  AND p.period_end < ($%[2]d::timestamptz AT TIME ZONE 'UTC')::date
+			  AND p.updated_at >= (p.period_end + 1)::timestamp AT TIME ZONE 'UTC'
+			ORDER BY p.period_end DESC
+			LIMIT 1
+		) %[1]s ON true`, alias, param)
+}
+
+// balanceAtExpr returns the posted balance of account a from entries posted op the
+// timestamp parameter param, starting from the checkpoint joined as alias
+func balanceAtExpr(alias string, param int, op string) string {
+	return fmt.Sprintf(`(COALESCE(%[1]s.closing_balance, 0) + COALESCE((
+			SELECT SUM(CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END)
+			FROM ledger_entries e
+			WHERE e.account_id = a.id AND e.posted_at IS NOT NULL
+			  AND e.posted_at %[3]s $%[2]d
+			  AND (%[1]s.period_end IS NULL OR e.posted_at >= (%[1]s.period_end + 1)::timestamp AT TIME ZONE 'UTC')
+		), 0))`, alias, param, op)
+}
+
+// valueBalanceJoin joins the last posted entry of account a valued op the day of
+// the timestamp parameter param; its balance_after is the value-dated balance
+func valueBalanceJoin(alias string, param int, op string) string {
+	return fmt.Sprintf(`
+		LEFT JOIN LATERAL (
+			SELECT e.balance_after
+			FROM ledger_entries e
+			WHERE e.account_id = a.id AND e.posted_at IS NOT NULL
+			  AND e.effective_date %[3]s ($%[2]d::timestamptz AT TIME ZONE 'UTC')::date
+			ORDER BY e.effective_date DESC, e.posted_at DESC, e.sequence DESC
+			LIMIT 1
+		) %[1]s ON true`, alias, param, op)
+}
+
+// entryDateCond compares the date of entries aliased e by basis with the
+// timestamp parameter param. By value date only the day of param counts.
+func entryDateCond(basis domain.DateBasis, op string, param int) string {
+	if basis == domain.DateBasisValue {
+		return fmt.Sprintf(`e.effective_date %s ($%d::timestamptz AT TIME ZONE 'UTC')::date`, op, param)
+	}
+	return fmt.Sprintf(`e.posted_at %s $%d`, op, param)
 }
 
 // CreateBatch creates a new ledger batch with entries (within a transaction)
@@ -145,21 +406,32 @@ func (s *Store) CreateBatch(ctx context.Context, batch *domain.Batch) error {
 	})
 }
 
-// CreateBatchTx creates a batch within an existing transaction
+// CreateBatchTx creates a batch within an existing transaction. A batch booked
+// into a period that does not allow it is rejected before anything is written.
 func (s *Store) CreateBatchTx(ctx context.Context, tx pgx.Tx, batch *domain.Batch) error {
 	// Validate batch first
 	if err := batch.Validate(); err != nil {
 		return err
 	}
 
+	if err := s.checkPeriodOpenTx(ctx, tx, batch); err != nil {
+		return err
+	}
+
+	if err := s.checkEntryAccountsTx(ctx, tx, batch); err != nil {
+		return err
+	}
+
 	// Insert batch
 	batchQuery := `
 		INSERT INTO ledger_batches (
 			id, tenant_id, reference, description, source_type, source_id,
 			total_debits, total_credits, entry_count, currency, status,
-			posted_at, posted_by, metadata, created_at
+			effective_date, posted_at, posted_by, reversal_of_batch_id, metadata, created_at,
+			idempotency_key, request_fingerprint
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+			NULLIF($18, ''), NULLIF($19, '')
 		)
 	`
 
@@ -175,28 +447,37 @@ func (s *Store) CreateBatchTx(ctx context.Context, tx pgx.Tx, batch *domain.Batc
 		batch.EntryCount,
 		batch.TotalDebits.Currency,
 		batch.Status,
+		batch.EffectiveDate,
 		batch.PostedAt,
 		batch.PostedBy,
+		batch.ReversalOfID,
 		batch.Metadata,
 		batch.CreatedAt,
+		batch.IdempotencyKey,
+		batch.Fingerprint,
 	)
 	if err != nil {
+		if batch.IdempotencyKey != "" && database.IsUniqueViolation(err) {
+			return fmt.Errorf("batch with idempotency key %s: %w", batch.IdempotencyKey, database.ErrAlreadyExists)
+		}
 		return fmt.Errorf("inserting batch: %w", err)
 	}
 
 	// Insert entries
 	entryQuery := `
 		INSERT INTO ledger_entries (
-			id, batch_id, account_id, entry_type, amount, currency,
-			balance_after, description, sequence, created_at
+			id, tenant_id, batch_id, account_id, entry_type, amount, currency,
+			balance_after, description, sequence, reverses_entry_id, effective_date,
+			posted_at, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)
 	`
 
 	for _, entry := range batch.Entries {
 		_, err := tx.Exec(ctx, entryQuery,
 			entry.ID,
+			batch.TenantID,
 			entry.BatchID,
 			entry.AccountID,
 			entry.EntryType,
@@ -205,6 +486,9 @@ func (s *Store) CreateBatchTx(ctx context.Context, tx pgx.Tx, batch *domain.Batc
 			entry.BalanceAfter,
 			entry.Description,
 			entry.Sequence,
+			entry.ReversesEntryID,
+			entry.EffectiveDate,
+			entry.PostedAt,
 			entry.CreatedAt,
 		)
 		if err != nil {
@@ -212,9 +496,118 @@ func (s *Store) CreateBatchTx(ctx context.Context, tx pgx.Tx, batch *domain.Batc
 		}
 	}
 
+	if batch.Status == domain.BatchStatusPending {
+		if err := s.applyBalanceChangeTx(ctx, tx, batch, balanceAddPending); err != nil {
+			return err
+		}
+	}
+
+	// Insert per-currency totals
+	totalQuery := `
+		INSERT INTO ledger_batch_totals (
+			batch_id, currency, total_debits, total_credits, entry_count
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+	`
+
+	for _, total := range batch.CurrencyTotals() {
+		_, err := tx.Exec(ctx, totalQuery,
+			batch.ID,
+			total.Currency,
+			total.TotalDebits,
+			total.TotalCredits,
+			total.EntryCount,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting batch total: %w", err)
+		}
+	}
+
+	// Insert FX legs
+	fxQuery := `
+		INSERT INTO ledger_fx_legs (
+			id, batch_id, source_amount, source_currency, counter_amount,
+			counter_currency, rate, source_position_account_id,
+			counter_position_account_id, revenue_account_id, revenue_amount, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7::numeric, $8, $9, $10, $11, $12
+		)
+	`
+
+	for _, leg := range batch.FXLegs {
+		_, err := tx.Exec(ctx, fxQuery,
+			leg.ID,
+			batch.ID,
+			leg.SourceAmount.AmountMinor,
+			leg.SourceAmount.Currency,
+			leg.CounterAmount.AmountMinor,
+			leg.CounterAmount.Currency,
+			leg.Rate,
+			leg.SourcePositionAccountID,
+			leg.CounterPositionAccountID,
+			nullableString(leg.RevenueAccountID),
+			leg.RevenueAmount,
+			batch.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting fx leg: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// GetAccountsByID loads the accounts of a tenant with the given IDs in one query,
+// keyed by ID. IDs that are not found are left out.
+func (s *Store) GetAccountsByID(ctx context.Context, tenantID string, ids []string) (map[string]*domain.Account, error) {
+	return s.getAccounts(ctx, s.db, tenantID, ids, false)
+}
+
+// checkEntryAccountsTx checks the batch's entries against their accounts again
+// within the transaction, share-locking the accounts so that none can be closed
+// until it commits
+func (s *Store) checkEntryAccountsTx(ctx context.Context, tx pgx.Tx, batch *domain.Batch) error {
+	accounts, err := s.getAccounts(ctx, tx, batch.TenantID, batch.AccountIDs(), true)
+	if err != nil {
+		return err
+	}
+
+	return domain.CheckEntryAccounts(batch, accounts)
+}
+
+func (s *Store) getAccounts(ctx context.Context, q database.Querier, tenantID string, ids []string, forShare bool) (map[string]*domain.Account, error) {
+	query := `
+		SELECT id, tenant_id, code, name, description, account_type, normal_balance,
+			   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+			   no_negative_balance, min_balance, max_balance, overdraft_limit,
+			   created_at, updated_at
+		FROM ledger_accounts
+		WHERE tenant_id = $1 AND id = ANY($2)
+		ORDER BY id
+	`
+	if forShare {
+		query += ` FOR SHARE`
+	}
+
+	rows, err := q.Query(ctx, query, tenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("getting accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make(map[string]*domain.Account, len(ids))
+	for rows.Next() {
+		account, err := scanAccountRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts[account.ID] = account
+	}
+
+	return accounts, rows.Err()
+}
+
 // PostBatch posts a pending batch (updates status and calculates balances)
 func (s *Store) PostBatch(ctx context.Context, tenantID, batchID, userID string) error {
 	return s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
@@ -225,136 +618,451 @@ func (s *Store) PostBatch(ctx context.Context, tenantID, batchID, userID string)
 		}
 
 		if batch.Status != domain.BatchStatusPending {
-			return errors.New("batch is not pending")
+			return domain.ErrBatchNotPending
 		}
 
 		// Get entries
-		entries, err := s.getEntriesTx(ctx, tx, batchID)
+		batch.Entries, err = s.getEntriesTx(ctx, tx, batchID)
 		if err != nil {
 			return err
 		}
 
-		// Update balances for each account
-		for _, entry := range entries {
-			// Get current balance
-			var currentBalance int64
-			err := tx.QueryRow(ctx, `
-				SELECT COALESCE(
-					(SELECT balance_after FROM ledger_entries
-					 WHERE account_id = $1 AND balance_after IS NOT NULL
-					 ORDER BY created_at DESC LIMIT 1),
-					0
-				)
-			`, entry.AccountID).Scan(&currentBalance)
-			if err != nil {
-				return fmt.Errorf("getting current balance: %w", err)
-			}
-
-			// Get account to determine normal balance
-			var normalBalance domain.NormalBalance
-			err = tx.QueryRow(ctx, `
-				SELECT normal_balance FROM ledger_accounts WHERE id = $1
-			`, entry.AccountID).Scan(&normalBalance)
-			if err != nil {
-				return fmt.Errorf("getting account: %w", err)
-			}
+		// Accounts may have been closed since the batch was created
+		if err := s.checkEntryAccountsTx(ctx, tx, batch); err != nil {
+			return err
+		}
 
-			// Calculate new balance
-			var newBalance int64
-			if normalBalance == domain.NormalBalanceDebit {
-				if entry.EntryType == domain.EntryTypeDebit {
-					newBalance = currentBalance + entry.Amount.AmountMinor
-				} else {
-					newBalance = currentBalance - entry.Amount.AmountMinor
-				}
-			} else {
-				if entry.EntryType == domain.EntryTypeCredit {
-					newBalance = currentBalance + entry.Amount.AmountMinor
-				} else {
-					newBalance = currentBalance - entry.Amount.AmountMinor
-				}
-			}
+		if err := batch.Post(userID); err != nil {
+			return err
+		}
 
-			// Update entry with balance
-			_, err = tx.Exec(ctx, `
-				UPDATE ledger_entries SET balance_after = $1 WHERE id = $2
-			`, newBalance, entry.ID)
-			if err != nil {
-				return fmt.Errorf("updating entry balance: %w", err)
-			}
+		if err := s.applyBalanceChangeTx(ctx, tx, batch, balancePostPending); err != nil {
+			return err
 		}
 
 		// Mark batch as posted
-		now := time.Now().UTC()
 		_, err = tx.Exec(ctx, `
 			UPDATE ledger_batches
 			SET status = $1, posted_at = $2, posted_by = $3
 			WHERE id = $4
-		`, domain.BatchStatusPosted, now, userID, batchID)
+		`, batch.Status, batch.PostedAt, batch.PostedBy, batchID)
 		if err != nil {
 			return fmt.Errorf("posting batch: %w", err)
 		}
 
-		return nil
+		return s.assignPostingSequenceTx(ctx, tx, batch)
 	})
 }
 
-// GetBatch retrieves a batch by ID
-func (s *Store) GetBatch(ctx context.Context, tenantID, id string) (*domain.Batch, error) {
-	query := `
-		SELECT id, tenant_id, reference, description, source_type, source_id,
-			   total_debits, total_credits, entry_count, currency, status,
-			   posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
-			   metadata, created_at
-		FROM ledger_batches
-		WHERE tenant_id = $1 AND id = $2
-	`
-
-	row := s.db.QueryRow(ctx, query, tenantID, id)
-	return scanBatch(row)
-}
+// PostNewBatch creates a batch and posts it within one serializable transaction,
+// so a posting rejected by its accounts' balance constraints leaves nothing behind
+func (s *Store) PostNewBatch(ctx context.Context, batch *domain.Batch, userID string) error {
+	return s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
+		if err := batch.Post(userID); err != nil {
+			return err
+		}
 
-// GetBatchWithEntries retrieves a batch with its entries
-func (s *Store) GetBatchWithEntries(ctx context.Context, tenantID, id string) (*domain.Batch, error) {
-	batch, err := s.GetBatch(ctx, tenantID, id)
-	if err != nil {
-		return nil, err
-	}
+		if err := s.CreateBatchTx(ctx, tx, batch); err != nil {
+			return err
+		}
 
-	entries, err := s.GetEntries(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+		if err := s.applyBalanceChangeTx(ctx, tx, batch, balancePostNew); err != nil {
+			return err
+		}
 
-	batch.Entries = entries
-	return batch, nil
+		return s.assignPostingSequenceTx(ctx, tx, batch)
+	})
 }
 
-// GetEntries retrieves entries for a batch
-func (s *Store) GetEntries(ctx context.Context, batchID string) ([]*domain.Entry, error) {
-	query := `
-		SELECT id, batch_id, account_id, entry_type, amount, currency,
-			   balance_after, description, sequence, created_at
-		FROM ledger_entries
-		WHERE batch_id = $1
-		ORDER BY sequence
-	`
+// VoidBatch discards a pending batch
+func (s *Store) VoidBatch(ctx context.Context, tenantID, batchID, userID string) error {
+	return s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		batch, err := s.getBatchForUpdate(ctx, tx, tenantID, batchID)
+		if err != nil {
+			return err
+		}
 
-	rows, err := s.db.Query(ctx, query, batchID)
-	if err != nil {
-		return nil, fmt.Errorf("getting entries: %w", err)
-	}
-	defer rows.Close()
+		if err := batch.Void(userID); err != nil {
+			return err
+		}
 
-	return scanEntries(rows)
-}
+		batch.Entries, err = s.getEntriesTx(ctx, tx, batch.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.applyBalanceChangeTx(ctx, tx, batch, balanceDropPending); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE ledger_batches
+			SET status = $1, voided_at = $2, voided_by = $3
+			WHERE id = $4
+		`, batch.Status, batch.VoidedAt, batch.VoidedBy, batch.ID)
+		if err != nil {
+			return fmt.Errorf("voiding batch: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReverseBatch posts a batch that mirrors all or part of a posted batch, all
+// within one serializable transaction. The original is locked and passed to build
+// with the unreversed amount of each entry loaded; build returns the reversal batch.
+// The original is marked reversed once its full total has been reversed.
+func (s *Store) ReverseBatch(ctx context.Context, tenantID, originalID, userID, reason string, build func(original *domain.Batch) (*domain.Batch, error)) (*domain.Batch, error) {
+	var reversal *domain.Batch
+	err := s.db.WithTxOptions(ctx, database.SerializableTxOptions(), func(tx pgx.Tx) error {
+		original, err := s.getBatchForUpdate(ctx, tx, tenantID, originalID)
+		if err != nil {
+			return err
+		}
+
+		if original.Status != domain.BatchStatusPosted {
+			return domain.ErrBatchNotPosted
+		}
+
+		original.Entries, err = s.getEntriesTx(ctx, tx, original.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.loadReversedAmountsTx(ctx, tx, original.Entries); err != nil {
+			return err
+		}
+		if err := s.loadBatchCurrencyData(ctx, tx, original); err != nil {
+			return err
+		}
+
+		reversal, err = build(original)
+		if err != nil {
+			return err
+		}
+
+		if err := original.ApplyReversal(reversal.TotalDebits.AmountMinor, userID, reason); err != nil {
+			return err
+		}
+
+		if err := reversal.Post(userID); err != nil {
+			return err
+		}
+		reversal.ReversalOfID = &original.ID
+
+		if err := s.CreateBatchTx(ctx, tx, reversal); err != nil {
+			return err
+		}
+
+		if err := s.applyBalanceChangeTx(ctx, tx, reversal, balancePostNew); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE ledger_batches
+			SET status = $1, reversed_amount = $2, reversed_at = $3,
+				reversed_by = $4, reversal_reason = $5
+			WHERE id = $6
+		`, original.Status, original.ReversedAmount, original.ReversedAt,
+			original.ReversedBy, nullableString(original.ReversalReason), original.ID)
+		if err != nil {
+			return fmt.Errorf("marking batch reversed: %w", err)
+		}
+
+		return s.assignPostingSequenceTx(ctx, tx, reversal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// assignPostingSequenceTx gives a batch being posted the next number in its tenant's
+// posting sequence and links it into the tenant's hash chain. The tenant's counter
+// row stays locked until the transaction ends, so numbers have no gaps and commit
+// in order; call it last, with the batch's entries loaded.
+func (s *Store) assignPostingSequenceTx(ctx context.Context, tx pgx.Tx, batch *domain.Batch) error {
+	last, previous, err := s.lockPostingSequenceTx(ctx, tx, batch.TenantID)
+	if err != nil {
+		return err
+	}
+
+	seq := last + 1
+	batch.PostingSequence = &seq
+	batch.ContentHash = batch.ComputeContentHash()
+	batch.ChainHash = domain.ChainHash(previous, batch.ContentHash)
+
+	if err := s.setPostingSequenceTx(ctx, tx, batch.TenantID, seq, batch.ChainHash); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ledger_batches
+		SET posting_sequence = $1, content_hash = $2, chain_hash = $3
+		WHERE id = $4
+	`, seq, batch.ContentHash, batch.ChainHash, batch.ID)
+	if err != nil {
+		return fmt.Errorf("assigning posting sequence: %w", err)
+	}
+
+	return nil
+}
+
+// lockPostingSequenceTx locks a tenant's posting sequence counter, creating it
+// first if needed, and returns the last sequence and chain hash assigned
+func (s *Store) lockPostingSequenceTx(ctx context.Context, tx pgx.Tx, tenantID string) (int64, string, error) {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO ledger_sequences (tenant_id) VALUES ($1)
+		ON CONFLICT (tenant_id) DO NOTHING
+	`, tenantID)
+	if err != nil {
+		return 0, "", fmt.Errorf("assigning posting sequence: %w", err)
+	}
+
+	var last int64
+	var hash string
+	err = tx.QueryRow(ctx, `
+		SELECT last_sequence, last_hash
+		FROM ledger_sequences
+		WHERE tenant_id = $1
+		FOR UPDATE
+	`, tenantID).Scan(&last, &hash)
+	if err != nil {
+		return 0, "", fmt.Errorf("assigning posting sequence: %w", err)
+	}
+
+	return last, hash, nil
+}
+
+// setPostingSequenceTx moves a tenant's locked counter to the last sequence and
+// chain hash assigned
+func (s *Store) setPostingSequenceTx(ctx context.Context, tx pgx.Tx, tenantID string, seq int64, hash string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE ledger_sequences
+		SET last_sequence = $2, last_hash = $3, updated_at = NOW()
+		WHERE tenant_id = $1
+	`, tenantID, seq, hash)
+	if err != nil {
+		return fmt.Errorf("assigning posting sequence: %w", err)
+	}
+	return nil
+}
+
+// ListFeed lists up to limit posted batches with their entries, in posting
+// sequence order starting after the given sequence
+func (s *Store) ListFeed(ctx context.Context, tenantID string, after int64, limit int) ([]*domain.Batch, error) {
+	query := `
+		SELECT id, tenant_id, reference, description, source_type, source_id,
+			   total_debits, total_credits, entry_count, currency, status,
+			   effective_date, posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   reversed_amount, reversal_of_batch_id, voided_at, voided_by,
+			   idempotency_key, request_fingerprint, posting_sequence, content_hash, chain_hash,
+			   metadata, created_at
+		FROM ledger_batches
+		WHERE tenant_id = $1 AND posting_sequence > $2
+		ORDER BY posting_sequence
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(ctx, query, tenantID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing feed: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*domain.Batch
+	byID := make(map[string]*domain.Batch)
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+		byID[batch.ID] = batch
+		ids = append(ids, batch.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return batches, nil
+	}
+
+	entryRows, err := s.db.Query(ctx, `
+		SELECT id, batch_id, account_id, entry_type, amount, currency,
+			   balance_after, description, sequence, reverses_entry_id, effective_date,
+			   posted_at, created_at
+		FROM ledger_entries
+		WHERE batch_id = ANY($1)
+		ORDER BY batch_id, sequence
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("listing feed entries: %w", err)
+	}
+	defer entryRows.Close()
+
+	entries, err := scanEntries(entryRows)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		batch := byID[entry.BatchID]
+		batch.Entries = append(batch.Entries, entry)
+	}
+
+	return batches, nil
+}
 
-// GetAccountEntries retrieves entries for an account
-func (s *Store) GetAccountEntries(ctx context.Context, accountID string, from, to *time.Time, limit, offset int) ([]*domain.Entry, int64, error) {
-	countQuery := `SELECT COUNT(*) FROM ledger_entries WHERE account_id = $1`
+// GetBatch retrieves a batch by ID
+func (s *Store) GetBatch(ctx context.Context, tenantID, id string) (*domain.Batch, error) {
+	query := `
+		SELECT id, tenant_id, reference, description, source_type, source_id,
+			   total_debits, total_credits, entry_count, currency, status,
+			   effective_date, posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   reversed_amount, reversal_of_batch_id, voided_at, voided_by,
+			   idempotency_key, request_fingerprint, posting_sequence, content_hash, chain_hash,
+			   metadata, created_at
+		FROM ledger_batches
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := s.db.QueryRow(ctx, query, tenantID, id)
+	return scanBatch(row)
+}
+
+// GetBatchByIdempotencyKey retrieves the batch created with an idempotency key
+func (s *Store) GetBatchByIdempotencyKey(ctx context.Context, tenantID, key string) (*domain.Batch, error) {
+	query := `
+		SELECT id, tenant_id, reference, description, source_type, source_id,
+			   total_debits, total_credits, entry_count, currency, status,
+			   effective_date, posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   reversed_amount, reversal_of_batch_id, voided_at, voided_by,
+			   idempotency_key, request_fingerprint, posting_sequence, content_hash, chain_hash,
+			   metadata, created_at
+		FROM ledger_batches
+		WHERE tenant_id = $1 AND idempotency_key = $2
+	`
+
+	row := s.db.QueryRow(ctx, query, tenantID, key)
+	return scanBatch(row)
+}
+
+// GetBatchWithEntries retrieves a batch with its entries
+func (s *Store) GetBatchWithEntries(ctx context.Context, tenantID, id string) (*domain.Batch, error) {
+	batch, err := s.GetBatch(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.GetEntries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	batch.Entries = entries
+
+	if err := s.loadBatchCurrencyData(ctx, s.db, batch); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// loadBatchCurrencyData loads the per-currency totals and FX legs of a batch
+func (s *Store) loadBatchCurrencyData(ctx context.Context, q database.Querier, batch *domain.Batch) error {
+	rows, err := q.Query(ctx, `
+		SELECT currency, total_debits, total_credits, entry_count
+		FROM ledger_batch_totals
+		WHERE batch_id = $1
+	`, batch.ID)
+	if err != nil {
+		return fmt.Errorf("getting batch totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.CurrencyTotal
+	for rows.Next() {
+		var t domain.CurrencyTotal
+		var currency string
+		if err := rows.Scan(&currency, &t.TotalDebits, &t.TotalCredits, &t.EntryCount); err != nil {
+			return fmt.Errorf("scanning batch total: %w", err)
+		}
+		t.Currency = money.Currency(currency)
+		totals = append(totals, t)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	if len(totals) > 1 {
+		domain.SortCurrencyTotals(totals, batch.TotalDebits.Currency)
+		batch.Totals = totals
+	}
+
+	legRows, err := q.Query(ctx, `
+		SELECT id, batch_id, source_amount, source_currency, counter_amount,
+			   counter_currency, rate::text, source_position_account_id,
+			   counter_position_account_id, revenue_account_id, revenue_amount, created_at
+		FROM ledger_fx_legs
+		WHERE batch_id = $1
+		ORDER BY id
+	`, batch.ID)
+	if err != nil {
+		return fmt.Errorf("getting fx legs: %w", err)
+	}
+	defer legRows.Close()
+
+	for legRows.Next() {
+		var leg domain.FXLeg
+		var sourceAmount, counterAmount int64
+		var sourceCurrency, counterCurrency string
+		var revenueAccountID *string
+		err := legRows.Scan(
+			&leg.ID, &leg.BatchID, &sourceAmount, &sourceCurrency, &counterAmount,
+			&counterCurrency, &leg.Rate, &leg.SourcePositionAccountID,
+			&leg.CounterPositionAccountID, &revenueAccountID, &leg.RevenueAmount, &leg.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("scanning fx leg: %w", err)
+		}
+		leg.SourceAmount = money.New(sourceAmount, money.Currency(sourceCurrency))
+		leg.CounterAmount = money.New(counterAmount, money.Currency(counterCurrency))
+		leg.RevenueAccountID = derefString(revenueAccountID)
+		batch.FXLegs = append(batch.FXLegs, &leg)
+	}
+
+	return legRows.Err()
+}
+
+// GetEntries retrieves entries for a batch
+func (s *Store) GetEntries(ctx context.Context, batchID string) ([]*domain.Entry, error) {
+	query := `
+		SELECT id, batch_id, account_id, entry_type, amount, currency,
+			   balance_after, description, sequence, reverses_entry_id, effective_date,
+			   posted_at, created_at
+		FROM ledger_entries
+		WHERE batch_id = $1
+		ORDER BY sequence
+	`
+
+	rows, err := s.db.Query(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("getting entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// GetAccountEntries lists up to limit entries of an account created in [from, to],
+// optionally of batches whose metadata contains the given pairs, in (created_at,
+// id) order, starting after the cursor if one is given
+func (s *Store) GetAccountEntries(ctx context.Context, accountID string, from, to *time.Time, metadata map[string]string, after *domain.EntryCursor, descending bool, limit int) ([]*domain.Entry, error) {
 	query := `
 		SELECT id, batch_id, account_id, entry_type, amount, currency,
-			   balance_after, description, sequence, created_at
+			   balance_after, description, sequence, reverses_entry_id, effective_date,
+			   posted_at, created_at
 		FROM ledger_entries
 		WHERE account_id = $1
 	`
@@ -362,63 +1070,601 @@ func (s *Store) GetAccountEntries(ctx context.Context, accountID string, from, t
 	argIdx := 2
 
 	if from != nil {
-		countQuery += fmt.Sprintf(` AND created_at >= $%d`, argIdx)
 		query += fmt.Sprintf(` AND created_at >= $%d`, argIdx)
 		args = append(args, *from)
 		argIdx++
 	}
 
 	if to != nil {
-		countQuery += fmt.Sprintf(` AND created_at <= $%d`, argIdx)
 		query += fmt.Sprintf(` AND created_at <= $%d`, argIdx)
 		args = append(args, *to)
+		argIdx++
 	}
 
-	var total int64
-	err := s.db.QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("counting entries: %w", err)
+	if len(metadata) > 0 {
+		query += fmt.Sprintf(` AND batch_id IN (SELECT id FROM ledger_batches WHERE metadata @> $%d)`, argIdx)
+		args = append(args, metadata)
+		argIdx++
 	}
 
-	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT %d OFFSET %d`, limit, offset)
+	op, order := ">", "ASC"
+	if descending {
+		op, order = "<", "DESC"
+	}
+
+	if after != nil {
+		query += fmt.Sprintf(` AND (created_at, id) %s ($%d, $%d)`, op, argIdx, argIdx+1)
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query += fmt.Sprintf(` ORDER BY created_at %s, id %s LIMIT %d`, order, order, limit)
 
 	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("listing entries: %w", err)
+		return nil, fmt.Errorf("listing entries: %w", err)
 	}
 	defer rows.Close()
 
-	entries, err := scanEntries(rows)
-	return entries, total, err
+	return scanEntries(rows)
 }
 
-// GetAccountBalance retrieves the current balance for an account
-func (s *Store) GetAccountBalance(ctx context.Context, accountID string) (int64, error) {
+// CountAccountEntries counts an account's entries created in [from, to],
+// optionally of batches whose metadata contains the given pairs
+func (s *Store) CountAccountEntries(ctx context.Context, accountID string, from, to *time.Time, metadata map[string]string) (int64, error) {
+	query := `SELECT COUNT(*) FROM ledger_entries WHERE account_id = $1`
+	args := []interface{}{accountID}
+	argIdx := 2
+
+	if from != nil {
+		query += fmt.Sprintf(` AND created_at >= $%d`, argIdx)
+		args = append(args, *from)
+		argIdx++
+	}
+
+	if to != nil {
+		query += fmt.Sprintf(` AND created_at <= $%d`, argIdx)
+		args = append(args, *to)
+		argIdx++
+	}
+
+	if len(metadata) > 0 {
+		query += fmt.Sprintf(` AND batch_id IN (SELECT id FROM ledger_batches WHERE metadata @> $%d)`, argIdx)
+		args = append(args, metadata)
+	}
+
+	var total int64
+	if err := s.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting entries: %w", err)
+	}
+	return total, nil
+}
+
+// GetAccountBalance retrieves the materialised balance of an account
+func (s *Store) GetAccountBalance(ctx context.Context, accountID string) (*domain.Balance, error) {
 	query := `
-		SELECT COALESCE(
-			(SELECT balance_after FROM ledger_entries
-			 WHERE account_id = $1 AND balance_after IS NOT NULL
-			 ORDER BY created_at DESC LIMIT 1),
-			0
-		)
+		SELECT a.id, a.currency, a.normal_balance,
+			   a.no_negative_balance, a.min_balance, a.max_balance, a.overdraft_limit,
+			   COALESCE(b.posted_balance, 0), COALESCE(b.pending_balance, 0),
+			   COALESCE(b.total_debits, 0), COALESCE(b.total_credits, 0),
+			   COALESCE(b.version, 0), COALESCE(b.updated_at, a.created_at)
+		FROM ledger_accounts a
+		LEFT JOIN ledger_account_balances b ON b.account_id = a.id
+		WHERE a.id = $1
+	`
+
+	row := s.db.QueryRow(ctx, query, accountID)
+	return scanBalance(row)
+}
+
+// GetBalanceCheckpoint returns the latest posting-date position of an account
+// that closed on or before the given date. Positions computed before their period
+// ended are not final and never used. ok is false if there is none.
+func (s *Store) GetBalanceCheckpoint(ctx context.Context, accountID string, onOrBefore time.Time) (periodEnd time.Time, closing int64, ok bool, err error) {
+	query := `
+		SELECT period_end, closing_balance
+		FROM ledger_positions
+		WHERE account_id = $1 AND date_basis = 'posting'
+		  AND period_end <= ($2::timestamptz AT TIME ZONE 'UTC')::date
+		  AND updated_at >= (period_end + 1)::timestamp AT TIME ZONE 'UTC'
+		ORDER BY period_end DESC
+		LIMIT 1
+	`
+
+	err = s.db.QueryRow(ctx, query, accountID, onOrBefore).Scan(&periodEnd, &closing)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, 0, false, nil
+		}
+		return time.Time{}, 0, false, fmt.Errorf("getting balance checkpoint: %w", err)
+	}
+
+	return periodEnd, closing, true, nil
+}
+
+// SumPostedEntries returns the net effect on an account of entries posted in
+// [after, through]. A nil after sums from the beginning.
+func (s *Store) SumPostedEntries(ctx context.Context, accountID string, after *time.Time, through time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(
+			CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END
+		), 0)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE e.account_id = $1 AND e.posted_at IS NOT NULL AND e.posted_at <= $2
+	`
+	args := []interface{}{accountID, through}
+
+	if after != nil {
+		query += ` AND e.posted_at >= $3`
+		args = append(args, *after)
+	}
+
+	var sum int64
+	err := s.db.QueryRow(ctx, query, args...).Scan(&sum)
+	if err != nil {
+		return 0, fmt.Errorf("summing entries: %w", err)
+	}
+
+	return sum, nil
+}
+
+// GetValueDatedBalance returns the posted balance of an account from entries
+// valued on or before the day of on. That is the running balance of the last
+// such entry, since balance_after is kept in value date order.
+func (s *Store) GetValueDatedBalance(ctx context.Context, accountID string, on time.Time) (int64, error) {
+	query := `
+		SELECT balance_after
+		FROM ledger_entries
+		WHERE account_id = $1 AND posted_at IS NOT NULL
+		  AND effective_date <= ($2::timestamptz AT TIME ZONE 'UTC')::date
+		ORDER BY effective_date DESC, posted_at DESC, sequence DESC
+		LIMIT 1
 	`
 
 	var balance int64
-	err := s.db.QueryRow(ctx, query, accountID).Scan(&balance)
+	err := s.db.QueryRow(ctx, query, accountID, on).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("getting value dated balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ListPostableAccounts lists the non-placeholder accounts of a tenant, or of all
+// tenants when tenantID is empty
+func (s *Store) ListPostableAccounts(ctx context.Context, tenantID string) ([]*domain.Account, error) {
+	query := `
+		SELECT id, tenant_id, code, name, description, account_type, normal_balance,
+			   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+			   no_negative_balance, min_balance, max_balance, overdraft_limit,
+			   created_at, updated_at
+		FROM ledger_accounts
+		WHERE is_placeholder = false AND ($1 = '' OR tenant_id = $1)
+		ORDER BY id
+	`
+
+	rows, err := s.db.Query(ctx, query, tenantID)
 	if err != nil {
-		return 0, fmt.Errorf("getting balance: %w", err)
+		return nil, fmt.Errorf("listing accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*domain.Account
+	for rows.Next() {
+		account, err := scanAccountRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// GetBalanceBefore returns the posted balance of an account from entries dated
+// by basis before t
+func (s *Store) GetBalanceBefore(ctx context.Context, accountID string, basis domain.DateBasis, t time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(
+			CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END
+		), 0)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE e.account_id = $1 AND e.posted_at IS NOT NULL AND ` + entryDateCond(basis, "<", 2)
+
+	var balance int64
+	if err := s.db.QueryRow(ctx, query, accountID, t).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("getting opening balance: %w", err)
 	}
 
 	return balance, nil
 }
 
+// GetBalanceBetween returns the change in the posted balance of an account from
+// entries dated by basis in [from, to)
+func (s *Store) GetBalanceBetween(ctx context.Context, accountID string, basis domain.DateBasis, from, to time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(
+			CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END
+		), 0)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE e.account_id = $1 AND e.posted_at IS NOT NULL
+		  AND ` + entryDateCond(basis, ">=", 2) + ` AND ` + entryDateCond(basis, "<", 3)
+
+	var balance int64
+	if err := s.db.QueryRow(ctx, query, accountID, from, to).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("getting balance change: %w", err)
+	}
+
+	return balance, nil
+}
+
+// GetDailyTotals returns the posted activity of an account per day (UTC) for
+// entries dated by basis in [from, to)
+func (s *Store) GetDailyTotals(ctx context.Context, accountID string, basis domain.DateBasis, from, to time.Time) ([]domain.DayTotal, error) {
+	day := `(e.posted_at AT TIME ZONE 'UTC')::date`
+	if basis == domain.DateBasisValue {
+		day = `e.effective_date`
+	}
+
+	query := `
+		SELECT ` + day + ` AS day,
+			   COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'debit'), 0),
+			   COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'credit'), 0),
+			   COUNT(*)
+		FROM ledger_entries e
+		WHERE e.account_id = $1 AND e.posted_at IS NOT NULL
+		  AND ` + entryDateCond(basis, ">=", 2) + ` AND ` + entryDateCond(basis, "<", 3) + `
+		GROUP BY day
+		ORDER BY day
+	`
+
+	rows, err := s.db.Query(ctx, query, accountID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting daily totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.DayTotal
+	for rows.Next() {
+		var d domain.DayTotal
+		if err := rows.Scan(&d.Day, &d.Debits, &d.Credits, &d.EntryCount); err != nil {
+			return nil, fmt.Errorf("scanning daily total: %w", err)
+		}
+		totals = append(totals, d)
+	}
+
+	return totals, rows.Err()
+}
+
+// UpsertPositions writes positions, replacing any already computed for the same
+// account, period and date basis
+func (s *Store) UpsertPositions(ctx context.Context, positions []*domain.Position) error {
+	query := `
+		INSERT INTO ledger_positions (
+			id, tenant_id, account_id, period_type, period_start, period_end,
+			opening_balance, debit_total, credit_total, closing_balance,
+			entry_count, currency, date_basis, is_stale, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, false, NOW(), NOW()
+		)
+		ON CONFLICT (account_id, period_type, period_start, date_basis) DO UPDATE SET
+			period_end = EXCLUDED.period_end,
+			opening_balance = EXCLUDED.opening_balance,
+			debit_total = EXCLUDED.debit_total,
+			credit_total = EXCLUDED.credit_total,
+			closing_balance = EXCLUDED.closing_balance,
+			entry_count = EXCLUDED.entry_count,
+			is_stale = false,
+			updated_at = NOW()
+	`
+
+	return s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		for _, p := range positions {
+			_, err := tx.Exec(ctx, query,
+				p.ID,
+				p.TenantID,
+				p.AccountID,
+				p.PeriodType,
+				p.PeriodStart,
+				p.PeriodEnd,
+				p.OpeningBalance,
+				p.DebitTotal,
+				p.CreditTotal,
+				p.ClosingBalance,
+				p.EntryCount,
+				p.Currency,
+				p.DateBasis,
+			)
+			if err != nil {
+				return fmt.Errorf("upserting position: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListPositions lists the positions of an account of one period type and date
+// basis, optionally limited to periods overlapping [from, to]
+func (s *Store) ListPositions(ctx context.Context, accountID, periodType string, basis domain.DateBasis, from, to *time.Time) ([]*domain.Position, error) {
+	query := `
+		SELECT id, tenant_id, account_id, period_type, period_start, period_end,
+			   opening_balance, debit_total, credit_total, closing_balance,
+			   entry_count, currency, date_basis, is_stale, created_at, updated_at
+		FROM ledger_positions
+		WHERE account_id = $1 AND period_type = $2 AND date_basis = $3
+	`
+	args := []interface{}{accountID, periodType, basis}
+
+	if from != nil {
+		args = append(args, *from)
+		query += fmt.Sprintf(` AND period_end >= ($%d::timestamptz AT TIME ZONE 'UTC')::date`, len(args))
+	}
+	if to != nil {
+		args = append(args, *to)
+		query += fmt.Sprintf(` AND period_start <= ($%d::timestamptz AT TIME ZONE 'UTC')::date`, len(args))
+	}
+
+	query += ` ORDER BY period_start`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []*domain.Position
+	for rows.Next() {
+		p, err := scanPosition(rows)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, p)
+	}
+
+	return positions, rows.Err()
+}
+
+// GetPosition retrieves the position of an account for the period of a type and
+// date basis starting on periodStart
+func (s *Store) GetPosition(ctx context.Context, accountID, periodType string, basis domain.DateBasis, periodStart time.Time) (*domain.Position, error) {
+	query := `
+		SELECT id, tenant_id, account_id, period_type, period_start, period_end,
+			   opening_balance, debit_total, credit_total, closing_balance,
+			   entry_count, currency, date_basis, is_stale, created_at, updated_at
+		FROM ledger_positions
+		WHERE account_id = $1 AND period_type = $2 AND date_basis = $3
+		  AND period_start = ($4::timestamptz AT TIME ZONE 'UTC')::date
+	`
+
+	return scanPosition(s.db.QueryRow(ctx, query, accountID, periodType, basis, periodStart))
+}
+
+// ListStalePositions returns, per account of a tenant (all tenants if empty), the
+// days spanned by its stale positions
+func (s *Store) ListStalePositions(ctx context.Context, tenantID string) (map[string]domain.DateRange, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT account_id, MIN(period_start), MAX(period_end)
+		FROM ledger_positions
+		WHERE is_stale AND ($1 = '' OR tenant_id = $1)
+		GROUP BY account_id
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listing stale positions: %w", err)
+	}
+	defer rows.Close()
+
+	ranges := make(map[string]domain.DateRange)
+	for rows.Next() {
+		var accountID string
+		var r domain.DateRange
+		if err := rows.Scan(&accountID, &r.From, &r.To); err != nil {
+			return nil, fmt.Errorf("scanning stale positions: %w", err)
+		}
+		ranges[accountID] = r
+	}
+
+	return ranges, rows.Err()
+}
+
 // Helper functions
 
+// balanceChange is how a batch transition moves its entries through the balances
+type balanceChange int
+
+const (
+	balanceAddPending  balanceChange = iota // A pending batch was created
+	balanceDropPending                      // A pending batch was voided
+	balancePostPending                      // A pending batch was posted
+	balancePostNew                          // A batch was created posted
+)
+
+// applyBalanceChangeTx updates the materialised balances of the batch's accounts
+// and, when posting, stores the running balance and posting time on each entry.
+// Running balances are kept in value date order, so posting a batch valued before
+// entries already posted also moves their running balances. A posting that takes
+// an account past its balance constraints fails with a *domain.BalanceConstraintError.
+func (s *Store) applyBalanceChangeTx(ctx context.Context, tx pgx.Tx, batch *domain.Batch, change balanceChange) error {
+	posting := change == balancePostPending || change == balancePostNew
+
+	// New batches were checked when created; the period of a pending one may have
+	// closed since
+	if change == balancePostPending {
+		if err := s.checkPeriodOpenTx(ctx, tx, batch); err != nil {
+			return err
+		}
+	}
+
+	entries := batch.Entries
+	accountIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		accountIDs[i] = entry.AccountID
+	}
+
+	balances, err := s.lockBalancesTx(ctx, tx, accountIDs)
+	if err != nil {
+		return err
+	}
+
+	before := make(map[string]int64, len(balances))
+	for id, balance := range balances {
+		before[id] = balance.Balance
+	}
+
+	for _, entry := range entries {
+		balance := balances[entry.AccountID]
+
+		switch change {
+		case balanceAddPending:
+			balance.AddPending(entry)
+		case balanceDropPending:
+			balance.DropPending(entry)
+		default:
+			newBalance := balance.Post(entry, change == balancePostPending)
+
+			later, err := s.shiftLaterBalancesTx(ctx, tx, entry, balance.NormalBalance)
+			if err != nil {
+				return err
+			}
+			balanceAfter := newBalance - later
+
+			_, err = tx.Exec(ctx, `
+				UPDATE ledger_entries SET balance_after = $1, posted_at = $2 WHERE id = $3
+			`, balanceAfter, batch.PostedAt, entry.ID)
+			if err != nil {
+				return fmt.Errorf("updating entry balance: %w", err)
+			}
+			entry.BalanceAfter = &balanceAfter
+			entry.PostedAt = batch.PostedAt
+		}
+	}
+
+	if posting && batch.IsBackdated() {
+		_, err := tx.Exec(ctx, `
+			UPDATE ledger_positions SET is_stale = true
+			WHERE account_id = ANY($1) AND date_basis = $2 AND period_end >= ($3::timestamptz AT TIME ZONE 'UTC')::date AND NOT is_stale
+		`, accountIDs, domain.DateBasisValue, batch.EffectiveDate)
+		if err != nil {
+			return fmt.Errorf("marking positions stale: %w", err)
+		}
+	}
+
+	ids := make([]string, 0, len(balances))
+	for id := range balances {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// Constraints apply to the net effect of the batch on each account
+	if posting {
+		for _, id := range ids {
+			if err := balances[id].Constraints.Check(id, before[id], balances[id].Balance); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, id := range ids {
+		balance := balances[id]
+		_, err := tx.Exec(ctx, `
+			UPDATE ledger_account_balances
+			SET posted_balance = $1, pending_balance = $2,
+				total_debits = $3, total_credits = $4, version = version + 1
+			WHERE account_id = $5
+		`, balance.Balance, balance.PendingBalance, balance.TotalDebits, balance.TotalCredits, id)
+		if err != nil {
+			return fmt.Errorf("updating account balance: %w", err)
+		}
+		balance.Version++
+	}
+
+	return nil
+}
+
+// shiftLaterBalancesTx adds an entry about to be posted to the running balance of
+// the account's posted entries valued after it, and returns their net effect
+func (s *Store) shiftLaterBalancesTx(ctx context.Context, tx pgx.Tx, entry *domain.Entry, normalBalance domain.NormalBalance) (int64, error) {
+	var later int64
+	err := tx.QueryRow(ctx, `
+		WITH shifted AS (
+			UPDATE ledger_entries
+			SET balance_after = balance_after + $1
+			WHERE account_id = $2 AND posted_at IS NOT NULL AND effective_date > ($3::timestamptz AT TIME ZONE 'UTC')::date
+			RETURNING entry_type, amount
+		)
+		SELECT COALESCE(SUM(CASE WHEN entry_type = $4 THEN amount ELSE -amount END), 0)
+		FROM shifted
+	`, entry.SignedAmount(normalBalance), entry.AccountID, entry.EffectiveDate, normalBalance).Scan(&later)
+	if err != nil {
+		return 0, fmt.Errorf("updating later entry balances: %w", err)
+	}
+
+	return later, nil
+}
+
+// lockBalancesTx locks the balance rows of the given accounts, creating missing
+// rows first. Rows are always locked in account ID order so that concurrent
+// postings touching the same accounts cannot deadlock.
+func (s *Store) lockBalancesTx(ctx context.Context, tx pgx.Tx, accountIDs []string) (map[string]*domain.Balance, error) {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO ledger_account_balances (account_id, tenant_id, currency)
+		SELECT id, tenant_id, currency FROM ledger_accounts
+		WHERE id = ANY($1)
+		ORDER BY id
+		ON CONFLICT (account_id) DO NOTHING
+	`, accountIDs)
+	if err != nil {
+		return nil, fmt.Errorf("creating account balances: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT a.id, a.currency, a.normal_balance,
+			   a.no_negative_balance, a.min_balance, a.max_balance, a.overdraft_limit,
+			   b.posted_balance, b.pending_balance, b.total_debits, b.total_credits,
+			   b.version, b.updated_at
+		FROM ledger_account_balances b
+		JOIN ledger_accounts a ON a.id = b.account_id
+		WHERE b.account_id = ANY($1)
+		ORDER BY b.account_id
+		FOR UPDATE OF b
+	`, accountIDs)
+	if err != nil {
+		return nil, fmt.Errorf("locking account balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make(map[string]*domain.Balance)
+	for rows.Next() {
+		balance, err := scanBalance(rows)
+		if err != nil {
+			return nil, err
+		}
+		balances[balance.AccountID] = balance
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range accountIDs {
+		if _, ok := balances[id]; !ok {
+			return nil, fmt.Errorf("account %s: %w", id, database.ErrNotFound)
+		}
+	}
+
+	return balances, nil
+}
+
 func (s *Store) getBatchForUpdate(ctx context.Context, tx pgx.Tx, tenantID, id string) (*domain.Batch, error) {
 	query := `
 		SELECT id, tenant_id, reference, description, source_type, source_id,
 			   total_debits, total_credits, entry_count, currency, status,
-			   posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   effective_date, posted_at, posted_by, reversed_at, reversed_by, reversal_reason,
+			   reversed_amount, reversal_of_batch_id, voided_at, voided_by,
+			   idempotency_key, request_fingerprint, posting_sequence, content_hash, chain_hash,
 			   metadata, created_at
 		FROM ledger_batches
 		WHERE tenant_id = $1 AND id = $2
@@ -432,7 +1678,8 @@ func (s *Store) getBatchForUpdate(ctx context.Context, tx pgx.Tx, tenantID, id s
 func (s *Store) getEntriesTx(ctx context.Context, tx pgx.Tx, batchID string) ([]*domain.Entry, error) {
 	query := `
 		SELECT id, batch_id, account_id, entry_type, amount, currency,
-			   balance_after, description, sequence, created_at
+			   balance_after, description, sequence, reverses_entry_id, effective_date,
+			   posted_at, created_at
 		FROM ledger_entries
 		WHERE batch_id = $1
 		ORDER BY sequence
@@ -447,12 +1694,45 @@ func (s *Store) getEntriesTx(ctx context.Context, tx pgx.Tx, batchID string) ([]
 	return scanEntries(rows)
 }
 
+// loadReversedAmountsTx sets how much of each entry has already been reversed
+func (s *Store) loadReversedAmountsTx(ctx context.Context, tx pgx.Tx, entries []*domain.Entry) error {
+	ids := make([]string, len(entries))
+	byID := make(map[string]*domain.Entry, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+		byID[entry.ID] = entry
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT reverses_entry_id, SUM(amount)
+		FROM ledger_entries
+		WHERE reverses_entry_id = ANY($1)
+		GROUP BY reverses_entry_id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("getting reversed amounts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entryID string
+		var reversed int64
+		if err := rows.Scan(&entryID, &reversed); err != nil {
+			return fmt.Errorf("scanning reversed amount: %w", err)
+		}
+		byID[entryID].ReversedAmount = reversed
+	}
+
+	return rows.Err()
+}
+
 func scanAccount(row pgx.Row) (*domain.Account, error) {
 	var a domain.Account
 	err := row.Scan(
 		&a.ID, &a.TenantID, &a.Code, &a.Name, &a.Description,
 		&a.AccountType, &a.NormalBalance, &a.Currency, &a.ParentID,
 		&a.Path, &a.IsSystem, &a.IsPlaceholder, &a.Status, &a.Metadata,
+		&a.Constraints.NoNegative, &a.Constraints.MinBalance, &a.Constraints.MaxBalance, &a.Constraints.OverdraftLimit,
 		&a.CreatedAt, &a.UpdatedAt,
 	)
 	if err != nil {
@@ -470,6 +1750,7 @@ func scanAccountRows(rows pgx.Rows) (*domain.Account, error) {
 		&a.ID, &a.TenantID, &a.Code, &a.Name, &a.Description,
 		&a.AccountType, &a.NormalBalance, &a.Currency, &a.ParentID,
 		&a.Path, &a.IsSystem, &a.IsPlaceholder, &a.Status, &a.Metadata,
+		&a.Constraints.NoNegative, &a.Constraints.MinBalance, &a.Constraints.MaxBalance, &a.Constraints.OverdraftLimit,
 		&a.CreatedAt, &a.UpdatedAt,
 	)
 	if err != nil {
@@ -482,10 +1763,13 @@ func scanBatch(row pgx.Row) (*domain.Batch, error) {
 	var b domain.Batch
 	var totalDebits, totalCredits int64
 	var currency string
+	var reference, description, sourceID, reversalReason, idempotencyKey, fingerprint, contentHash, chainHash *string
 	err := row.Scan(
-		&b.ID, &b.TenantID, &b.Reference, &b.Description, &b.SourceType, &b.SourceID,
+		&b.ID, &b.TenantID, &reference, &description, &b.SourceType, &sourceID,
 		&totalDebits, &totalCredits, &b.EntryCount, &currency, &b.Status,
-		&b.PostedAt, &b.PostedBy, &b.ReversedAt, &b.ReversedBy, &b.ReversalReason,
+		&b.EffectiveDate, &b.PostedAt, &b.PostedBy, &b.ReversedAt, &b.ReversedBy, &reversalReason,
+		&b.ReversedAmount, &b.ReversalOfID, &b.VoidedAt, &b.VoidedBy,
+		&idempotencyKey, &fingerprint, &b.PostingSequence, &contentHash, &chainHash,
 		&b.Metadata, &b.CreatedAt,
 	)
 	if err != nil {
@@ -494,33 +1778,95 @@ func scanBatch(row pgx.Row) (*domain.Batch, error) {
 		}
 		return nil, fmt.Errorf("scanning batch: %w", err)
 	}
+	b.Reference = derefString(reference)
+	b.Description = derefString(description)
+	b.SourceID = derefString(sourceID)
+	b.ReversalReason = derefString(reversalReason)
+	b.IdempotencyKey = derefString(idempotencyKey)
+	b.Fingerprint = derefString(fingerprint)
+	b.ContentHash = derefString(contentHash)
+	b.ChainHash = derefString(chainHash)
 	b.TotalDebits = money.New(totalDebits, money.Currency(currency))
 	b.TotalCredits = money.New(totalCredits, money.Currency(currency))
 	return &b, nil
 }
 
+func scanBalance(row pgx.Row) (*domain.Balance, error) {
+	var b domain.Balance
+	var currency string
+	err := row.Scan(
+		&b.AccountID, &currency, &b.NormalBalance,
+		&b.Constraints.NoNegative, &b.Constraints.MinBalance, &b.Constraints.MaxBalance, &b.Constraints.OverdraftLimit,
+		&b.Balance, &b.PendingBalance, &b.TotalDebits, &b.TotalCredits,
+		&b.Version, &b.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning balance: %w", err)
+	}
+	b.Currency = money.Currency(currency)
+	return &b, nil
+}
+
+func scanPosition(row pgx.Row) (*domain.Position, error) {
+	var p domain.Position
+	var currency string
+	err := row.Scan(
+		&p.ID, &p.TenantID, &p.AccountID, &p.PeriodType, &p.PeriodStart, &p.PeriodEnd,
+		&p.OpeningBalance, &p.DebitTotal, &p.CreditTotal, &p.ClosingBalance,
+		&p.EntryCount, &currency, &p.DateBasis, &p.Stale, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning position: %w", err)
+	}
+	p.Currency = money.Currency(currency)
+	return &p, nil
+}
+
 func scanEntries(rows pgx.Rows) ([]*domain.Entry, error) {
 	var entries []*domain.Entry
 	for rows.Next() {
 		var e domain.Entry
 		var amount int64
 		var currency string
+		var description *string
 		err := rows.Scan(
 			&e.ID, &e.BatchID, &e.AccountID, &e.EntryType, &amount, &currency,
-			&e.BalanceAfter, &e.Description, &e.Sequence, &e.CreatedAt,
+			&e.BalanceAfter, &description, &e.Sequence, &e.ReversesEntryID, &e.EffectiveDate,
+			&e.PostedAt, &e.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning entry: %w", err)
 		}
 		e.Amount = money.New(amount, money.Currency(currency))
+		e.Description = derefString(description)
 		entries = append(entries, &e)
 	}
 	return entries, nil
 }
 
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // Querier interface for testing
 type Querier interface {
-	Exec(ctx context.Context, sql string, args ...interface{}) (pgxpool.Row, error)
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 }
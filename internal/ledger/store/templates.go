@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+const templateColumns = `
+	id, tenant_id, name, description, source_type, roles, lines, created_at, updated_at
+`
+
+// CreateTemplate creates a posting template
+func (s *Store) CreateTemplate(ctx context.Context, t *domain.PostingTemplate) error {
+	query := `
+		INSERT INTO ledger_posting_templates (` + templateColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := s.db.Exec(ctx, query,
+		t.ID, t.TenantID, t.Name, nullableString(t.Description), t.SourceType,
+		t.Roles, t.Lines, t.CreatedAt, t.UpdatedAt,
+	)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return fmt.Errorf("posting template %s already exists: %w", t.Name, database.ErrAlreadyExists)
+		}
+		return fmt.Errorf("creating posting template: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTemplate saves a posting template's description, source type, roles and lines
+func (s *Store) UpdateTemplate(ctx context.Context, t *domain.PostingTemplate) error {
+	query := `
+		UPDATE ledger_posting_templates
+		SET description = $3, source_type = $4, roles = $5, lines = $6, updated_at = $7
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	result, err := s.db.Exec(ctx, query,
+		t.TenantID, t.ID, nullableString(t.Description), t.SourceType, t.Roles, t.Lines, t.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating posting template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return database.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteTemplate deletes a tenant's posting template by name
+func (s *Store) DeleteTemplate(ctx context.Context, tenantID, name string) error {
+	result, err := s.db.Exec(ctx,
+		`DELETE FROM ledger_posting_templates WHERE tenant_id = $1 AND name = $2`, tenantID, name)
+	if err != nil {
+		return fmt.Errorf("deleting posting template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return database.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetTemplate retrieves a tenant's posting template by name
+func (s *Store) GetTemplate(ctx context.Context, tenantID, name string) (*domain.PostingTemplate, error) {
+	query := `SELECT ` + templateColumns + ` FROM ledger_posting_templates WHERE tenant_id = $1 AND name = $2`
+
+	return scanTemplate(s.db.QueryRow(ctx, query, tenantID, name))
+}
+
+// ListTemplates lists a tenant's posting templates by name
+func (s *Store) ListTemplates(ctx context.Context, tenantID string) ([]*domain.PostingTemplate, error) {
+	query := `SELECT ` + templateColumns + ` FROM ledger_posting_templates WHERE tenant_id = $1 ORDER BY name`
+
+	rows, err := s.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listing posting templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*domain.PostingTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+func scanTemplate(row pgx.Row) (*domain.PostingTemplate, error) {
+	var t domain.PostingTemplate
+	var description *string
+	err := row.Scan(
+		&t.ID, &t.TenantID, &t.Name, &description, &t.SourceType,
+		&t.Roles, &t.Lines, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning posting template: %w", err)
+	}
+	t.Description = derefString(description)
+	return &t, nil
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/ledger/domain"
+)
+
+const walletAccountQuery = `
+	SELECT wa.id, w.tenant_id, wa.wallet_id, w.customer_id, wa.currency, wa.ledger_account_id
+	FROM wallet_accounts wa
+	JOIN wallets w ON w.id = wa.wallet_id
+`
+
+// GetWalletAccount retrieves a tenant's wallet account
+func (s *Store) GetWalletAccount(ctx context.Context, tenantID, id string) (*domain.WalletAccount, error) {
+	row := s.db.QueryRow(ctx, walletAccountQuery+` WHERE w.tenant_id = $1 AND wa.id = $2`, tenantID, id)
+
+	var wa domain.WalletAccount
+	err := row.Scan(&wa.ID, &wa.TenantID, &wa.WalletID, &wa.CustomerID, &wa.Currency, &wa.LedgerAccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning wallet account: %w", err)
+	}
+
+	return &wa, nil
+}
+
+// ListUnlinkedWalletAccounts lists the wallet accounts without a ledger account,
+// of one tenant or, with an empty tenantID, of all tenants
+func (s *Store) ListUnlinkedWalletAccounts(ctx context.Context, tenantID string) ([]*domain.WalletAccount, error) {
+	query := walletAccountQuery + ` WHERE wa.ledger_account_id IS NULL`
+	var args []interface{}
+	if tenantID != "" {
+		query += ` AND w.tenant_id = $1`
+		args = append(args, tenantID)
+	}
+	query += ` ORDER BY w.tenant_id, wa.id`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing unlinked wallet accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*domain.WalletAccount
+	for rows.Next() {
+		var wa domain.WalletAccount
+		if err := rows.Scan(&wa.ID, &wa.TenantID, &wa.WalletID, &wa.CustomerID, &wa.Currency, &wa.LedgerAccountID); err != nil {
+			return nil, fmt.Errorf("scanning wallet account: %w", err)
+		}
+		accounts = append(accounts, &wa)
+	}
+
+	return accounts, rows.Err()
+}
+
+// LinkWalletLedgerAccount creates a wallet account's ledger account, unless one
+// with its code exists already, and records it on the wallet account. It returns
+// the linked account; a wallet account linked to another account is left as it is
+// and fails with database.ErrAlreadyExists.
+func (s *Store) LinkWalletLedgerAccount(ctx context.Context, walletAccountID string, account *domain.Account) (*domain.Account, error) {
+	var linked *domain.Account
+	err := s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, insertAccountQuery+` ON CONFLICT (tenant_id, code) DO NOTHING`, insertAccountArgs(account)...)
+		if err != nil {
+			return fmt.Errorf("creating wallet ledger account: %w", err)
+		}
+
+		row := tx.QueryRow(ctx, `
+			SELECT id, tenant_id, code, name, description, account_type, normal_balance,
+				   currency, parent_id, path, is_system, is_placeholder, status, metadata,
+				   no_negative_balance, min_balance, max_balance, overdraft_limit,
+				   created_at, updated_at
+			FROM ledger_accounts
+			WHERE tenant_id = $1 AND code = $2
+		`, account.TenantID, account.Code)
+		if linked, err = scanAccount(row); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(ctx, `
+			UPDATE wallet_accounts SET ledger_account_id = $2
+			WHERE id = $1 AND (ledger_account_id IS NULL OR ledger_account_id = $2)
+		`, walletAccountID, linked.ID)
+		if err != nil {
+			return fmt.Errorf("linking wallet account: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("wallet account %s is linked to another ledger account: %w", walletAccountID, database.ErrAlreadyExists)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return linked, nil
+}
+
+// GetRollupBalance sums the current balances of an account and its descendants
+func (s *Store) GetRollupBalance(ctx context.Context, account *domain.Account) (*domain.RollupBalance, error) {
+	query := `
+		SELECT COALESCE(SUM(b.posted_balance), 0), COALESCE(SUM(b.pending_balance), 0),
+			   COALESCE(SUM(b.total_debits), 0), COALESCE(SUM(b.total_credits), 0), COUNT(*)
+		FROM ledger_accounts a
+		LEFT JOIN ledger_account_balances b ON b.account_id = a.id
+		WHERE a.tenant_id = $1 AND (a.path = $2 OR a.path LIKE $3)
+	`
+
+	rb := &domain.RollupBalance{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		AsOf:      time.Now().UTC(),
+	}
+	err := s.db.QueryRow(ctx, query, account.TenantID, account.Path, escapeLike(account.Path)+"/%").Scan(
+		&rb.Balance, &rb.PendingBalance, &rb.TotalDebits, &rb.TotalCredits, &rb.AccountCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summing account balances: %w", err)
+	}
+
+	return rb, nil
+}
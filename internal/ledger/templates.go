@@ -0,0 +1,192 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// TemplateMetadataKey is the batch metadata key naming the posting template a
+// batch was posted from
+const TemplateMetadataKey = "posting_template"
+
+// SaveTemplateRequest is the request to create or replace a posting template
+type SaveTemplateRequest struct {
+	TenantID    string
+	Name        string
+	Description string
+	SourceType  domain.SourceType
+	Roles       map[string]string
+	Lines       []domain.TemplateLine
+}
+
+// CreateTemplate creates a posting template
+func (s *Service) CreateTemplate(ctx context.Context, req SaveTemplateRequest) (*domain.PostingTemplate, error) {
+	now := time.Now().UTC()
+	t := &domain.PostingTemplate{
+		ID:          ulid.Make().String(),
+		TenantID:    req.TenantID,
+		Name:        req.Name,
+		Description: req.Description,
+		SourceType:  req.SourceType,
+		Roles:       req.Roles,
+		Lines:       req.Lines,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateTemplate(ctx, t); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("posting template created",
+		"tenant_id", t.TenantID,
+		"template", t.Name,
+	)
+
+	return t, nil
+}
+
+// UpdateTemplate replaces the description, source type, roles and lines of the
+// posting template named by the request. Batches already posted from it are
+// not affected.
+func (s *Service) UpdateTemplate(ctx context.Context, req SaveTemplateRequest) (*domain.PostingTemplate, error) {
+	t, err := s.store.GetTemplate(ctx, req.TenantID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Description = req.Description
+	t.SourceType = req.SourceType
+	t.Roles = req.Roles
+	t.Lines = req.Lines
+	t.UpdatedAt = time.Now().UTC()
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateTemplate(ctx, t); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("posting template updated",
+		"tenant_id", t.TenantID,
+		"template", t.Name,
+	)
+
+	return t, nil
+}
+
+// DeleteTemplate deletes a posting template
+func (s *Service) DeleteTemplate(ctx context.Context, tenantID, name string) error {
+	return s.store.DeleteTemplate(ctx, tenantID, name)
+}
+
+// GetTemplate retrieves a posting template by name
+func (s *Service) GetTemplate(ctx context.Context, tenantID, name string) (*domain.PostingTemplate, error) {
+	return s.store.GetTemplate(ctx, tenantID, name)
+}
+
+// ListTemplates lists a tenant's posting templates
+func (s *Service) ListTemplates(ctx context.Context, tenantID string) ([]*domain.PostingTemplate, error) {
+	return s.store.ListTemplates(ctx, tenantID)
+}
+
+// PostFromTemplateRequest is the request to post the entries a template expands to
+type PostFromTemplateRequest struct {
+	TenantID       string
+	Template       string
+	Accounts       map[string]string // Role -> account ID, for roles bound per posting
+	Amounts        map[string]int64
+	Reference      string
+	Description    string // Defaults to the template's description
+	SourceID       string
+	Currency       money.Currency
+	EffectiveDate  *time.Time
+	Metadata       map[string]string
+	IdempotencyKey string
+}
+
+// PostFromTemplate expands a posting template with the request's accounts and
+// amounts and posts the entries as PostEntries does, with the template's source
+// type. The batch's metadata names the template.
+func (s *Service) PostFromTemplate(ctx context.Context, req PostFromTemplateRequest) (*domain.Batch, error) {
+	postReq, err := s.expandTemplate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.PostEntries(ctx, postReq)
+}
+
+// expandTemplate builds the post request a template expands to, looking up the
+// accounts of roles bound by code
+func (s *Service) expandTemplate(ctx context.Context, req PostFromTemplateRequest) (PostEntriesRequest, error) {
+	t, err := s.store.GetTemplate(ctx, req.TenantID, req.Template)
+	if err != nil {
+		return PostEntriesRequest{}, err
+	}
+
+	expanded, err := t.Expand(req.Accounts, req.Amounts)
+	if err != nil {
+		return PostEntriesRequest{}, err
+	}
+
+	accountIDs := make(map[string]string)
+	entries := make([]EntryRequest, len(expanded))
+	for i, e := range expanded {
+		accountID := e.AccountID
+		if accountID == "" {
+			if accountID = accountIDs[e.AccountCode]; accountID == "" {
+				account, err := s.store.GetAccountByCode(ctx, req.TenantID, e.AccountCode)
+				if err != nil {
+					if database.IsNotFound(err) {
+						return PostEntriesRequest{}, fmt.Errorf("%w: role %q posts to account %s, which does not exist",
+							domain.ErrTemplateArguments, e.Role, e.AccountCode)
+					}
+					return PostEntriesRequest{}, err
+				}
+				accountID = account.ID
+				accountIDs[e.AccountCode] = accountID
+			}
+		}
+		entries[i] = EntryRequest{
+			AccountID:   accountID,
+			EntryType:   e.EntryType,
+			Amount:      e.Amount,
+			Description: e.Description,
+		}
+	}
+
+	description := req.Description
+	if description == "" {
+		description = t.Description
+	}
+
+	metadata := make(map[string]string, len(req.Metadata)+1)
+	for key, value := range req.Metadata {
+		metadata[key] = value
+	}
+	metadata[TemplateMetadataKey] = t.Name
+
+	return PostEntriesRequest{
+		TenantID:       req.TenantID,
+		Reference:      req.Reference,
+		Description:    description,
+		SourceType:     t.SourceType,
+		SourceID:       req.SourceID,
+		Currency:       req.Currency,
+		Entries:        entries,
+		EffectiveDate:  req.EffectiveDate,
+		Metadata:       metadata,
+		IdempotencyKey: req.IdempotencyKey,
+	}, nil
+}
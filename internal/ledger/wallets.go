@@ -0,0 +1,158 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/oklog/ulid/v2"
+
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/events"
+	"finplatform/internal/common/middleware"
+	"finplatform/internal/common/money"
+	"finplatform/internal/ledger/domain"
+)
+
+// ProvisionWalletAccount gives a wallet account a ledger account of its own, a
+// liability under the tenant's customer wallet liabilities account in the
+// wallet's currency, and links the two. Its code is derived from the wallet
+// account, so provisioning again, or concurrently, returns the same account.
+func (s *Service) ProvisionWalletAccount(ctx context.Context, tenantID, walletAccountID string) (*domain.Account, error) {
+	wa, err := s.store.GetWalletAccount(ctx, tenantID, walletAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if wa.LedgerAccountID != nil {
+		return s.store.GetAccount(ctx, tenantID, *wa.LedgerAccountID)
+	}
+
+	parent, err := s.walletParent(ctx, tenantID, wa.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := domain.NewWalletLedgerAccount(ulid.Make().String(), wa, parent)
+	if err != nil {
+		return nil, fmt.Errorf("creating wallet ledger account: %w", err)
+	}
+
+	account, err = s.store.LinkWalletLedgerAccount(ctx, wa.ID, account)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("wallet ledger account provisioned",
+		"tenant_id", tenantID,
+		"wallet_account_id", wa.ID,
+		"account_id", account.ID,
+		"code", account.Code,
+	)
+
+	return account, nil
+}
+
+// walletParent finds the customer wallet liabilities account in currency: 2000
+// if it is kept in that currency, otherwise its copy for the currency
+func (s *Service) walletParent(ctx context.Context, tenantID string, currency money.Currency) (*domain.Account, error) {
+	codes := []string{
+		domain.CustomerWalletsCode,
+		domain.CurrencyAccountCode(domain.CustomerWalletsCode, currency),
+	}
+	for _, code := range codes {
+		account, err := s.store.GetAccountByCode(ctx, tenantID, code)
+		if err != nil {
+			if database.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if account.Currency == currency {
+			return account, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", domain.ErrNoWalletParent, currency)
+}
+
+// ProvisionWalletAccounts provisions the ledger accounts of every wallet account
+// without one, of one tenant or, with an empty tenantID, of all tenants. Wallets
+// of tenants without a customer wallet liabilities account in their currency are
+// skipped. It returns how many were provisioned.
+func (s *Service) ProvisionWalletAccounts(ctx context.Context, tenantID string) (int, error) {
+	unlinked, err := s.store.ListUnlinkedWalletAccounts(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var provisioned int
+	for _, wa := range unlinked {
+		if _, err := s.ProvisionWalletAccount(ctx, wa.TenantID, wa.ID); err != nil {
+			if errors.Is(err, domain.ErrNoWalletParent) {
+				s.logger.Warn("wallet ledger account not provisioned",
+					"tenant_id", wa.TenantID,
+					"wallet_account_id", wa.ID,
+					"error", err,
+				)
+				continue
+			}
+			return provisioned, fmt.Errorf("provisioning wallet account %s: %w", wa.ID, err)
+		}
+		provisioned++
+	}
+
+	return provisioned, nil
+}
+
+// GetAccountRollupBalance retrieves the current balance of an account together
+// with all its descendants, such as 2000 with every wallet's account under it
+func (s *Service) GetAccountRollupBalance(ctx context.Context, tenantID, accountID string) (*domain.RollupBalance, error) {
+	account, err := s.store.GetAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.GetRollupBalance(ctx, account)
+}
+
+// WalletEventHandler provisions the ledger account of each wallet account as it
+// is created
+type WalletEventHandler struct {
+	service *Service
+	logger  *slog.Logger
+}
+
+// NewWalletEventHandler creates a handler of wallet events
+func NewWalletEventHandler(service *Service, logger *slog.Logger) *WalletEventHandler {
+	return &WalletEventHandler{service: service, logger: logger}
+}
+
+// EventTypes returns the event types the handler handles
+func (h *WalletEventHandler) EventTypes() []string {
+	return []string{events.EventWalletAccountCreated}
+}
+
+// Handle provisions the ledger account of a created wallet account. An event
+// that cannot be decoded is dropped; any other failure is returned so that the
+// event is redelivered.
+func (h *WalletEventHandler) Handle(ctx context.Context, event *events.Event) error {
+	if event.Type != events.EventWalletAccountCreated {
+		return nil
+	}
+
+	var data events.WalletAccountCreatedData
+	if err := event.DecodeData(&data); err != nil || data.WalletAccountID == "" || event.TenantID == "" {
+		h.logger.Error("dropping malformed wallet account event",
+			"event_id", event.ID,
+			"error", err,
+		)
+		return nil
+	}
+
+	ctx = middleware.WithTenantID(ctx, event.TenantID)
+	if _, err := h.service.ProvisionWalletAccount(ctx, event.TenantID, data.WalletAccountID); err != nil {
+		return fmt.Errorf("provisioning wallet account %s: %w", data.WalletAccountID, err)
+	}
+
+	return nil
+}
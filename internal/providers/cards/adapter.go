@@ -13,6 +13,7 @@ import (
 	"github.com/nats-io/nats.go"
 	"github.com/oklog/ulid/v2"
 
+	"finplatform/internal/common/database"
 	"finplatform/internal/common/money"
 	"finplatform/internal/funding"
 )
@@ -444,7 +445,8 @@ func (a *Adapter) Refund(ctx context.Context, providerRef string, amount money.M
 	return nil
 }
 
-// handleCaptured processes txn.captured events from acquiring.
+// handleCaptured processes txn.captured events from acquiring. Events are not
+// made for a tenant, so the payment is looked up across tenants.
 func (a *Adapter) handleCaptured(msg *nats.Msg) {
 	var event struct {
 		TransactionID string    `json:"transactionId"`
@@ -458,7 +460,7 @@ func (a *Adapter) handleCaptured(msg *nats.Msg) {
 
 	a.logger.Info("received capture event", "transaction_id", event.TransactionID)
 
-	ctx := context.Background()
+	ctx := database.WithSystemScope(context.Background())
 	payment, err := a.store.GetByTransactionID(ctx, event.TransactionID)
 	if err != nil {
 		a.logger.Error("payment not found for capture event", "transaction_id", event.TransactionID)
@@ -476,7 +478,7 @@ func (a *Adapter) handleCaptured(msg *nats.Msg) {
 	}
 }
 
-// handleRefunded processes txn.refunded events from acquiring.
+// handleRefunded processes txn.refunded events from acquiring, across tenants.
 func (a *Adapter) handleRefunded(msg *nats.Msg) {
 	var event struct {
 		TransactionID       string    `json:"transactionId"`
@@ -494,11 +496,11 @@ func (a *Adapter) handleRefunded(msg *nats.Msg) {
 		"refund_txn_id", event.RefundTransactionID,
 	)
 
-	ctx := context.Background()
+	ctx := database.WithSystemScope(context.Background())
 	a.store.MarkRefunded(ctx, event.TransactionID)
 }
 
-// handleChargeback processes chargeback events from acquiring.
+// handleChargeback processes chargeback events from acquiring, across tenants.
 func (a *Adapter) handleChargeback(msg *nats.Msg) {
 	var event ChargebackEvent
 	if err := json.Unmarshal(msg.Data, &event); err != nil {
@@ -513,7 +515,7 @@ func (a *Adapter) handleChargeback(msg *nats.Msg) {
 		"amount", event.Amount,
 	)
 
-	ctx := context.Background()
+	ctx := database.WithSystemScope(context.Background())
 	payment, err := a.store.GetByTransactionID(ctx, event.TransactionID)
 	if err != nil {
 		a.logger.Error("payment not found for chargeback", "transaction_id", event.TransactionID)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/oklog/ulid/v2"
 
+	"finplatform/internal/common/database"
 	"finplatform/internal/funding"
 )
 
@@ -386,7 +387,10 @@ func (a *Adapter) Recall(ctx context.Context, endToEndID string, reason RecallRe
 }
 
 // HandleReturn processes an inbound return notification from the receiving bank.
+// Returns are not made for a tenant, so the payment is looked up across tenants.
 func (a *Adapter) HandleReturn(ctx context.Context, notification *ReturnNotification) error {
+	ctx = database.WithSystemScope(ctx)
+
 	a.logger.Info("processing FPS return",
 		"original_e2e_id", notification.OriginalEndToEndID,
 		"return_reason", notification.ReturnReason,
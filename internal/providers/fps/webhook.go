@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"finplatform/internal/common/database"
 	"finplatform/internal/domain"
 	"finplatform/internal/events"
 )
@@ -44,9 +45,10 @@ func NewWebhookHandler(store Store, publisher EventPublisher, logger *slog.Logge
 	}
 }
 
-// ServeHTTP handles incoming FPS webhook requests.
+// ServeHTTP handles incoming FPS webhook requests. They are not made for a
+// tenant, so they look payments up across tenants.
 func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := database.WithSystemScope(r.Context())
 
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
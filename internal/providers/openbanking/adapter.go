@@ -15,6 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/oklog/ulid/v2"
 
+	"finplatform/internal/common/database"
 	"finplatform/internal/domain"
 	"finplatform/internal/events"
 )
@@ -191,8 +192,11 @@ func (a *Adapter) Initiate(ctx context.Context, req *InitiateRequest) (*Initiate
 	return &resp, nil
 }
 
-// HandleCallback processes the callback after user authorization.
+// HandleCallback processes the callback after user authorization. Callbacks are
+// not made for a tenant, so the payment is looked up across tenants.
 func (a *Adapter) HandleCallback(ctx context.Context, paymentID string) error {
+	ctx = database.WithSystemScope(ctx)
+
 	payment, err := a.store.GetByPaymentID(ctx, paymentID)
 	if err != nil {
 		return fmt.Errorf("get payment: %w", err)
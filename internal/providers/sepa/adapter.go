@@ -14,6 +14,7 @@ import (
 
 	"github.com/oklog/ulid/v2"
 
+	"finplatform/internal/common/database"
 	"finplatform/internal/funding"
 )
 
@@ -418,8 +419,11 @@ func (a *Adapter) Recall(ctx context.Context, msgID, pmtInfID string, reason SEP
 	return &resp, nil
 }
 
-// HandleReturn processes an inbound return notification.
+// HandleReturn processes an inbound return notification. Returns are not made
+// for a tenant, so the payment is looked up across tenants.
 func (a *Adapter) HandleReturn(ctx context.Context, notification *ReturnNotification) error {
+	ctx = database.WithSystemScope(ctx)
+
 	a.logger.Info("processing SEPA return",
 		"original_msg_id", notification.OriginalMsgID,
 		"original_pmt_inf_id", notification.OriginalPmtInfID,
@@ -14,6 +14,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/oklog/ulid/v2"
 
+	"finplatform/internal/common/database"
+	"finplatform/internal/common/iso20022"
 	"finplatform/internal/domain"
 	"finplatform/internal/events"
 )
@@ -130,8 +132,11 @@ func NewReportIngester(paymentStore *PostgresStore, reportStore *ReportStore, pu
 	}
 }
 
-// IngestFile processes a SEPA report file.
+// IngestFile processes a SEPA report file. Reports cover payments of every
+// tenant, so they are ingested across tenants.
 func (i *ReportIngester) IngestFile(ctx context.Context, filePath string) error {
+	ctx = database.WithSystemScope(ctx)
+
 	// Read file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -359,57 +364,20 @@ func (i *ReportIngester) mapPain002Status(txSts string) SEPAStatus {
 	}
 }
 
-// Camt053 XML structures (ISO 20022 Bank to Customer Statement)
-type Camt053Document struct {
-	XMLName xml.Name      `xml:"Document"`
-	BkToCstmrStmt Camt053BkToCstmrStmt `xml:"BkToCstmrStmt"`
-}
-
-type Camt053BkToCstmrStmt struct {
-	GrpHdr Camt053GrpHdr `xml:"GrpHdr"`
-	Stmt   []Camt053Stmt  `xml:"Stmt"`
-}
-
-type Camt053GrpHdr struct {
-	MsgId   string `xml:"MsgId"`
-	CreDtTm string `xml:"CreDtTm"`
-}
-
-type Camt053Stmt struct {
-	Id      string       `xml:"Id"`
-	Ntry    []Camt053Ntry `xml:"Ntry"`
-}
-
-type Camt053Ntry struct {
-	Amt       Camt053Amt   `xml:"Amt"`
-	CdtDbtInd string       `xml:"CdtDbtInd"` // CRDT or DBIT
-	Sts       string       `xml:"Sts"`       // BOOK, PDNG
-	BookgDt   Camt053Dt    `xml:"BookgDt"`
-	NtryDtls  []Camt053NtryDtls `xml:"NtryDtls"`
-}
-
-type Camt053Amt struct {
-	Value string `xml:",chardata"`
-	Ccy   string `xml:"Ccy,attr"`
-}
-
-type Camt053Dt struct {
-	Dt string `xml:"Dt"`
-}
-
-type Camt053NtryDtls struct {
-	TxDtls []Camt053TxDtls `xml:"TxDtls"`
-}
-
-type Camt053TxDtls struct {
-	Refs Camt053Refs `xml:"Refs"`
-}
-
-type Camt053Refs struct {
-	MsgId      string `xml:"MsgId"`
-	PmtInfId   string `xml:"PmtInfId"`
-	EndToEndId string `xml:"EndToEndId"`
-}
+// Camt053 XML structures (ISO 20022 Bank to Customer Statement), shared with the
+// ledger's statement export
+type (
+	Camt053Document      = iso20022.Camt053Document
+	Camt053BkToCstmrStmt = iso20022.Camt053BkToCstmrStmt
+	Camt053GrpHdr        = iso20022.Camt053GrpHdr
+	Camt053Stmt          = iso20022.Camt053Stmt
+	Camt053Ntry          = iso20022.Camt053Ntry
+	Camt053Amt           = iso20022.Camt053Amt
+	Camt053Dt            = iso20022.Camt053Dt
+	Camt053NtryDtls      = iso20022.Camt053NtryDtls
+	Camt053TxDtls        = iso20022.Camt053TxDtls
+	Camt053Refs          = iso20022.Camt053Refs
+)
 
 // ParseCamt053 parses a camt.053 Bank Statement.
 func (i *ReportIngester) ParseCamt053(data []byte) ([]StatusUpdate, error) {
@@ -423,7 +391,7 @@ func (i *ReportIngester) ParseCamt053(data []byte) ([]StatusUpdate, error) {
 	for _, stmt := range doc.BkToCstmrStmt.Stmt {
 		for _, ntry := range stmt.Ntry {
 			// Only process booked debit entries (outgoing payments)
-			if ntry.Sts != "BOOK" || ntry.CdtDbtInd != "DBIT" {
+			if ntry.Sts != iso20022.EntryBooked || ntry.CdtDbtInd != iso20022.Debit {
 				continue
 			}
 
@@ -445,8 +413,10 @@ func (i *ReportIngester) ParseCamt053(data []byte) ([]StatusUpdate, error) {
 	return updates, nil
 }
 
-// IngestFromReader processes a report from an io.Reader.
+// IngestFromReader processes a report from an io.Reader, across tenants like IngestFile.
 func (i *ReportIngester) IngestFromReader(ctx context.Context, r io.Reader, reportType string) error {
+	ctx = database.WithSystemScope(ctx)
+
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return fmt.Errorf("read data: %w", err)
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_ledger_entries_reverses;
+
+ALTER TABLE ledger_entries
+    DROP COLUMN IF EXISTS reverses_entry_id;
+
+DROP INDEX IF EXISTS idx_ledger_batches_reversal_of;
+
+ALTER TABLE ledger_batches
+    DROP COLUMN IF EXISTS reversal_of_batch_id,
+    DROP COLUMN IF EXISTS reversed_amount;
@@ -0,0 +1,12 @@
+-- Link reversal batches to the batch they reverse and track partially reversed amounts
+ALTER TABLE ledger_batches
+    ADD COLUMN IF NOT EXISTS reversal_of_batch_id VARCHAR(26) REFERENCES ledger_batches(id),
+    ADD COLUMN IF NOT EXISTS reversed_amount BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_batches_reversal_of ON ledger_batches(reversal_of_batch_id) WHERE reversal_of_batch_id IS NOT NULL;
+
+-- Reversal entries point at the entry they reverse so partial reversals never over-reverse an entry
+ALTER TABLE ledger_entries
+    ADD COLUMN IF NOT EXISTS reverses_entry_id VARCHAR(26) REFERENCES ledger_entries(id);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_reverses ON ledger_entries(reverses_entry_id) WHERE reverses_entry_id IS NOT NULL;
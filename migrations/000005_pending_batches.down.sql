@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_ledger_batches_pending;
+
+ALTER TABLE ledger_batches
+    DROP COLUMN IF EXISTS voided_at,
+    DROP COLUMN IF EXISTS voided_by;
@@ -0,0 +1,7 @@
+-- Two-phase batches: pending batches can be posted later or voided
+ALTER TABLE ledger_batches
+    ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS voided_by VARCHAR(26) REFERENCES users(id);
+
+-- A tenant's pending batches are listed oldest first, in keyset (created_at, id) order
+CREATE INDEX IF NOT EXISTS idx_ledger_batches_pending ON ledger_batches(tenant_id, created_at, id) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ledger_fx_legs_batch_id;
+DROP TABLE IF EXISTS ledger_fx_legs;
+DROP TABLE IF EXISTS ledger_batch_totals;
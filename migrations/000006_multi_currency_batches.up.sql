@@ -0,0 +1,37 @@
+-- Per-currency totals; a multi-currency batch must balance in every currency.
+-- ledger_batches.total_debits/total_credits/currency keep the batch (base) currency totals.
+CREATE TABLE IF NOT EXISTS ledger_batch_totals (
+    batch_id VARCHAR(26) NOT NULL REFERENCES ledger_batches(id),
+    currency VARCHAR(3) NOT NULL,
+    total_debits BIGINT NOT NULL,
+    total_credits BIGINT NOT NULL,
+    entry_count INT NOT NULL,
+    PRIMARY KEY (batch_id, currency),
+    CONSTRAINT ledger_batch_totals_balanced CHECK (total_debits = total_credits)
+);
+
+INSERT INTO ledger_batch_totals (batch_id, currency, total_debits, total_credits, entry_count)
+SELECT id, currency, total_debits, total_credits, entry_count
+FROM ledger_batches
+ON CONFLICT DO NOTHING;
+
+-- FX legs record the conversions within a batch
+CREATE TABLE IF NOT EXISTS ledger_fx_legs (
+    id VARCHAR(26) PRIMARY KEY,
+    batch_id VARCHAR(26) NOT NULL REFERENCES ledger_batches(id),
+
+    source_amount BIGINT NOT NULL,
+    source_currency VARCHAR(3) NOT NULL,
+    counter_amount BIGINT NOT NULL,
+    counter_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC(28, 12) NOT NULL,  -- Counter units per source unit
+
+    source_position_account_id VARCHAR(26) NOT NULL REFERENCES ledger_accounts(id),
+    counter_position_account_id VARCHAR(26) NOT NULL REFERENCES ledger_accounts(id),
+    revenue_account_id VARCHAR(26) REFERENCES ledger_accounts(id),
+    revenue_amount BIGINT NOT NULL DEFAULT 0,  -- In the counter currency
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_fx_legs_batch_id ON ledger_fx_legs(batch_id);
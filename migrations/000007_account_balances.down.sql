@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_ledger_entries_account_created;
+
+DROP TRIGGER IF EXISTS update_ledger_account_balances_updated_at ON ledger_account_balances;
+DROP TABLE IF EXISTS ledger_account_balances;
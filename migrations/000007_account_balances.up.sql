@@ -0,0 +1,43 @@
+-- Materialised account balances, updated row-locked in the posting transaction.
+-- pending_balance is the balance once all pending batches are posted.
+CREATE TABLE IF NOT EXISTS ledger_account_balances (
+    account_id VARCHAR(26) PRIMARY KEY REFERENCES ledger_accounts(id),
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+    currency VARCHAR(3) NOT NULL,
+
+    posted_balance BIGINT NOT NULL DEFAULT 0,
+    pending_balance BIGINT NOT NULL DEFAULT 0,
+    total_debits BIGINT NOT NULL DEFAULT 0,   -- Posted debits
+    total_credits BIGINT NOT NULL DEFAULT 0,  -- Posted credits
+
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_account_balances_tenant_id ON ledger_account_balances(tenant_id);
+
+CREATE TRIGGER update_ledger_account_balances_updated_at BEFORE UPDATE ON ledger_account_balances
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Backfill from existing entries
+INSERT INTO ledger_account_balances (
+    account_id, tenant_id, currency, posted_balance, pending_balance,
+    total_debits, total_credits, version
+)
+SELECT
+    a.id, a.tenant_id, a.currency,
+    COALESCE(SUM(CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END)
+        FILTER (WHERE b.status IN ('posted', 'reversed')), 0),
+    COALESCE(SUM(CASE WHEN e.entry_type = a.normal_balance THEN e.amount ELSE -e.amount END)
+        FILTER (WHERE b.status IN ('pending', 'posted', 'reversed')), 0),
+    COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'debit' AND b.status IN ('posted', 'reversed')), 0),
+    COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'credit' AND b.status IN ('posted', 'reversed')), 0),
+    COUNT(e.id) FILTER (WHERE b.status IN ('posted', 'reversed'))
+FROM ledger_accounts a
+LEFT JOIN ledger_entries e ON e.account_id = a.id
+LEFT JOIN ledger_batches b ON b.id = e.batch_id
+GROUP BY a.id, a.tenant_id, a.currency
+ON CONFLICT (account_id) DO NOTHING;
+
+-- Account entry listings order by created_at
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_created ON ledger_entries(account_id, created_at);
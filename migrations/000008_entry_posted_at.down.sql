@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_ledger_entries_account_posted;
+
+ALTER TABLE ledger_entries
+    DROP COLUMN IF EXISTS posted_at;
@@ -0,0 +1,10 @@
+-- Posting time on entries so as-of balance lookups can use an index
+ALTER TABLE ledger_entries
+    ADD COLUMN IF NOT EXISTS posted_at TIMESTAMPTZ;
+
+UPDATE ledger_entries e
+SET posted_at = b.posted_at
+FROM ledger_batches b
+WHERE b.id = e.batch_id AND b.posted_at IS NOT NULL AND e.posted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_posted ON ledger_entries(account_id, posted_at) WHERE posted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_ledger_batches_period_close;
+
+DROP TABLE IF EXISTS ledger_period_audit;
+
+DROP TRIGGER IF EXISTS update_ledger_periods_updated_at ON ledger_periods;
+DROP TABLE IF EXISTS ledger_periods;
@@ -0,0 +1,47 @@
+-- Monthly accounting periods; nothing can be posted into a closing or closed period
+CREATE TABLE IF NOT EXISTS ledger_periods (
+    id VARCHAR(26) PRIMARY KEY,
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'open',  -- open, closing, closed
+
+    closed_at TIMESTAMPTZ,
+    closed_by VARCHAR(26) REFERENCES users(id),
+    closing_batch_id VARCHAR(26) REFERENCES ledger_batches(id),  -- Revenue/expense sweep
+
+    reopened_at TIMESTAMPTZ,
+    reopened_by VARCHAR(26) REFERENCES users(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_periods_tenant_range ON ledger_periods(tenant_id, period_start, period_end);
+
+CREATE TRIGGER update_ledger_periods_updated_at BEFORE UPDATE ON ledger_periods
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Every period status change, including admin reopens
+CREATE TABLE IF NOT EXISTS ledger_period_audit (
+    id VARCHAR(26) PRIMARY KEY,
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+    period_id VARCHAR(26) NOT NULL REFERENCES ledger_periods(id),
+
+    action VARCHAR(20) NOT NULL,  -- start_close, close, reopen
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    user_id VARCHAR(26) REFERENCES users(id),
+    reason TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_period_audit_period_id ON ledger_period_audit(period_id);
+
+-- Closing batches are looked up by the period they close
+CREATE INDEX IF NOT EXISTS idx_ledger_batches_period_close ON ledger_batches(source_id) WHERE source_type = 'period_close';
@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_ledger_positions_stale;
+
+DELETE FROM ledger_positions WHERE date_basis <> 'posting';
+
+ALTER TABLE ledger_positions
+    DROP CONSTRAINT IF EXISTS ledger_positions_account_period_basis_key;
+
+ALTER TABLE ledger_positions
+    ADD CONSTRAINT ledger_positions_account_id_period_type_period_start_key UNIQUE (account_id, period_type, period_start);
+
+ALTER TABLE ledger_positions
+    DROP COLUMN IF EXISTS is_stale,
+    DROP COLUMN IF EXISTS date_basis;
+
+DROP INDEX IF EXISTS idx_ledger_entries_account_effective;
+
+ALTER TABLE ledger_entries
+    DROP COLUMN IF EXISTS effective_date;
+
+ALTER TABLE ledger_batches
+    DROP COLUMN IF EXISTS effective_date;
@@ -0,0 +1,41 @@
+-- Value date of a batch, which can differ from when it was posted (e.g. camt.053 ValDt vs BookgDt)
+ALTER TABLE ledger_batches
+    ADD COLUMN IF NOT EXISTS effective_date DATE;
+
+UPDATE ledger_batches
+SET effective_date = (COALESCE(posted_at, created_at) AT TIME ZONE 'UTC')::date
+WHERE effective_date IS NULL;
+
+ALTER TABLE ledger_batches
+    ALTER COLUMN effective_date SET NOT NULL;
+
+-- Copied onto entries so value-dated balance lookups can use an index
+ALTER TABLE ledger_entries
+    ADD COLUMN IF NOT EXISTS effective_date DATE;
+
+UPDATE ledger_entries e
+SET effective_date = b.effective_date
+FROM ledger_batches b
+WHERE b.id = e.batch_id AND e.effective_date IS NULL;
+
+ALTER TABLE ledger_entries
+    ALTER COLUMN effective_date SET NOT NULL;
+
+-- balance_after is now the running balance in (effective_date, posted_at, sequence) order.
+-- Existing batches are valued on the day they were posted, so existing values already are.
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_effective
+    ON ledger_entries(account_id, effective_date, posted_at, sequence) WHERE posted_at IS NOT NULL;
+
+-- Positions are kept by posting date and by value date. Value dated positions go stale
+-- when a backdated batch is posted into their period, until they are recomputed.
+ALTER TABLE ledger_positions
+    ADD COLUMN IF NOT EXISTS date_basis VARCHAR(20) NOT NULL DEFAULT 'posting',  -- posting, value
+    ADD COLUMN IF NOT EXISTS is_stale BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE ledger_positions
+    DROP CONSTRAINT IF EXISTS ledger_positions_account_id_period_type_period_start_key;
+
+ALTER TABLE ledger_positions
+    ADD CONSTRAINT ledger_positions_account_period_basis_key UNIQUE (account_id, period_type, period_start, date_basis);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_positions_stale ON ledger_positions(account_id, period_start) WHERE is_stale;
@@ -0,0 +1,9 @@
+ALTER TABLE ledger_accounts
+    DROP CONSTRAINT IF EXISTS ledger_accounts_balance_range_check,
+    DROP CONSTRAINT IF EXISTS ledger_accounts_overdraft_limit_check;
+
+ALTER TABLE ledger_accounts
+    DROP COLUMN IF EXISTS overdraft_limit,
+    DROP COLUMN IF EXISTS max_balance,
+    DROP COLUMN IF EXISTS min_balance,
+    DROP COLUMN IF EXISTS no_negative_balance;
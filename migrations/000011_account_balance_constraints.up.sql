@@ -0,0 +1,10 @@
+-- Limits on an account's posted balance (signed by its normal side), checked when posting
+ALTER TABLE ledger_accounts
+    ADD COLUMN IF NOT EXISTS no_negative_balance BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS min_balance BIGINT,
+    ADD COLUMN IF NOT EXISTS max_balance BIGINT,
+    ADD COLUMN IF NOT EXISTS overdraft_limit BIGINT;  -- How far below zero the balance may go
+
+ALTER TABLE ledger_accounts
+    ADD CONSTRAINT ledger_accounts_overdraft_limit_check CHECK (overdraft_limit IS NULL OR overdraft_limit >= 0),
+    ADD CONSTRAINT ledger_accounts_balance_range_check CHECK (min_balance IS NULL OR max_balance IS NULL OR min_balance <= max_balance);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_ledger_batches_idempotency_key;
+
+ALTER TABLE ledger_batches
+    DROP COLUMN IF EXISTS request_fingerprint,
+    DROP COLUMN IF EXISTS idempotency_key;
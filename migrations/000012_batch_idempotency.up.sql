@@ -0,0 +1,8 @@
+-- Retries of POST /entries are matched on a tenant-scoped idempotency key: the caller's
+-- key, or source_type:source_id. The fingerprint is a SHA-256 of the original request.
+ALTER TABLE ledger_batches
+    ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS request_fingerprint VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_batches_idempotency_key
+    ON ledger_batches(tenant_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
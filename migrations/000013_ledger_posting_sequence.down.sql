@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_ledger_batches_posting_sequence;
+
+ALTER TABLE ledger_batches
+    DROP COLUMN IF EXISTS posting_sequence;
+
+DROP TABLE IF EXISTS ledger_sequences;
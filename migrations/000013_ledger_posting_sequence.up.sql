@@ -0,0 +1,31 @@
+-- Last posting sequence handed out per tenant. Posting a batch increments it in the
+-- posting transaction, so sequences have no gaps and commit in order.
+CREATE TABLE IF NOT EXISTS ledger_sequences (
+    tenant_id VARCHAR(26) PRIMARY KEY REFERENCES tenants(id),
+    last_sequence BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Position of a posted batch in its tenant's change feed
+ALTER TABLE ledger_batches
+    ADD COLUMN IF NOT EXISTS posting_sequence BIGINT;
+
+-- Batches already posted are numbered in the order they were posted
+UPDATE ledger_batches b
+SET posting_sequence = s.seq
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY tenant_id ORDER BY posted_at, created_at, id) AS seq
+    FROM ledger_batches
+    WHERE posted_at IS NOT NULL
+) s
+WHERE b.id = s.id AND b.posting_sequence IS NULL;
+
+INSERT INTO ledger_sequences (tenant_id, last_sequence)
+SELECT tenant_id, MAX(posting_sequence)
+FROM ledger_batches
+WHERE posting_sequence IS NOT NULL
+GROUP BY tenant_id
+ON CONFLICT (tenant_id) DO NOTHING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_batches_posting_sequence
+    ON ledger_batches(tenant_id, posting_sequence) WHERE posting_sequence IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS ledger_chain_checkpoints;
+
+ALTER TABLE ledger_sequences
+    DROP COLUMN IF EXISTS chain_start,
+    DROP COLUMN IF EXISTS last_hash;
+
+ALTER TABLE ledger_batches
+    DROP COLUMN IF EXISTS chain_hash,
+    DROP COLUMN IF EXISTS content_hash;
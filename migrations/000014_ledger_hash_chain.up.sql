@@ -0,0 +1,29 @@
+-- Each posted batch is hashed and chained to the batch its tenant posted before it
+-- (by posting_sequence): chain_hash = sha256(previous chain_hash || content_hash)
+ALTER TABLE ledger_batches
+    ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS chain_hash VARCHAR(64);
+
+-- The chain head lives with the posting sequence it follows. Batches posted before
+-- this migration are not chained; each tenant's chain starts with its next posting.
+ALTER TABLE ledger_sequences
+    ADD COLUMN IF NOT EXISTS last_hash VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS chain_start BIGINT NOT NULL DEFAULT 1;
+
+UPDATE ledger_sequences SET chain_start = last_sequence + 1;
+
+-- Chain heads signed with the ledger's ed25519 checkpoint key
+CREATE TABLE IF NOT EXISTS ledger_chain_checkpoints (
+    id VARCHAR(26) PRIMARY KEY,
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+
+    sequence BIGINT NOT NULL,
+    chain_hash VARCHAR(64) NOT NULL,
+
+    public_key VARCHAR(64) NOT NULL,  -- Hex
+    signature TEXT NOT NULL,           -- Base64
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, sequence)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ledger_audit_findings;
+DROP TABLE IF EXISTS ledger_audit_runs;
@@ -0,0 +1,33 @@
+-- Runs of the ledger integrity audit (cmd/ledger audit, or the scheduled audit job)
+CREATE TABLE IF NOT EXISTS ledger_audit_runs (
+    id VARCHAR(26) PRIMARY KEY,
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+
+    status VARCHAR(20) NOT NULL,  -- clean, discrepancies
+    finding_count INT NOT NULL DEFAULT 0,
+
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_audit_runs_tenant ON ledger_audit_runs(tenant_id, started_at DESC);
+
+-- Discrepancies an audit run found
+CREATE TABLE IF NOT EXISTS ledger_audit_findings (
+    id VARCHAR(26) PRIMARY KEY,
+    run_id VARCHAR(26) NOT NULL REFERENCES ledger_audit_runs(id),
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+
+    check_name VARCHAR(50) NOT NULL,    -- batch_totals, entry_count, running_balance, account_balance, accounting_equation, entry_status
+    subject_type VARCHAR(20) NOT NULL,  -- batch, entry, account, currency
+    subject_id VARCHAR(26) NOT NULL,
+
+    expected TEXT NOT NULL,
+    actual TEXT NOT NULL,
+    message TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_audit_findings_run_id ON ledger_audit_findings(run_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_audit_findings_subject ON ledger_audit_findings(tenant_id, subject_type, subject_id);
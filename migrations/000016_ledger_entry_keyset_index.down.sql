@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_created ON ledger_entries(account_id, created_at);
+
+DROP INDEX IF EXISTS idx_ledger_entries_account_created_id;
@@ -0,0 +1,6 @@
+-- Account entry listings page by (created_at, id), so the index needs id as a tiebreaker.
+-- Account listings page by (code, id), which UNIQUE (tenant_id, code) already covers.
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_created_id
+    ON ledger_entries(account_id, created_at, id);
+
+DROP INDEX IF EXISTS idx_ledger_entries_account_created;
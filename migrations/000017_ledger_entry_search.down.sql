@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_ledger_batches_tenant_reference;
+DROP INDEX IF EXISTS idx_ledger_entries_created_id;
@@ -0,0 +1,6 @@
+-- Tenant-wide entry searches page by (created_at, id) and look batches up by reference.
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_created_id
+    ON ledger_entries(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_batches_tenant_reference
+    ON ledger_batches(tenant_id, reference) WHERE reference IS NOT NULL;
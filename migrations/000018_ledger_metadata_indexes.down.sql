@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ledger_batches_tenant_created_id;
+DROP INDEX IF EXISTS idx_ledger_batches_metadata;
+DROP INDEX IF EXISTS idx_ledger_accounts_metadata;
@@ -0,0 +1,10 @@
+-- metadata.<key>=<value> filters are JSONB containment (@>) queries.
+CREATE INDEX IF NOT EXISTS idx_ledger_accounts_metadata
+    ON ledger_accounts USING GIN (metadata jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_batches_metadata
+    ON ledger_batches USING GIN (metadata jsonb_path_ops);
+
+-- Batch listings page by (created_at, id) within a tenant.
+CREATE INDEX IF NOT EXISTS idx_ledger_batches_tenant_created_id
+    ON ledger_batches(tenant_id, created_at, id);
@@ -0,0 +1,25 @@
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'ledger_accounts', 'ledger_batches', 'ledger_entries', 'ledger_positions',
+        'ledger_account_balances', 'ledger_periods', 'ledger_period_audit', 'ledger_sequences',
+        'ledger_chain_checkpoints', 'ledger_audit_runs', 'ledger_audit_findings',
+        'funding_intents', 'openbanking_payments', 'card_payments',
+        'ledger_batch_totals', 'ledger_fx_legs', 'funding_attempts', 'card_chargebacks',
+        'fps_payments', 'sepa_payments'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    END LOOP;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_created_id
+    ON ledger_entries(created_at, id);
+
+DROP INDEX IF EXISTS idx_ledger_entries_tenant_created_id;
+
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,76 @@
+-- Tenant isolation by row-level security, keyed on the session variables that
+-- database.DB sets on every connection it hands out: app.tenant_id, the tenant
+-- the work is done for, and app.system_scope, on for work spanning tenants
+-- (background jobs, provider callbacks). A session with neither sees no rows.
+-- FORCE applies the policies to the tables' owner too; only superusers and
+-- BYPASSRLS roles skip them, so later data migrations must SET app.system_scope = 'on'.
+
+-- Entries carry their tenant so that the policy needs no join
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(26) REFERENCES tenants(id);
+
+UPDATE ledger_entries e
+SET tenant_id = b.tenant_id
+FROM ledger_batches b
+WHERE b.id = e.batch_id AND e.tenant_id IS NULL;
+
+ALTER TABLE ledger_entries ALTER COLUMN tenant_id SET NOT NULL;
+
+-- Tenant-wide entry searches page by (created_at, id) within the tenant
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_tenant_created_id
+    ON ledger_entries(tenant_id, created_at, id);
+
+DROP INDEX IF EXISTS idx_ledger_entries_created_id;
+
+-- Tables with a tenant_id column
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'ledger_accounts', 'ledger_batches', 'ledger_entries', 'ledger_positions',
+        'ledger_account_balances', 'ledger_periods', 'ledger_period_audit', 'ledger_sequences',
+        'ledger_chain_checkpoints', 'ledger_audit_runs', 'ledger_audit_findings',
+        'funding_intents', 'openbanking_payments', 'card_payments'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format($policy$
+            CREATE POLICY tenant_isolation ON %I
+            USING (tenant_id = current_setting('app.tenant_id', true)
+                   OR current_setting('app.system_scope', true) = 'on')
+        $policy$, t);
+    END LOOP;
+END
+$$;
+
+-- Tables without one are visible with the row they belong to, whose own policy
+-- applies inside the subquery
+ALTER TABLE ledger_batch_totals ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_batch_totals FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON ledger_batch_totals
+    USING (EXISTS (SELECT 1 FROM ledger_batches b WHERE b.id = ledger_batch_totals.batch_id));
+
+ALTER TABLE ledger_fx_legs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_fx_legs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON ledger_fx_legs
+    USING (EXISTS (SELECT 1 FROM ledger_batches b WHERE b.id = ledger_fx_legs.batch_id));
+
+ALTER TABLE funding_attempts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE funding_attempts FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON funding_attempts
+    USING (EXISTS (SELECT 1 FROM funding_intents i WHERE i.id = funding_attempts.intent_id));
+
+ALTER TABLE card_chargebacks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE card_chargebacks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON card_chargebacks
+    USING (EXISTS (SELECT 1 FROM card_payments p WHERE p.id = card_chargebacks.card_payment_id));
+
+ALTER TABLE fps_payments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE fps_payments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON fps_payments
+    USING (EXISTS (SELECT 1 FROM funding_attempts a WHERE a.id = fps_payments.payment_attempt_id));
+
+ALTER TABLE sepa_payments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sepa_payments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON sepa_payments
+    USING (EXISTS (SELECT 1 FROM funding_attempts a WHERE a.id = sepa_payments.payment_attempt_id));
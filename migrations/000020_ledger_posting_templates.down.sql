@@ -0,0 +1,4 @@
+DROP POLICY IF EXISTS tenant_isolation ON ledger_posting_templates;
+
+DROP TRIGGER IF EXISTS update_ledger_posting_templates_updated_at ON ledger_posting_templates;
+DROP TABLE IF EXISTS ledger_posting_templates;
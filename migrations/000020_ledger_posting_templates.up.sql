@@ -0,0 +1,27 @@
+-- Posting templates: a tenant's named journal rules, expanded into batches by
+-- POST /entries/from-template
+CREATE TABLE IF NOT EXISTS ledger_posting_templates (
+    id VARCHAR(26) PRIMARY KEY,
+    tenant_id VARCHAR(26) NOT NULL REFERENCES tenants(id),
+
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    source_type VARCHAR(50) NOT NULL,
+
+    roles JSONB NOT NULL DEFAULT '{}',  -- Role -> account code; '' when bound per posting
+    lines JSONB NOT NULL,               -- [{role, entry_type, amount, description}]
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, name)
+);
+
+CREATE TRIGGER update_ledger_posting_templates_updated_at BEFORE UPDATE ON ledger_posting_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE ledger_posting_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_posting_templates FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON ledger_posting_templates
+    USING (tenant_id = current_setting('app.tenant_id', true)
+           OR current_setting('app.system_scope', true) = 'on');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ledger_accounts_tenant_path_prefix;
+DROP INDEX IF EXISTS idx_wallet_accounts_unlinked;
+DROP INDEX IF EXISTS idx_wallet_accounts_ledger_account_id;
@@ -0,0 +1,12 @@
+-- Each wallet account has a ledger account of its own under 2000 Customer Wallet
+-- Liabilities (or its copy in the wallet's currency)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_accounts_ledger_account_id
+    ON wallet_accounts(ledger_account_id) WHERE ledger_account_id IS NOT NULL;
+
+-- Backfills look for the wallet accounts not linked yet
+CREATE INDEX IF NOT EXISTS idx_wallet_accounts_unlinked
+    ON wallet_accounts(wallet_id) WHERE ledger_account_id IS NULL;
+
+-- Rolled up balances sum an account's subtree by path prefix
+CREATE INDEX IF NOT EXISTS idx_ledger_accounts_tenant_path_prefix
+    ON ledger_accounts(tenant_id, path text_pattern_ops);